@@ -3,10 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,6 +19,8 @@ import (
 	"github.com/sl4wa/outages-bot/internal/outage/persistence"
 	"github.com/sl4wa/outages-bot/internal/outage/subscription"
 	"github.com/sl4wa/outages-bot/internal/outage/telegram"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+	"github.com/sl4wa/outages-bot/internal/shared/httpcache"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/joho/godotenv"
@@ -36,6 +40,10 @@ func main() {
 	rootCmd.AddCommand(notifierCmd())
 	rootCmd.AddCommand(outagesCmd())
 	rootCmd.AddCommand(usersCmd())
+	rootCmd.AddCommand(statsCmd())
+	rootCmd.AddCommand(replayCmd())
+	rootCmd.AddCommand(archiveCmd())
+	rootCmd.AddCommand(validateCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -71,6 +79,69 @@ func dataDir() string {
 	return getEnv("DATA_DIR", "data")
 }
 
+// loadCities reads the optional city catalog. Without it the app serves the single
+// city pinned in OUTAGE_API_URL, with streets from streets.csv.
+func loadCities(dir string) []persistence.CityConfig {
+	cities, err := persistence.LoadCities(filepath.Join(dir, persistence.CitiesFileName))
+	if err != nil {
+		log.Fatalf("Failed to load cities: %v", err)
+	}
+	return cities
+}
+
+// archiveConfig enables archiving raw API responses under dir.
+type archiveConfig struct {
+	dir       string
+	retention httpcache.Retention
+}
+
+// newArchive returns the archive of the given source, or nil when archiving is off.
+func (c *archiveConfig) newArchive(source string) *httpcache.Archive {
+	if c == nil {
+		return nil
+	}
+	return httpcache.NewArchive(c.dir, source, c.retention)
+}
+
+// newOutageProvider builds a provider for every configured city. When cacheDir is
+// non-empty each endpoint keeps its own HTTP cache file there; with archive set
+// every changed response is archived too. Failed requests are retried behind a
+// circuit breaker of each city's own, so that one failing city does not stop the others.
+func newOutageProvider(cities []persistence.CityConfig, cacheDir string, archive *archiveConfig, logger *log.Logger) (*loe.Provider, error) {
+	baseURL := requireEnv("OUTAGE_API_URL")
+	if len(cities) == 0 {
+		provider := loe.NewProvider(baseURL, nil, logger)
+		if cacheDir != "" {
+			provider.WithCacheFile(filepath.Join(cacheDir, loe.DefaultCacheFileName))
+		}
+		provider.WithArchive(archive.newArchive("outages")).WithPolicy(httpcache.DefaultPolicy())
+		return provider, nil
+	}
+
+	endpoints := make([]loe.Endpoint, 0, len(cities))
+	for _, city := range cities {
+		url, err := loe.CityURL(baseURL, city.OTGID, city.ID)
+		if err != nil {
+			return nil, err
+		}
+		ep := loe.Endpoint{URL: url, Archive: archive.newArchive(fmt.Sprintf("outages-%d", city.ID)), Breaker: httpcache.DefaultBreaker()}
+		if cacheDir != "" {
+			ep.CacheFile = filepath.Join(cacheDir, loe.CityCacheFileName(city.ID))
+		}
+		endpoints = append(endpoints, ep)
+	}
+	return loe.NewMultiProvider(endpoints, nil, logger).WithPolicy(httpcache.DefaultPolicy()), nil
+}
+
+// loadZone returns the time zone outage times are shown and evaluated in.
+func loadZone() (*time.Location, error) {
+	zone, err := time.LoadLocation("Europe/Kyiv")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone: %w", err)
+	}
+	return zone, nil
+}
+
 func botCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "bot",
@@ -84,14 +155,27 @@ func botCmd() *cobra.Command {
 				log.Fatalf("Failed to create user repository: %v", err)
 			}
 
-			streetRepo, err := persistence.NewFileStreetRepository(filepath.Join(dir, "streets.csv"))
+			cities := loadCities(dir)
+			var streetRepo *persistence.FileStreetRepository
+			if len(cities) == 0 {
+				streetRepo, err = persistence.NewFileStreetRepository(filepath.Join(dir, "streets.csv"))
+			} else {
+				streetRepo, err = persistence.NewCityStreetRepository(cities)
+			}
 			if err != nil {
 				log.Fatalf("Failed to create street repository: %v", err)
 			}
 
+			cityList := make([]users.City, len(cities))
+			for i, city := range cities {
+				cityList[i] = city.City()
+			}
+
 			subscriptionWorkflow := subscription.NewWorkflow(subscription.WorkflowConfig{
 				UserRepo:   userRepo,
 				StreetRepo: streetRepo,
+				Outages:    persistence.NewFileOutageRepository(filepath.Join(dir, persistence.OutageSnapshotFileName)),
+				Cities:     cityList,
 			})
 			runner := telegram.NewBotRunner(telegram.BotRunnerConfig{
 				Bot:      api,
@@ -105,12 +189,22 @@ func botCmd() *cobra.Command {
 }
 
 func notifierCmd() *cobra.Command {
-	var interval time.Duration
+	var interval, remindBefore time.Duration
+	var maxSendAttempts, archiveMaxCount int
+	var dryRun, archiveResponses bool
+	var archiveMaxAge, staleIfError time.Duration
 
 	cmd := &cobra.Command{
 		Use:   "notifier",
 		Short: "Fetch outages and send notifications",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if dryRun {
+				if interval > 0 {
+					return fmt.Errorf("--dry-run cannot be combined with --interval")
+				}
+				return runNotifierDryRun(cmd.OutOrStdout(), remindBefore)
+			}
+
 			api := mustBotAPI()
 			dir := dataDir()
 
@@ -119,12 +213,47 @@ func notifierCmd() *cobra.Command {
 				return fmt.Errorf("failed to create user repository: %w", err)
 			}
 
-			outageProvider := loe.NewProvider(requireEnv("OUTAGE_API_URL"), nil, log.Default()).
-				WithCacheFile(filepath.Join(dir, loe.DefaultCacheFileName))
+			var archive *archiveConfig
+			if archiveResponses {
+				archive = &archiveConfig{
+					dir:       filepath.Join(dir, httpcache.ArchiveDirName),
+					retention: httpcache.Retention{MaxAge: archiveMaxAge, MaxCount: archiveMaxCount},
+				}
+			}
+			cities := loadCities(dir)
+			if len(cities) > 0 {
+				// Deployments predating cities.csv cached the default city's feed under the old name.
+				migrated, err := loe.MigrateLegacyCacheFile(dir, cities[0].ID)
+				if err != nil {
+					return err
+				}
+				if migrated {
+					log.Printf("Renamed %s to %s.", loe.DefaultCacheFileName, loe.CityCacheFileName(cities[0].ID))
+				}
+			}
+			outageProvider, err := newOutageProvider(cities, dir, archive, log.Default())
+			if err != nil {
+				return err
+			}
+			outageProvider.WithStaleIfError(staleIfError)
 			sender := telegram.NewNotificationSender(api)
-			fetchService := outage.NewFetchOutages(outageProvider)
+			fetchService := outage.NewFetchOutages(outageProvider).WithLogger(log.Default())
 			snapshotRepo := persistence.NewFileOutageRepository(filepath.Join(dir, persistence.OutageSnapshotFileName))
-			notifyUsers := notifier.NewNotifyUsers(fetchService, sender, userRepo, snapshotRepo, log.Default())
+			outbox, err := persistence.NewFileOutbox(filepath.Join(dir, persistence.OutboxDirName))
+			if err != nil {
+				return err
+			}
+			zone, err := loadZone()
+			if err != nil {
+				return err
+			}
+			notifyUsers := notifier.NewNotifyUsers(fetchService, sender, userRepo, snapshotRepo, log.Default()).
+				WithClock(time.Now, zone).
+				WithReminder(remindBefore).
+				WithDelivery(telegram.DeliveryLimits).
+				WithOutbox(outbox).
+				WithMaxSendAttempts(maxSendAttempts).
+				WithHistory(persistence.NewFileOutageHistory(filepath.Join(dir, persistence.OutageHistoryFileName)))
 			runFn := notifyUsers.Handle
 
 			if interval <= 0 {
@@ -139,10 +268,48 @@ func notifierCmd() *cobra.Command {
 	}
 
 	cmd.Flags().DurationVar(&interval, "interval", 0, "Run repeatedly with this interval between runs (e.g. 60s). If zero, run once and exit.")
+	cmd.Flags().DurationVar(&remindBefore, "remind-before", 0, "Remind users this long before a notified outage starts (e.g. 30m). If zero, no reminders are sent.")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the notifications each chat would get without sending them or saving any state.")
+	cmd.Flags().BoolVar(&archiveResponses, "archive", false, "Keep every changed API response under DATA_DIR/archive/")
+	cmd.Flags().DurationVar(&archiveMaxAge, "archive-max-age", 30*24*time.Hour, "Remove archived responses older than this. If zero, keep them regardless of age.")
+	cmd.Flags().IntVar(&archiveMaxCount, "archive-max-count", 1000, "Keep at most this many archived responses per endpoint. If zero, keep them all.")
+	cmd.Flags().IntVar(&maxSendAttempts, "max-send-attempts", notifier.DefaultMaxSendAttempts, "Give up on a notification after this many failed sends.")
+	cmd.Flags().DurationVar(&staleIfError, "stale-if-error", 0, "When the API is unreachable, use cached responses up to this old instead of failing the run. If zero, fail the run.")
 
 	return cmd
 }
 
+// runNotifierDryRun fetches, diffs and matches outages like a notifier run, printing the
+// notifications to w instead of sending them. Nothing under DATA_DIR is written.
+func runNotifierDryRun(w io.Writer, remindBefore time.Duration) error {
+	dir := dataDir()
+	userRepo, err := persistence.NewFileUserRepository(filepath.Join(dir, "users"))
+	if err != nil {
+		return fmt.Errorf("failed to create user repository: %w", err)
+	}
+	// No cache directory, so that the HTTP cache files are left alone too.
+	outageProvider, err := newOutageProvider(loadCities(dir), "", nil, log.Default())
+	if err != nil {
+		return err
+	}
+	zone, err := loadZone()
+	if err != nil {
+		return err
+	}
+
+	snapshotRepo := persistence.NewFileOutageRepository(filepath.Join(dir, persistence.OutageSnapshotFileName))
+	notifyUsers := notifier.NewNotifyUsers(
+		outage.NewFetchOutages(outageProvider).WithLogger(log.Default()),
+		telegram.NewPreviewSender(w),
+		cli.DryRunUsers{UserRepository: userRepo},
+		cli.DryRunSnapshot{SnapshotReader: snapshotRepo},
+		log.Default(),
+	).
+		WithClock(time.Now, zone).
+		WithReminder(remindBefore)
+	return notifyUsers.Handle(context.Background())
+}
+
 func runNotifierLoop(ctx context.Context, runFn func(context.Context) error, interval time.Duration, logger *log.Logger) error {
 	if interval <= 0 {
 		return fmt.Errorf("interval must be positive, got %v", interval)
@@ -164,14 +331,56 @@ func runNotifierLoop(ctx context.Context, runFn func(context.Context) error, int
 }
 
 func outagesCmd() *cobra.Command {
-	return &cobra.Command{
+	var staleIfError time.Duration
+
+	cmd := &cobra.Command{
 		Use:   "outages",
 		Short: "Print a table of current outages",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			outageProvider := loe.NewProvider(requireEnv("OUTAGE_API_URL"), nil, nil)
-			return cli.RunOutagesCommand(context.Background(), outageProvider, os.Stdout)
+			dir := dataDir()
+			outageProvider, err := newOutageProvider(loadCities(dir), dir, nil, nil)
+			if err != nil {
+				return err
+			}
+			// The cache files belong to the notifier; only read them.
+			outageProvider.WithReadOnlyCache().WithStaleIfError(staleIfError)
+			return cli.RunOutagesCommand(context.Background(), outageProvider, time.Now(), os.Stdout)
+		},
+	}
+
+	cmd.Flags().DurationVar(&staleIfError, "stale-if-error", 24*time.Hour, "When the API is unreachable, show the notifier's cached responses up to this old. If zero, fail instead.")
+
+	cmd.AddCommand(outagesHistoryCmd())
+
+	return cmd
+}
+
+func outagesHistoryCmd() *cobra.Command {
+	var street, building, from, to string
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Print archived outages recorded by the notifier",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zone, err := loadZone()
+			if err != nil {
+				return err
+			}
+			filter, err := cli.NewHistoryFilter(street, building, from, to, zone)
+			if err != nil {
+				return err
+			}
+			history := persistence.NewFileOutageHistory(filepath.Join(dataDir(), persistence.OutageHistoryFileName))
+			return cli.RunHistoryCommand(history, filter, zone, os.Stdout)
 		},
 	}
+
+	cmd.Flags().StringVar(&street, "street", "", "Only outages on streets whose name contains this text")
+	cmd.Flags().StringVar(&building, "building", "", "Only outages affecting this building")
+	cmd.Flags().StringVar(&from, "from", "", "Only outages lasting past the start of this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&to, "to", "", "Only outages starting on or before this date (YYYY-MM-DD)")
+
+	return cmd
 }
 
 func usersCmd() *cobra.Command {
@@ -193,3 +402,130 @@ func usersCmd() *cobra.Command {
 		},
 	}
 }
+
+func statsCmd() *cobra.Command {
+	var street, building, from, to, by, format string
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Print outage statistics per street or building from the outage history",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zone, err := loadZone()
+			if err != nil {
+				return err
+			}
+			filter, err := cli.NewHistoryFilter(street, building, from, to, zone)
+			if err != nil {
+				return err
+			}
+			grouping, ok := outage.ParseStatsGrouping(by)
+			if !ok {
+				return fmt.Errorf("invalid --by value %q, expected street or building", by)
+			}
+			history := persistence.NewFileOutageHistory(filepath.Join(dataDir(), persistence.OutageHistoryFileName))
+			return cli.RunStatsCommand(history, filter, grouping, format, time.Now(), os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVar(&by, "by", "street", "Group by street or building")
+	cmd.Flags().StringVar(&format, "format", cli.FormatTable, "Output format: table, csv or json")
+	cmd.Flags().StringVar(&street, "street", "", "Only outages on streets whose name contains this text")
+	cmd.Flags().StringVar(&building, "building", "", "Only outages affecting this building")
+	cmd.Flags().StringVar(&from, "from", "", "Start of the report period (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&to, "to", "", "End of the report period, inclusive (YYYY-MM-DD)")
+
+	return cmd
+}
+
+func validateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [FILE|URL]",
+		Short: "Report the outage feed rows that are dropped or corrected while parsing",
+		Long: "Fetch the outage feed from the configured API, or from URL, or read a saved response from FILE " +
+			"(JSON body, HTTP cache or archive file), and print every row dropped or corrected on the way to the notifier.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var provider outage.RawProvider
+			var err error
+			switch {
+			case len(args) == 0:
+				provider, err = newOutageProvider(loadCities(dataDir()), "", nil, nil)
+			case strings.HasPrefix(args[0], "http://") || strings.HasPrefix(args[0], "https://"):
+				provider = loe.NewProvider(args[0], nil, nil).WithPolicy(httpcache.DefaultPolicy())
+			default:
+				provider, err = cli.NewFileFeed(args[0], time.Now())
+			}
+			if err != nil {
+				return err
+			}
+			return cli.RunValidateCommand(context.Background(), provider, os.Stdout)
+		},
+	}
+}
+
+func replayCmd() *cobra.Command {
+	var start string
+	var step, remindBefore time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "replay FILE...",
+		Short: "Replay recorded outage API responses through the notifier and print its decisions",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zone, err := loadZone()
+			if err != nil {
+				return err
+			}
+			steps, err := cli.NewReplaySteps(args, start, step, zone)
+			if err != nil {
+				return err
+			}
+			userRepo, err := persistence.NewFileUserRepository(filepath.Join(dataDir(), "users"))
+			if err != nil {
+				return fmt.Errorf("failed to create user repository: %w", err)
+			}
+			return cli.RunReplayCommand(context.Background(), steps, userRepo.FindAll(), zone, remindBefore, os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVar(&start, "start", "", "Simulated time of the first response (YYYY-MM-DD HH:MM, Kyiv time). If empty, each response is replayed at its file's modification time.")
+	cmd.Flags().DurationVar(&step, "step", time.Minute, "Simulated time between responses when --start is set")
+	cmd.Flags().DurationVar(&remindBefore, "remind-before", 0, "Remind users this long before a notified outage starts, as the notifier flag of the same name")
+
+	return cmd
+}
+
+func archiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Inspect API responses archived by notifier --archive",
+	}
+
+	var source string
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List archived API responses",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			zone, err := loadZone()
+			if err != nil {
+				return err
+			}
+			return cli.RunArchiveListCommand(filepath.Join(dataDir(), httpcache.ArchiveDirName), source, zone, os.Stdout)
+		},
+	}
+	list.Flags().StringVar(&source, "source", "", "Only responses of this source (e.g. outages, outages-693, schedule)")
+
+	var output string
+	extract := &cobra.Command{
+		Use:   "extract FILE",
+		Short: "Print the body of an archived API response, e.g. to replay it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cli.RunArchiveExtractCommand(filepath.Join(dataDir(), httpcache.ArchiveDirName), args[0], output, os.Stdout)
+		},
+	}
+	extract.Flags().StringVarP(&output, "output", "o", "", "Write the body to this file instead of stdout")
+
+	cmd.AddCommand(list, extract)
+	return cmd
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/sl4wa/outages-bot/internal/schedule/persistence"
 	"github.com/sl4wa/outages-bot/internal/schedule/schedule"
 	"github.com/sl4wa/outages-bot/internal/schedule/telegram"
+	"github.com/sl4wa/outages-bot/internal/shared/httpcache"
 	"github.com/sl4wa/outages-bot/internal/shared/subscribers"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -25,6 +26,7 @@ import (
 type appConfig struct {
 	StatePath        string
 	HTTPCachePath    string
+	ArchiveDir       string
 	APIURL           string
 	TelegramBotToken string
 	TelegramUsersDir string
@@ -32,8 +34,14 @@ type appConfig struct {
 }
 
 func main() {
-	var interval time.Duration
+	var interval, staleIfError time.Duration
+	var archive bool
+	var retention httpcache.Retention
 	flag.DurationVar(&interval, "interval", 0, "Run repeatedly with this interval between runs (e.g. 60s). If zero, run once and exit.")
+	flag.BoolVar(&archive, "archive", false, "Keep every changed API response under DATA_DIR/archive/")
+	flag.DurationVar(&retention.MaxAge, "archive-max-age", 30*24*time.Hour, "Remove archived responses older than this. If zero, keep them regardless of age.")
+	flag.IntVar(&retention.MaxCount, "archive-max-count", 1000, "Keep at most this many archived responses. If zero, keep them all.")
+	flag.DurationVar(&staleIfError, "stale-if-error", 0, "When the API is unreachable, use the cached response up to this old instead of failing the run. If zero, fail the run.")
 	flag.Parse()
 
 	log.SetOutput(os.Stdout)
@@ -52,6 +60,10 @@ func main() {
 	}
 
 	cache := loe.NewHTTPCache(config.HTTPCachePath)
+	cache.MaxStale = staleIfError
+	if archive {
+		cache.Archive = httpcache.NewArchive(config.ArchiveDir, "schedule", retention)
+	}
 	provider := loe.Provider{
 		LoadPayload: func(ctx context.Context) (string, error) {
 			return cache.Fetch(ctx, config.APIURL)
@@ -157,6 +169,7 @@ func configFromEnv() (appConfig, error) {
 	return appConfig{
 		StatePath:        filepath.Join(dataDir, persistence.StateFileName),
 		HTTPCachePath:    filepath.Join(dataDir, loe.DefaultCacheFileName),
+		ArchiveDir:       filepath.Join(dataDir, httpcache.ArchiveDirName),
 		APIURL:           apiURL,
 		TelegramBotToken: token,
 		TelegramUsersDir: filepath.Join(dataDir, "users"),
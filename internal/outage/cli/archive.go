@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/sl4wa/outages-bot/internal/shared/httpcache"
+	"io"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+// RunArchiveListCommand prints the responses archived in dir, oldest first, optionally only
+// those of one source. Receive times are shown in zone. Only the listed responses are read,
+// for their ETag and body size.
+func RunArchiveListCommand(dir, source string, zone *time.Location, w io.Writer) error {
+	archived, err := httpcache.ListArchive(dir)
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewTable(w,
+		tablewriter.WithConfig(tablewriter.NewConfigBuilder().WithHeaderAutoFormat(tw.Off).Build()),
+		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{})),
+	)
+	table.Header([]string{"File", "Source", "Received", "ETag", "Bytes"})
+
+	found := false
+	for _, r := range archived {
+		if source != "" && r.Source != source {
+			continue
+		}
+		found = true
+		etag, size := "-", "-"
+		if entry, err := httpcache.LoadArchived(dir, r.File); err == nil {
+			etag, size = orDash(entry.ETag), fmt.Sprintf("%d", len(entry.Body))
+		}
+		table.Append([]string{r.File, r.Source, r.ReceivedAt.In(zone).Format(dateTimeFormat + ":05"), etag, size})
+	}
+	if !found {
+		fmt.Fprintln(w, "No archived responses found.")
+		return nil
+	}
+	return table.Render()
+}
+
+// RunArchiveExtractCommand writes the body of the archived response file in dir to w or,
+// when output is set, to that file, dated when the response was received so that replay
+// simulates it at that time.
+func RunArchiveExtractCommand(dir, file, output string, w io.Writer) error {
+	entry, err := httpcache.LoadArchived(dir, file)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		if _, err := w.Write(entry.Body); err != nil {
+			return fmt.Errorf("failed to write archived response: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.WriteFile(output, entry.Body, 0o644); err != nil {
+		return fmt.Errorf("failed to write archived response: %w", err)
+	}
+	archived, err := httpcache.ListArchive(dir)
+	if err != nil {
+		return err
+	}
+	for _, r := range archived {
+		if r.File == file {
+			return os.Chtimes(output, r.ReceivedAt, r.ReceivedAt)
+		}
+	}
+	return nil
+}
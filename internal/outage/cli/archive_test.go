@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/sl4wa/outages-bot/internal/shared/httpcache"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const archivedFile = "outages-20241128T070000.000Z.http-cache"
+
+func setupArchive(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, httpcache.Save(filepath.Join(dir, archivedFile), `"v1"`, []byte(`{"hydra:member":[]}`)))
+	require.NoError(t, httpcache.Save(filepath.Join(dir, "schedule-20241128T080000.000Z.http-cache"), "", []byte("schedule")))
+	return dir
+}
+
+func TestRunArchiveListCommand(t *testing.T) {
+	dir := setupArchive(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, RunArchiveListCommand(dir, "", time.UTC, &buf))
+	out := buf.String()
+	assert.Contains(t, out, archivedFile)
+	assert.Contains(t, out, "28.11.2024 07:00:00")
+	assert.Contains(t, out, `"v1"`)
+	assert.Contains(t, out, "schedule-20241128T080000.000Z.http-cache")
+
+	buf.Reset()
+	require.NoError(t, RunArchiveListCommand(dir, "schedule", time.UTC, &buf))
+	assert.NotContains(t, buf.String(), archivedFile)
+}
+
+func TestRunArchiveListCommand_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RunArchiveListCommand(t.TempDir(), "", time.UTC, &buf))
+	assert.Equal(t, "No archived responses found.\n", buf.String())
+}
+
+func TestRunArchiveExtractCommand_ToWriter(t *testing.T) {
+	dir := setupArchive(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, RunArchiveExtractCommand(dir, archivedFile, "", &buf))
+	assert.Equal(t, `{"hydra:member":[]}`, buf.String())
+}
+
+func TestRunArchiveExtractCommand_ToFileDatedForReplay(t *testing.T) {
+	dir := setupArchive(t)
+	output := filepath.Join(t.TempDir(), "payload.json")
+
+	require.NoError(t, RunArchiveExtractCommand(dir, archivedFile, output, &bytes.Buffer{}))
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, `{"hydra:member":[]}`, string(data))
+	steps, err := NewReplaySteps([]string{output}, "", time.Minute, time.UTC)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2024, 11, 28, 7, 0, 0, 0, time.UTC).Equal(steps[0].At))
+}
+
+func TestRunArchiveExtractCommand_Missing(t *testing.T) {
+	err := RunArchiveExtractCommand(t.TempDir(), archivedFile, "", &bytes.Buffer{})
+	assert.ErrorContains(t, err, "not found")
+}
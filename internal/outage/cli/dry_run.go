@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"github.com/sl4wa/outages-bot/internal/outage/notifier"
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+)
+
+// DryRunUsers reads users from the wrapped repository and discards every change to them.
+type DryRunUsers struct {
+	notifier.UserRepository
+}
+
+// Save discards the change.
+func (DryRunUsers) Save(*users.User) error {
+	return nil
+}
+
+// Remove keeps the user.
+func (DryRunUsers) Remove(int64) (bool, error) {
+	return false, nil
+}
+
+// DryRunSnapshot reads the saved outage snapshot and never replaces it.
+type DryRunSnapshot struct {
+	outage.SnapshotReader
+}
+
+// Save discards the outages.
+func (DryRunSnapshot) Save([]*outage.Outage) error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingUserRepo struct {
+	users   []*users.User
+	saved   int
+	removed int
+}
+
+func (r *recordingUserRepo) FindAll() []*users.User { return r.users }
+
+func (r *recordingUserRepo) Save(*users.User) error {
+	r.saved++
+	return nil
+}
+
+func (r *recordingUserRepo) Remove(int64) (bool, error) {
+	r.removed++
+	return true, nil
+}
+
+type recordingSnapshot struct {
+	outages []*outage.Outage
+	saved   int
+}
+
+func (s *recordingSnapshot) Load() ([]*outage.Outage, error) { return s.outages, nil }
+
+func (s *recordingSnapshot) Save([]*outage.Outage) error {
+	s.saved++
+	return nil
+}
+
+func TestDryRunUsers_ReadsButDiscardsChanges(t *testing.T) {
+	addr, err := users.NewAddress(1, "Стрийська", "10")
+	require.NoError(t, err)
+	user := users.NewUser(100, addr)
+	repo := &recordingUserRepo{users: []*users.User{user}}
+	dry := DryRunUsers{UserRepository: repo}
+
+	assert.Equal(t, []*users.User{user}, dry.FindAll())
+	require.NoError(t, dry.Save(user))
+	removed, err := dry.Remove(100)
+	require.NoError(t, err)
+	assert.False(t, removed)
+	assert.Zero(t, repo.saved)
+	assert.Zero(t, repo.removed)
+}
+
+func TestDryRunSnapshot_ReadsButNeverSaves(t *testing.T) {
+	current := []*outage.Outage{{ID: 1}}
+	snapshot := &recordingSnapshot{outages: current}
+	dry := DryRunSnapshot{SnapshotReader: snapshot}
+
+	loaded, err := dry.Load()
+	require.NoError(t, err)
+	assert.Equal(t, current, loaded)
+	require.NoError(t, dry.Save(nil))
+	assert.Zero(t, snapshot.saved)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+const historyDateFormat = "2006-01-02"
+
+// NewHistoryFilter builds a history filter from command-line values.
+// Dates use the YYYY-MM-DD format in the given zone; the to date is inclusive.
+func NewHistoryFilter(street, building, from, to string, zone *time.Location) (outage.HistoryFilter, error) {
+	filter := outage.HistoryFilter{
+		Street:   strings.TrimSpace(street),
+		Building: strings.TrimSpace(building),
+	}
+	if from != "" {
+		t, err := time.ParseInLocation(historyDateFormat, from, zone)
+		if err != nil {
+			return outage.HistoryFilter{}, fmt.Errorf("invalid --from date %q, expected YYYY-MM-DD", from)
+		}
+		filter.From = t
+	}
+	if to != "" {
+		t, err := time.ParseInLocation(historyDateFormat, to, zone)
+		if err != nil {
+			return outage.HistoryFilter{}, fmt.Errorf("invalid --to date %q, expected YYYY-MM-DD", to)
+		}
+		filter.To = t.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return outage.HistoryFilter{}, fmt.Errorf("--from date must not be after --to date")
+	}
+	return filter, nil
+}
+
+// RunHistoryCommand prints archived outages matching the filter in a table, times shown in zone.
+func RunHistoryCommand(history outage.HistoryReader, filter outage.HistoryFilter, zone *time.Location, w io.Writer) error {
+	entries, err := history.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to read outage history: %w", err)
+	}
+
+	var matched []*outage.HistoryEntry
+	for _, e := range entries {
+		if filter.Matches(e) {
+			matched = append(matched, e)
+		}
+	}
+
+	if len(matched) == 0 {
+		fmt.Fprintln(w, "No outages found.")
+		return nil
+	}
+
+	cfg := tablewriter.NewConfigBuilder().
+		WithHeaderAutoFormat(tw.Off).
+		WithRowAutoWrap(tw.WrapNormal).
+		ForColumn(1).WithMaxWidth(30).Build().
+		ForColumn(2).WithMaxWidth(30).Build().
+		ForColumn(4).WithMaxWidth(40).Build().
+		Build()
+
+	table := tablewriter.NewTable(w,
+		tablewriter.WithConfig(cfg),
+		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{})),
+	)
+	table.Header([]string{"ID", "Street", "Buildings", "Periods", "Comment", "First seen", "Last seen", "Resolved"})
+
+	for _, e := range matched {
+		id := "-"
+		if e.Outage.ID != 0 {
+			id = fmt.Sprintf("%d", e.Outage.ID)
+		}
+		periods := make([]string, 0, len(e.Periods))
+		for _, p := range e.Periods {
+			periods = append(periods, PeriodFormatter(p.StartDate.In(zone), p.EndDate.In(zone)))
+		}
+		resolved := "-"
+		if e.Resolved() {
+			resolved = e.ResolvedAt.In(zone).Format(dateTimeFormat)
+		}
+		table.Append([]string{
+			id,
+			e.Outage.Address.StreetName,
+			strings.Join(e.Outage.Address.Buildings, ", "),
+			strings.Join(periods, "\n"),
+			e.Outage.Description.Value,
+			e.FirstSeen.In(zone).Format(dateTimeFormat),
+			e.LastSeen.In(zone).Format(dateTimeFormat),
+			resolved,
+		})
+	}
+
+	if err := table.Render(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nTotal Outages: %d\n", len(matched))
+	return nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHistoryReader struct {
+	entries []*outage.HistoryEntry
+	err     error
+}
+
+func (m *mockHistoryReader) Entries() ([]*outage.HistoryEntry, error) {
+	return m.entries, m.err
+}
+
+func makeHistoryEntry(t *testing.T, id int, streetName string, buildings []string, start time.Time) *outage.HistoryEntry {
+	t.Helper()
+	period, err := outage.NewPeriod(start, start.Add(8*time.Hour))
+	require.NoError(t, err)
+	addr, err := outage.NewAddress(1, streetName, buildings, "Львів")
+	require.NoError(t, err)
+	return &outage.HistoryEntry{
+		Outage:    &outage.Outage{ID: id, Period: period, Address: addr, Description: outage.NewDescription("Ремонт")},
+		FirstSeen: start.Add(-time.Hour),
+		LastSeen:  start.Add(8 * time.Hour),
+		Periods:   []outage.Period{period},
+	}
+}
+
+func TestRunHistoryCommand_PrintsMatchingEntries(t *testing.T) {
+	resolved := makeHistoryEntry(t, 7, "Стрийська", []string{"10", "12"}, time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC))
+	resolved.ResolvedAt = time.Date(2024, 3, 15, 16, 5, 0, 0, time.UTC)
+	other := makeHistoryEntry(t, 8, "Наукова", []string{"5"}, time.Date(2024, 3, 16, 8, 0, 0, 0, time.UTC))
+	reader := &mockHistoryReader{entries: []*outage.HistoryEntry{resolved, other}}
+
+	var buf bytes.Buffer
+	err := RunHistoryCommand(reader, outage.HistoryFilter{Street: "Стрийська"}, time.UTC, &buf)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "First seen")
+	assert.Contains(t, output, "Стрийська")
+	assert.Contains(t, output, "10, 12")
+	assert.Contains(t, output, "15.03.2024 08:00 - 16:00")
+	assert.Contains(t, output, "15.03.2024 16:05")
+	assert.NotContains(t, output, "Наукова")
+	assert.Contains(t, output, "Total Outages: 1")
+}
+
+func TestRunHistoryCommand_NoMatches(t *testing.T) {
+	reader := &mockHistoryReader{}
+
+	var buf bytes.Buffer
+	require.NoError(t, RunHistoryCommand(reader, outage.HistoryFilter{}, time.UTC, &buf))
+	assert.Equal(t, "No outages found.\n", buf.String())
+}
+
+func TestRunHistoryCommand_ReadError(t *testing.T) {
+	reader := &mockHistoryReader{err: errors.New("broken")}
+
+	err := RunHistoryCommand(reader, outage.HistoryFilter{}, time.UTC, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read outage history")
+}
+
+func TestNewHistoryFilter(t *testing.T) {
+	filter, err := NewHistoryFilter(" Стрийська ", "10", "2024-03-01", "2024-03-31", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, "Стрийська", filter.Street)
+	assert.Equal(t, "10", filter.Building)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), filter.From)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), filter.To, "to date is inclusive")
+
+	_, err = NewHistoryFilter("", "", "01.03.2024", "", time.UTC)
+	assert.Error(t, err)
+	_, err = NewHistoryFilter("", "", "2024-03-05", "2024-03-01", time.UTC)
+	assert.Error(t, err)
+}
@@ -6,19 +6,31 @@ import (
 	"github.com/sl4wa/outages-bot/internal/outage/outage"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/olekukonko/tablewriter/renderer"
 	"github.com/olekukonko/tablewriter/tw"
 )
 
-// RunOutagesCommand fetches and prints outages in a table.
-func RunOutagesCommand(ctx context.Context, provider outage.RawProvider, w io.Writer) error {
+// staleReporter is implemented by providers that may serve cached data when the API is down.
+type staleReporter interface {
+	DataAge() time.Duration
+}
+
+// RunOutagesCommand fetches and prints outages in a table. Outages still listed
+// past their planned end at now are flagged as overdue. When the provider had to
+// fall back to cached data, a warning with its age comes first.
+func RunOutagesCommand(ctx context.Context, provider outage.RawProvider, now time.Time, w io.Writer) error {
 	rows, err := provider.FetchOutages(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch outages: %w", err)
 	}
 
+	if r, ok := provider.(staleReporter); ok && r.DataAge() > 0 {
+		fmt.Fprintf(w, "Warning: the outage API is unavailable, data is %d minutes old.\n", int(r.DataAge().Minutes()))
+	}
+
 	if len(rows) == 0 {
 		fmt.Fprintln(w, "No outages found.")
 		return nil
@@ -41,6 +53,9 @@ func RunOutagesCommand(ctx context.Context, provider outage.RawProvider, w io.Wr
 	for _, o := range rows {
 		buildings := strings.Join(o.Buildings, ", ")
 		period := PeriodFormatter(o.Start, o.End)
+		if o.End.Before(now) {
+			period += " (overdue)"
+		}
 		table.Append([]string{fmt.Sprintf("%d", o.StreetID), o.StreetName, buildings, period, o.Comment})
 	}
 
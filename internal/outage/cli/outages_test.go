@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,6 +22,17 @@ func (m *mockOutageProviderForOutages) FetchOutages(_ context.Context) ([]outage
 	return m.outages, m.err
 }
 
+type staleOutageProvider struct {
+	mockOutageProviderForOutages
+	age time.Duration
+}
+
+func (m *staleOutageProvider) DataAge() time.Duration {
+	return m.age
+}
+
+var outagesNow = time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)
+
 func TestRunOutagesCommand_PrintsTable(t *testing.T) {
 	provider := &mockOutageProviderForOutages{
 		outages: []outage.RawOutage{
@@ -37,7 +49,7 @@ func TestRunOutagesCommand_PrintsTable(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := RunOutagesCommand(context.Background(), provider, &buf)
+	err := RunOutagesCommand(context.Background(), provider, outagesNow, &buf)
 	require.NoError(t, err)
 
 	output := buf.String()
@@ -53,11 +65,44 @@ func TestRunOutagesCommand_PrintsTable(t *testing.T) {
 	assert.Contains(t, output, "Ремонт")
 }
 
+func TestRunOutagesCommand_FlagsOverdueRows(t *testing.T) {
+	provider := &mockOutageProviderForOutages{
+		outages: []outage.RawOutage{
+			{
+				ID:         1,
+				StreetID:   100,
+				StreetName: "Стрийська",
+				Buildings:  []string{"10"},
+				Start:      time.Date(2024, 3, 15, 6, 0, 0, 0, time.UTC),
+				End:        time.Date(2024, 3, 15, 8, 30, 0, 0, time.UTC),
+				Comment:    "Аварія",
+			},
+			{
+				ID:         2,
+				StreetID:   100,
+				StreetName: "Стрийська",
+				Buildings:  []string{"12"},
+				Start:      time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC),
+				End:        time.Date(2024, 3, 15, 16, 0, 0, 0, time.UTC),
+				Comment:    "Ремонт",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, RunOutagesCommand(context.Background(), provider, outagesNow, &buf))
+
+	output := buf.String()
+	assert.Contains(t, output, "15.03.2024 06:00 - 08:30 (overdue)")
+	assert.Contains(t, output, "15.03.2024 08:00 - 16:00")
+	assert.Equal(t, 1, strings.Count(output, "(overdue)"))
+}
+
 func TestRunOutagesCommand_Empty(t *testing.T) {
 	provider := &mockOutageProviderForOutages{outages: []outage.RawOutage{}}
 
 	var buf bytes.Buffer
-	err := RunOutagesCommand(context.Background(), provider, &buf)
+	err := RunOutagesCommand(context.Background(), provider, outagesNow, &buf)
 	require.NoError(t, err)
 	assert.Equal(t, "No outages found.\n", buf.String())
 }
@@ -66,8 +111,29 @@ func TestRunOutagesCommand_ProviderError(t *testing.T) {
 	provider := &mockOutageProviderForOutages{err: errors.New("timeout")}
 
 	var buf bytes.Buffer
-	err := RunOutagesCommand(context.Background(), provider, &buf)
+	err := RunOutagesCommand(context.Background(), provider, outagesNow, &buf)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to fetch outages")
 	assert.Contains(t, err.Error(), "timeout")
 }
+
+func TestRunOutagesCommand_WarnsAboutStaleData(t *testing.T) {
+	provider := &staleOutageProvider{age: 42*time.Minute + 30*time.Second}
+
+	var buf bytes.Buffer
+	err := RunOutagesCommand(context.Background(), provider, outagesNow, &buf)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(buf.String(), "Warning: the outage API is unavailable, data is 42 minutes old.\n"))
+	assert.Contains(t, buf.String(), "No outages found.")
+}
+
+func TestRunOutagesCommand_NoWarningForFreshData(t *testing.T) {
+	provider := &staleOutageProvider{}
+
+	var buf bytes.Buffer
+	err := RunOutagesCommand(context.Background(), provider, outagesNow, &buf)
+	require.NoError(t, err)
+
+	assert.NotContains(t, buf.String(), "Warning")
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/loe"
+	"github.com/sl4wa/outages-bot/internal/outage/notifier"
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+)
+
+const replayStartFormat = "2006-01-02 15:04"
+
+// ReplayStep is a recorded API response and the time the notifier is simulated to fetch it.
+type ReplayStep struct {
+	Name string
+	Body []byte
+	At   time.Time
+}
+
+// NewReplaySteps reads the recorded responses at paths, in order. With an empty start each
+// response is simulated at its file's modification time; otherwise the first one is at start,
+// given as YYYY-MM-DD HH:MM in zone, and every next one interval later.
+func NewReplaySteps(paths []string, start string, interval time.Duration, zone *time.Location) ([]ReplayStep, error) {
+	var at time.Time
+	if start != "" {
+		t, err := time.ParseInLocation(replayStartFormat, start, zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --start time %q, expected YYYY-MM-DD HH:MM", start)
+		}
+		at = t
+	}
+
+	steps := make([]ReplayStep, len(paths))
+	for i, path := range paths {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recorded response: %w", err)
+		}
+		steps[i] = ReplayStep{Name: path, Body: body, At: at.Add(time.Duration(i) * interval)}
+		if start == "" {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read recorded response: %w", err)
+			}
+			steps[i].At = info.ModTime()
+		}
+	}
+	return steps, nil
+}
+
+// RunReplayCommand feeds the recorded responses one by one through the notifier, starting from
+// copies of the given users and no outage snapshot, and prints every decision it makes: the
+// notifier's own log lines, each message sent and each user whose messages are held back for
+// quiet hours. Nothing is sent or saved. Times are shown in zone.
+func RunReplayCommand(ctx context.Context, steps []ReplayStep, all []*users.User, zone *time.Location, remindBefore time.Duration, w io.Writer) error {
+	var now time.Time
+	provider := &parsedFeed{}
+	repo := newReplayUsers(all, w)
+	logger := log.New(w, "  ", 0)
+	notifyUsers := notifier.NewNotifyUsers(
+		outage.NewFetchOutages(provider).WithLogger(logger),
+		&replaySender{w: w, zone: zone},
+		repo,
+		&replaySnapshot{},
+		logger,
+	).
+		WithClock(func() time.Time { return now }, zone).
+		WithReminder(remindBefore)
+
+	for i, step := range steps {
+		now = step.At
+		fmt.Fprintf(w, "== Step %d: %s at %s ==\n", i+1, step.Name, now.In(zone).Format(dateTimeFormat))
+		rows, diagnostics, err := loe.ParseResponse(step.Body, step.Name, now)
+		if err != nil {
+			return fmt.Errorf("%s: %w", step.Name, err)
+		}
+		provider.rows, provider.diagnostics = rows, diagnostics
+		if err := notifyUsers.Handle(ctx); err != nil {
+			return fmt.Errorf("%s: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// parsedFeed serves the outage rows parsed from a saved API response.
+type parsedFeed struct {
+	rows        []outage.RawOutage
+	diagnostics []outage.Diagnostic
+}
+
+func (p *parsedFeed) FetchOutages(context.Context) ([]outage.RawOutage, error) {
+	return p.rows, nil
+}
+
+func (p *parsedFeed) Diagnostics() []outage.Diagnostic {
+	return p.diagnostics
+}
+
+// replaySnapshot keeps the outage snapshot in memory between steps.
+type replaySnapshot struct {
+	outages []*outage.Outage
+}
+
+func (s *replaySnapshot) Load() ([]*outage.Outage, error) {
+	return s.outages, nil
+}
+
+func (s *replaySnapshot) Save(outages []*outage.Outage) error {
+	s.outages = outages
+	return nil
+}
+
+// replayUsers keeps users in memory and reports those whose notifications are held back.
+type replayUsers struct {
+	users []*users.User
+	w     io.Writer
+}
+
+func newReplayUsers(all []*users.User, w io.Writer) *replayUsers {
+	return &replayUsers{users: slices.Clone(all), w: w}
+}
+
+func (r *replayUsers) FindAll() []*users.User {
+	return slices.Clone(r.users)
+}
+
+func (r *replayUsers) Save(user *users.User) error {
+	i := slices.IndexFunc(r.users, func(u *users.User) bool { return u.ID == user.ID })
+	if i < 0 {
+		r.users = append(r.users, user)
+		return nil
+	}
+	if user.HasCatchUp() && !r.users[i].HasCatchUp() {
+		fmt.Fprintf(r.w, "  chat %d: notifications held back until quiet hours end\n", user.ID)
+	}
+	r.users[i] = user
+	return nil
+}
+
+func (r *replayUsers) Remove(chatID int64) (bool, error) {
+	n := len(r.users)
+	r.users = slices.DeleteFunc(r.users, func(u *users.User) bool { return u.ID == chatID })
+	return len(r.users) < n, nil
+}
+
+// replaySender prints each message instead of sending it.
+type replaySender struct {
+	w    io.Writer
+	zone *time.Location
+}
+
+func (s *replaySender) Send(chatID int64, c notifier.Content) error {
+	mode := ""
+	if c.Silent {
+		mode = " (silent)"
+	}
+	fmt.Fprintf(s.w, "  chat %d: %s%s for %s: %s, %s\n",
+		chatID, c.Event, mode, c.SavedAddress.Label(),
+		PeriodFormatter(c.Start.In(s.zone), c.End.In(s.zone)), sanitizeDisplayText(c.Comment))
+	return nil
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const replayOutageBody = `{"hydra:member": [{
+	"id": 1,
+	"dateEvent": "2024-01-01T08:00:00+00:00",
+	"datePlanIn": "2024-01-01T16:00:00+00:00",
+	"koment": "Планове відключення",
+	"buildingNames": "10, 12",
+	"city": {"name": "Львів"},
+	"street": {"id": 1, "name": "Стрийська"}
+}]}`
+
+const replayEmptyBody = `{"hydra:member": []}`
+
+func replayUser(t *testing.T, chatID int64, building string) *users.User {
+	t.Helper()
+	addr, err := users.NewAddress(1, "Стрийська", building)
+	require.NoError(t, err)
+	return users.NewUser(chatID, addr)
+}
+
+func TestRunReplayCommand_ReportsDecisionsPerStep(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	steps := []ReplayStep{
+		{Name: "first.json", Body: []byte(replayOutageBody), At: start},
+		{Name: "second.json", Body: []byte(replayOutageBody), At: start.Add(time.Minute)},
+		{Name: "third.json", Body: []byte(replayEmptyBody), At: start.Add(2 * time.Minute)},
+	}
+	all := []*users.User{replayUser(t, 100, "10"), replayUser(t, 200, "14")}
+
+	var buf bytes.Buffer
+	require.NoError(t, RunReplayCommand(context.Background(), steps, all, time.UTC, 0, &buf))
+
+	assert.Equal(t, ""+
+		"== Step 1: first.json at 01.01.2024 09:00 ==\n"+
+		"  No prior outage data found; saving and continuing.\n"+
+		"  chat 100: outage for Стрийська, 10: 01.01.2024 08:00 - 16:00, Планове відключення\n"+
+		"== Step 2: second.json at 01.01.2024 09:01 ==\n"+
+		"  Outage data unchanged; checker/notifier logic skipped.\n"+
+		"== Step 3: third.json at 01.01.2024 09:02 ==\n"+
+		"  Outage data changed (0 new, 0 changed, 1 resolved); saving and continuing.\n"+
+		"  chat 100: restored for Стрийська, 10: 01.01.2024 08:00 - 16:00, Планове відключення\n",
+		buf.String())
+	assert.Empty(t, all[0].Addresses[0].Notified, "the given users are not modified")
+}
+
+func TestRunReplayCommand_ReportsHeldBackNotifications(t *testing.T) {
+	user := replayUser(t, 100, "10")
+	user.QuietHours = &users.QuietHours{Start: 0, End: 7, Mode: users.QuietDefer}
+	steps := []ReplayStep{{Name: "night.json", Body: []byte(replayOutageBody), At: time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)}}
+
+	var buf bytes.Buffer
+	require.NoError(t, RunReplayCommand(context.Background(), steps, []*users.User{user}, time.UTC, 0, &buf))
+
+	assert.Contains(t, buf.String(), "chat 100: notifications held back until quiet hours end")
+	assert.NotContains(t, buf.String(), "chat 100: outage")
+}
+
+func TestRunReplayCommand_InvalidPayload(t *testing.T) {
+	steps := []ReplayStep{{Name: "broken.json", Body: []byte("{"), At: time.Now()}}
+
+	err := RunReplayCommand(context.Background(), steps, nil, time.UTC, 0, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "broken.json")
+}
+
+func TestNewReplaySteps_StartAndInterval(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.json")
+	second := filepath.Join(dir, "b.json")
+	require.NoError(t, os.WriteFile(first, []byte(replayOutageBody), 0o644))
+	require.NoError(t, os.WriteFile(second, []byte(replayEmptyBody), 0o644))
+
+	steps, err := NewReplaySteps([]string{first, second}, "2024-01-01 09:00", 5*time.Minute, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), steps[0].At)
+	assert.Equal(t, time.Date(2024, 1, 1, 9, 5, 0, 0, time.UTC), steps[1].At)
+	assert.Equal(t, replayEmptyBody, string(steps[1].Body))
+}
+
+func TestNewReplaySteps_DefaultsToModificationTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.json")
+	require.NoError(t, os.WriteFile(path, []byte(replayOutageBody), 0o644))
+	modified := time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, modified, modified))
+
+	steps, err := NewReplaySteps([]string{path}, "", time.Minute, time.UTC)
+	require.NoError(t, err)
+	assert.True(t, modified.Equal(steps[0].At))
+}
+
+func TestNewReplaySteps_Errors(t *testing.T) {
+	_, err := NewReplaySteps([]string{"missing.json"}, "", time.Minute, time.UTC)
+	assert.Error(t, err)
+
+	_, err = NewReplaySteps(nil, "yesterday", time.Minute, time.UTC)
+	assert.ErrorContains(t, err, "invalid --start time")
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+// Stats output formats.
+const (
+	FormatTable = "table"
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+)
+
+type statsRow struct {
+	City         string  `json:"city"`
+	StreetID     int     `json:"street_id"`
+	Street       string  `json:"street"`
+	Building     string  `json:"building,omitempty"`
+	Outages      int     `json:"outages"`
+	TotalHours   float64 `json:"total_hours"`
+	AverageHours float64 `json:"average_hours"`
+	Resolved     int     `json:"resolved"`
+	Late         int     `json:"late"`
+}
+
+// RunStatsCommand prints outage statistics of the archived outages matching the filter,
+// grouped per street or building, as a table, CSV or JSON. Outages still listed are counted until now.
+func RunStatsCommand(history outage.HistoryReader, filter outage.HistoryFilter, by outage.StatsGrouping, format string, now time.Time, w io.Writer) error {
+	if format != FormatTable && format != FormatCSV && format != FormatJSON {
+		return fmt.Errorf("unknown format %q, expected table, csv or json", format)
+	}
+
+	entries, err := history.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to read outage history: %w", err)
+	}
+
+	stats := outage.ComputeStats(entries, filter, by, now)
+	rows := make([]statsRow, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, statsRow{
+			City:         s.City,
+			StreetID:     s.StreetID,
+			Street:       s.StreetName,
+			Building:     s.Building,
+			Outages:      s.Outages,
+			TotalHours:   roundHours(s.Duration.Hours()),
+			AverageHours: roundHours(s.AverageDuration().Hours()),
+			Resolved:     s.Resolved,
+			Late:         s.Late,
+		})
+	}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case FormatCSV:
+		return writeStatsCSV(rows, by, w)
+	}
+
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No outages found.")
+		return nil
+	}
+
+	cfg := tablewriter.NewConfigBuilder().
+		WithHeaderAutoFormat(tw.Off).
+		WithRowAutoWrap(tw.WrapNormal).
+		ForColumn(0).WithMaxWidth(30).Build().
+		Build()
+
+	table := tablewriter.NewTable(w,
+		tablewriter.WithConfig(cfg),
+		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{})),
+	)
+	header := []string{"Street"}
+	if by == outage.StatsByBuilding {
+		header = append(header, "Building")
+	}
+	table.Header(append(header, "Outages", "Total hours", "Average hours", "Restored late"))
+
+	for i, r := range rows {
+		row := []string{r.Street}
+		if by == outage.StatsByBuilding {
+			row = append(row, r.Building)
+		}
+		late := "-"
+		if r.Resolved > 0 {
+			late = fmt.Sprintf("%d of %d (%.0f%%)", r.Late, r.Resolved, stats[i].LateShare()*100)
+		}
+		table.Append(append(row,
+			strconv.Itoa(r.Outages),
+			formatHours(r.TotalHours),
+			formatHours(r.AverageHours),
+			late,
+		))
+	}
+
+	return table.Render()
+}
+
+func writeStatsCSV(rows []statsRow, by outage.StatsGrouping, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"city", "street_id", "street"}
+	if by == outage.StatsByBuilding {
+		header = append(header, "building")
+	}
+	if err := writer.Write(append(header, "outages", "total_hours", "average_hours", "resolved", "late")); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, r := range rows {
+		record := []string{r.City, strconv.Itoa(r.StreetID), r.Street}
+		if by == outage.StatsByBuilding {
+			record = append(record, r.Building)
+		}
+		record = append(record,
+			strconv.Itoa(r.Outages),
+			formatHours(r.TotalHours),
+			formatHours(r.AverageHours),
+			strconv.Itoa(r.Resolved),
+			strconv.Itoa(r.Late),
+		)
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func roundHours(h float64) float64 {
+	return math.Round(h*10) / 10
+}
+
+func formatHours(h float64) string {
+	return strconv.FormatFloat(h, 'f', 1, 64)
+}
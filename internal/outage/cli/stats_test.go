@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var statsNow = time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)
+
+func statsReader(t *testing.T) *mockHistoryReader {
+	t.Helper()
+	start := time.Date(2024, 3, 15, 8, 0, 0, 0, time.UTC)
+	late := makeHistoryEntry(t, 7, "Стрийська", []string{"10", "12"}, start)
+	late.ResolvedAt = start.Add(10 * time.Hour)
+	late.LastSeen = start.Add(9 * time.Hour)
+	return &mockHistoryReader{entries: []*outage.HistoryEntry{late}}
+}
+
+func TestRunStatsCommand_Table(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RunStatsCommand(statsReader(t), outage.HistoryFilter{}, outage.StatsByBuilding, FormatTable, statsNow, &buf))
+
+	output := buf.String()
+	assert.Contains(t, output, "Total hours")
+	assert.Contains(t, output, "Building")
+	assert.Contains(t, output, "Стрийська")
+	assert.Contains(t, output, "10.0")
+	assert.Contains(t, output, "1 of 1 (100%)")
+}
+
+func TestRunStatsCommand_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RunStatsCommand(statsReader(t), outage.HistoryFilter{}, outage.StatsByStreet, FormatCSV, statsNow, &buf))
+
+	assert.Equal(t, "city,street_id,street,outages,total_hours,average_hours,resolved,late\n"+
+		"Львів,1,Стрийська,1,10.0,10.0,1,1\n", buf.String())
+}
+
+func TestRunStatsCommand_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RunStatsCommand(statsReader(t), outage.HistoryFilter{}, outage.StatsByBuilding, FormatJSON, statsNow, &buf))
+
+	var rows []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &rows))
+	require.Len(t, rows, 2)
+	assert.Equal(t, "12", rows[1]["building"])
+	assert.Equal(t, 10.0, rows[1]["total_hours"])
+}
+
+func TestRunStatsCommand_UnknownFormat(t *testing.T) {
+	err := RunStatsCommand(statsReader(t), outage.HistoryFilter{}, outage.StatsByStreet, "xml", statsNow, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown format")
+}
+
+func TestRunStatsCommand_EmptyTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RunStatsCommand(&mockHistoryReader{}, outage.HistoryFilter{}, outage.StatsByStreet, FormatTable, statsNow, &buf))
+	assert.Equal(t, "No outages found.\n", buf.String())
+}
@@ -13,7 +13,7 @@ import (
 	"github.com/olekukonko/tablewriter/tw"
 )
 
-// RunUsersCommand lists all users with their Telegram info and addresses.
+// RunUsersCommand lists all users with their Telegram info, one row per saved address.
 func RunUsersCommand(
 	userRepo users.UserLister,
 	infoProvider users.InfoProvider,
@@ -54,19 +54,6 @@ func RunUsersCommand(
 			username = "@" + info.Username
 		}
 
-		outageStr := "-"
-		commentStr := "-"
-		if user.OutageInfo != nil {
-			outageStr = PeriodFormatter(
-				user.OutageInfo.Period.StartDate,
-				user.OutageInfo.Period.EndDate,
-			)
-			commentStr = user.OutageInfo.Description.Value
-			if commentStr == "" {
-				commentStr = "-"
-			}
-		}
-
 		firstName := sanitizeDisplayText(info.FirstName)
 		lastName := sanitizeDisplayText(info.LastName)
 		var nameParts []string
@@ -81,15 +68,39 @@ func RunUsersCommand(
 			name = strings.Join(nameParts, " ")
 		}
 
-		table.Append([]string{
-			fmt.Sprintf("%d", info.ChatID),
-			username,
-			name,
-			user.Address.StreetName,
-			user.Address.Building,
-			outageStr,
-			commentStr,
-		})
+		for _, saved := range user.Addresses {
+			outageStr := "-"
+			commentStr := "-"
+			if len(saved.Notified) > 0 {
+				periods := make([]string, 0, len(saved.Notified))
+				comments := make([]string, 0, len(saved.Notified))
+				for _, info := range saved.Notified {
+					periods = append(periods, PeriodFormatter(info.Period.StartDate, info.Period.EndDate))
+					comment := info.Description.Value
+					if comment == "" {
+						comment = "-"
+					}
+					comments = append(comments, comment)
+				}
+				outageStr = strings.Join(periods, "\n")
+				commentStr = strings.Join(comments, "\n")
+			}
+
+			building := saved.Address.Building
+			if saved.Address.WholeStreet() {
+				building = "all"
+			}
+
+			table.Append([]string{
+				fmt.Sprintf("%d", info.ChatID),
+				username,
+				name,
+				saved.Address.StreetName,
+				building,
+				outageStr,
+				commentStr,
+			})
+		}
 		successCount++
 	}
 
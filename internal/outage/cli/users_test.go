@@ -6,6 +6,7 @@ import (
 	"github.com/sl4wa/outages-bot/internal/outage/outage"
 	"github.com/sl4wa/outages-bot/internal/outage/users"
 	"log"
+	"strings"
 	"testing"
 	"time"
 
@@ -39,7 +40,7 @@ func makeUserWithAddr(t *testing.T, id int64, streetName, building string) *user
 	t.Helper()
 	addr, err := users.NewAddress(1, streetName, building)
 	require.NoError(t, err)
-	return &users.User{ID: id, Address: addr}
+	return users.NewUser(id, addr)
 }
 
 func TestRunUsersCommand_PrintsUsers(t *testing.T) {
@@ -137,7 +138,7 @@ func TestRunUsersCommand_OutageInfoFormatting(t *testing.T) {
 	info := users.NewOutageInfo(period, desc)
 
 	testUsers := []*users.User{
-		{ID: 100, Address: addr, OutageInfo: &info},
+		{ID: 100, Addresses: []users.SavedAddress{{Address: addr, Notified: []users.OutageInfo{info}}}},
 	}
 	repo := &mockUserRepoForUsers{users: testUsers}
 	infoProvider := &mockInfoProvider{
@@ -156,6 +157,28 @@ func TestRunUsersCommand_OutageInfoFormatting(t *testing.T) {
 	assert.Contains(t, output, "Ремонт")
 }
 
+func TestRunUsersCommand_MultipleAddresses_OneRowEach(t *testing.T) {
+	office, _ := users.NewAddress(2, "Наукова", "5")
+	testUsers := []*users.User{
+		makeUserWithAddr(t, 100, "Стрийська", "10").WithAddress(office),
+	}
+	repo := &mockUserRepoForUsers{users: testUsers}
+	infoProvider := &mockInfoProvider{
+		infos: map[int64]users.Info{
+			100: {ChatID: 100, Username: "user1"},
+		},
+	}
+
+	var buf bytes.Buffer
+	RunUsersCommand(repo, infoProvider, &buf, log.New(&bytes.Buffer{}, "", 0))
+
+	output := buf.String()
+	assert.Contains(t, output, "Стрийська")
+	assert.Contains(t, output, "Наукова")
+	assert.Equal(t, 2, strings.Count(output, "@user1"))
+	assert.Contains(t, output, "Total Users: 1")
+}
+
 func TestRunUsersCommand_SanitizationApplied(t *testing.T) {
 	testUsers := []*users.User{
 		makeUserWithAddr(t, 100, "Стрийська", "10"),
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/sl4wa/outages-bot/internal/outage/loe"
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/shared/httpcache"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/renderer"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+// RunValidateCommand fetches the outage feed through provider, converts it the way the
+// notifier does and prints a report of every row dropped or corrected on the way.
+func RunValidateCommand(ctx context.Context, provider outage.RawProvider, w io.Writer) error {
+	fetch := outage.NewFetchOutages(provider)
+	outages, err := fetch.Handle(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch outages: %w", err)
+	}
+	diagnostics := fetch.Diagnostics()
+
+	dropped := 0
+	for _, d := range diagnostics {
+		if d.Dropped {
+			dropped++
+		}
+	}
+	fmt.Fprintf(w, "%d outages accepted, %d rows dropped, %d values corrected.\n", len(outages), dropped, len(diagnostics)-dropped)
+	if len(diagnostics) == 0 {
+		fmt.Fprintln(w, "No problems found.")
+		return nil
+	}
+
+	cfg := tablewriter.NewConfigBuilder().
+		WithHeaderAutoFormat(tw.Off).
+		WithRowAutoWrap(tw.WrapNormal).
+		ForColumn(0).WithMaxWidth(40).Build().
+		ForColumn(6).WithMaxWidth(60).Build().
+		Build()
+	table := tablewriter.NewTable(w,
+		tablewriter.WithConfig(cfg),
+		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{})),
+	)
+	table.Header([]string{"Source", "Row", "ID", "Field", "Action", "Reason", "Detail"})
+	for _, d := range diagnostics {
+		action := "corrected"
+		if d.Dropped {
+			action = "dropped"
+		}
+		table.Append([]string{orDash(d.Source), orDash(strconv.Itoa(d.Row)), orDash(strconv.Itoa(d.ID)), d.Field, action, d.Reason, d.Detail})
+	}
+	if err := table.Render(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nBy kind:")
+	for _, c := range outage.CountDiagnostics(diagnostics) {
+		fmt.Fprintf(w, "  %s: %d\n", c.Kind, c.Count)
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" || s == "0" {
+		return "-"
+	}
+	return s
+}
+
+// NewFileFeed reads a saved API response, either the JSON body itself or an HTTP cache or
+// archive file holding it, and parses it as if fetched at now.
+func NewFileFeed(path string, now time.Time) (outage.DiagnosingProvider, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if filepath.Ext(path) == ".http-cache" {
+		entry := httpcache.Load(path)
+		if entry == nil {
+			return nil, fmt.Errorf("%s is not a valid HTTP cache file", path)
+		}
+		body = entry.Body
+	}
+	rows, diagnostics, err := loe.ParseResponse(body, path, now)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &parsedFeed{rows: rows, diagnostics: diagnostics}, nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/shared/httpcache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validateBody = `{"hydra:member":[` +
+	`{"id":1,"dateEvent":"2024-03-15T08:00:00+00:00","datePlanIn":"2024-03-15T16:00:00+00:00","buildingNames":"10","street":{"id":100,"name":"Стрийська"}},` +
+	`{"id":2,"dateEvent":"2024-03-15T08:00:00+00:00","datePlanIn":"2024-03-15T06:00:00+00:00","buildingNames":"12","street":{"id":100,"name":"Стрийська"}},` +
+	`{"id":3,"dateEvent":"2024-03-15T08:00:00+00:00","buildingNames":"14","street":{"id":100,"name":"Стрийська"}},` +
+	`42]}`
+
+func TestRunValidateCommand_PrintsReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "response.json")
+	require.NoError(t, os.WriteFile(path, []byte(validateBody), 0o644))
+	feed, err := NewFileFeed(path, time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, RunValidateCommand(context.Background(), feed, &buf))
+
+	output := buf.String()
+	assert.Contains(t, output, "2 outages accepted, 2 rows dropped, 1 values corrected.")
+	assert.Contains(t, output, "undecodable row")
+	assert.Contains(t, output, "missing date")
+	assert.Contains(t, output, "invalid period")
+	assert.Contains(t, output, "By kind:\n  datePlanIn: missing date: 1\n  row: undecodable row: 1\n  period: invalid period: 1\n")
+}
+
+func TestRunValidateCommand_CleanFeed(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RunValidateCommand(context.Background(), &parsedFeed{}, &buf))
+
+	assert.Equal(t, "0 outages accepted, 0 rows dropped, 0 values corrected.\nNo problems found.\n", buf.String())
+}
+
+func TestNewFileFeed_ReadsHTTPCacheFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outages.http-cache")
+	require.NoError(t, httpcache.Save(path, `"v1"`, []byte(validateBody)))
+
+	feed, err := NewFileFeed(path, time.Now())
+	require.NoError(t, err)
+
+	rows, err := feed.FetchOutages(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Len(t, feed.Diagnostics(), 2)
+	assert.Equal(t, path, feed.Diagnostics()[0].Source)
+}
+
+func TestNewFileFeed_RejectsInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "response.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o644))
+
+	_, err := NewFileFeed(path, time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse API response")
+}
@@ -9,6 +9,9 @@ import (
 	"log"
 	"math"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -19,36 +22,141 @@ var newlineRegex = regexp.MustCompile(`[\r\n]+`)
 
 const DefaultCacheFileName = "outages.http-cache"
 
+// maxPages caps how many pages of one endpoint are followed, in case the next links loop.
+const maxPages = 100
+
+// CityCacheFileName returns the HTTP cache file name for a city-scoped endpoint.
+func CityCacheFileName(cityID int) string {
+	return fmt.Sprintf("outages-%d.http-cache", cityID)
+}
+
+// MigrateLegacyCacheFile renames the cache file kept in dir before endpoints were scoped to
+// cities, DefaultCacheFileName, to the cache file of the given city, unless that city has
+// one already. It reports whether the file was renamed.
+func MigrateLegacyCacheFile(dir string, cityID int) (bool, error) {
+	legacy := filepath.Join(dir, DefaultCacheFileName)
+	if _, err := os.Stat(legacy); err != nil {
+		return false, nil
+	}
+	city := filepath.Join(dir, CityCacheFileName(cityID))
+	if _, err := os.Stat(city); err == nil {
+		return false, nil
+	}
+	if err := os.Rename(legacy, city); err != nil {
+		return false, fmt.Errorf("failed to migrate outage cache file: %w", err)
+	}
+	return true, nil
+}
+
+// Endpoint is a single outage API URL, typically scoped to one OTG and city,
+// with its own HTTP cache file.
+type Endpoint struct {
+	URL       string
+	CacheFile string
+	// Archive, when set, keeps every changed response, including those that fail to parse.
+	Archive *httpcache.Archive
+	// Breaker, when set, replaces the policy's breaker for this endpoint, so that a failing
+	// endpoint does not keep the others from being fetched.
+	Breaker *httpcache.Breaker
+}
+
 // Provider fetches outages from the Lviv power outage API.
 type Provider struct {
-	baseURL   string
+	endpoints []Endpoint
 	client    *http.Client
+	policy    httpcache.Policy
 	clock     func() time.Time
-	cacheFile string
 	logger    *log.Logger
+	// maxStale is how old a cached body may be to stand in for an unreachable API; zero disables it.
+	maxStale      time.Duration
+	readOnlyCache bool
+	dataAge       time.Duration
+	diagnostics   []outage.Diagnostic
 }
 
-// NewProvider creates a new Provider.
+// NewProvider creates a new Provider for a single API URL.
 func NewProvider(baseURL string, clock func() time.Time, logger *log.Logger) *Provider {
+	return NewMultiProvider([]Endpoint{{URL: baseURL}}, clock, logger)
+}
+
+// NewMultiProvider creates a Provider that fetches every endpoint and merges the results.
+func NewMultiProvider(endpoints []Endpoint, clock func() time.Time, logger *log.Logger) *Provider {
 	if clock == nil {
 		clock = time.Now
 	}
 	return &Provider{
-		baseURL: baseURL,
-		client:  &http.Client{Timeout: 30 * time.Second},
-		clock:   clock,
-		logger:  logger,
+		endpoints: endpoints,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		clock:     clock,
+		logger:    logger,
 	}
 }
 
-// WithCacheFile sets the path for HTTP-level ETag caching.
+// WithCacheFile sets the path for HTTP-level ETag caching of a single-endpoint provider.
 func (p *Provider) WithCacheFile(path string) *Provider {
-	p.cacheFile = path
+	if len(p.endpoints) == 1 {
+		p.endpoints[0].CacheFile = path
+	}
+	return p
+}
+
+// WithArchive sets the archive of a single-endpoint provider.
+func (p *Provider) WithArchive(archive *httpcache.Archive) *Provider {
+	if len(p.endpoints) == 1 {
+		p.endpoints[0].Archive = archive
+	}
+	return p
+}
+
+// WithPolicy sets how failed requests are retried. Endpoints without a breaker of their own
+// share the policy's breaker.
+func (p *Provider) WithPolicy(policy httpcache.Policy) *Provider {
+	p.policy = policy
+	return p
+}
+
+// WithStaleIfError serves the cached outages, up to maxAge old, when the API cannot be reached.
+func (p *Provider) WithStaleIfError(maxAge time.Duration) *Provider {
+	p.maxStale = maxAge
 	return p
 }
 
+// WithReadOnlyCache uses the cache files without updating them, so that an occasional
+// fetch does not disturb the cache of the notifier owning them.
+func (p *Provider) WithReadOnlyCache() *Provider {
+	p.readOnlyCache = true
+	return p
+}
+
+// DataAge reports how old the oldest cached body served in place of the API by the last
+// FetchOutages was; zero when every endpoint answered.
+func (p *Provider) DataAge() time.Duration {
+	return p.dataAge
+}
+
+// Diagnostics returns the rows the last FetchOutages dropped or corrected while parsing.
+func (p *Provider) Diagnostics() []outage.Diagnostic {
+	return p.diagnostics
+}
+
+// CityURL scopes baseURL to the given OTG and city by setting the otg.id and city.id query parameters.
+func CityURL(baseURL string, otgID, cityID int) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid outage API URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("otg.id", strconv.Itoa(otgID))
+	q.Set("city.id", strconv.Itoa(cityID))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
 type apiResponse struct {
 	HydraMember []json.RawMessage `json:"hydra:member"`
+	HydraView   struct {
+		Next string `json:"hydra:next"`
+	} `json:"hydra:view"`
 }
 
 type apiRow struct {
@@ -59,37 +167,152 @@ type apiRow struct {
 	BuildingNames json.RawMessage `json:"buildingNames"`
 	City          json.RawMessage `json:"city"`
 	Street        json.RawMessage `json:"street"`
+	TypeOff       interface{}     `json:"typeOff"`
+	SubReason     interface{}     `json:"switchSubReason"`
+	OTG           json.RawMessage `json:"otg"`
 }
 
 type cityObj struct {
 	Name string `json:"name"`
 }
 
+type otgObj struct {
+	Name string `json:"name"`
+}
+
 type streetObj struct {
 	ID   interface{} `json:"id"`
 	Name string      `json:"name"`
 }
 
-// FetchOutages fetches outages from the API.
+// FetchOutages fetches outages from every endpoint and merges them.
+// A failure on any endpoint fails the whole fetch so that callers never mistake
+// a missing city for one without outages.
 func (p *Provider) FetchOutages(ctx context.Context) ([]outage.RawOutage, error) {
-	result, err := httpcache.Get(ctx, p.client, p.baseURL, p.cacheFile)
+	p.dataAge = 0
+	p.diagnostics = nil
+	if len(p.endpoints) == 1 {
+		return p.fetchEndpoint(ctx, p.endpoints[0])
+	}
+
+	var merged []outage.RawOutage
+	seen := make(map[string]int)
+	for _, ep := range p.endpoints {
+		outages, err := p.fetchEndpoint(ctx, ep)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ep.URL, err)
+		}
+		merged = mergeOutages(merged, seen, outages)
+	}
+	return merged, nil
+}
+
+// fetchEndpoint fetches every page of the endpoint, following the hydra:next links, and
+// merges them. Each page is cached and archived on its own.
+func (p *Provider) fetchEndpoint(ctx context.Context, ep Endpoint) ([]outage.RawOutage, error) {
+	var merged []outage.RawOutage
+	seen := make(map[string]int)
+	page := ep
+	for n := 1; ; n++ {
+		rows, next, err := p.fetchPage(ctx, page)
+		if err != nil {
+			if n > 1 {
+				err = fmt.Errorf("page %d: %w", n, err)
+			}
+			return nil, err
+		}
+		merged = mergeOutages(merged, seen, rows)
+		if next == "" {
+			return merged, nil
+		}
+		if n == maxPages {
+			return nil, fmt.Errorf("outage API response has more than %d pages", maxPages)
+		}
+		if page, err = ep.page(n+1, page.URL, next); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// page returns the endpoint of page n, linked to from the page at current.
+func (ep Endpoint) page(n int, current, next string) (Endpoint, error) {
+	base, err := url.Parse(current)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid outage API URL: %w", err)
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid next page link %q: %w", next, err)
+	}
+	page := Endpoint{URL: base.ResolveReference(ref).String(), Archive: ep.Archive.Sub(fmt.Sprintf("page%d", n)), Breaker: ep.Breaker}
+	if ep.CacheFile != "" {
+		page.CacheFile = fmt.Sprintf("%s-page%d.http-cache", strings.TrimSuffix(ep.CacheFile, ".http-cache"), n)
+	}
+	return page, nil
+}
+
+// fetchPage fetches a single page and returns its outages and the link to the next page, if any.
+func (p *Provider) fetchPage(ctx context.Context, ep Endpoint) ([]outage.RawOutage, string, error) {
+	policy := p.policy
+	policy.StaleIfError = p.maxStale > 0
+	if ep.Breaker != nil {
+		policy.Breaker = ep.Breaker
+	}
+	var before httpcache.BreakerState
+	if policy.Breaker != nil {
+		before = policy.Breaker.State()
+	}
+	result, err := httpcache.Get(ctx, p.client, ep.URL, ep.CacheFile, policy)
+	if policy.Breaker != nil && p.logger != nil {
+		if after := policy.Breaker.State(); after != before {
+			p.logger.Printf("outageapi: circuit breaker for %s %s -> %s", ep.URL, before, after)
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch outages: %w", err)
+		return nil, "", fmt.Errorf("failed to fetch outages: %w", err)
+	}
+	if result.Stale {
+		age := result.Age.Round(time.Minute)
+		if result.Age > p.maxStale {
+			return nil, "", fmt.Errorf("failed to fetch outages, cached ones are %s old: %w", age, result.Err)
+		}
+		if p.logger != nil {
+			p.logger.Printf("outageapi: %v; using cached body from %s ago", result.Err, age)
+		}
+		p.dataAge = max(p.dataAge, result.Age)
+		return p.parseBody(result.Body, ep.URL)
 	}
 
 	switch result.StatusCode {
 	case http.StatusNotModified:
+		if result.Fresh {
+			if p.logger != nil {
+				p.logger.Printf("outageapi: cache fresh, using cached body without a request")
+			}
+			return p.parseBody(result.Body, ep.URL)
+		}
 		if p.logger != nil {
 			p.logger.Printf("outageapi: cache hit (304), using cached body")
 		}
-		return p.parseBody(result.Body)
+		if ep.CacheFile != "" && !p.readOnlyCache {
+			// Record the revalidation, which also moves older cache files to the current format.
+			if saveErr := httpcache.SaveEntry(ep.CacheFile, result.Entry()); saveErr != nil && p.logger != nil {
+				p.logger.Printf("outageapi: failed to save cache: %v", saveErr)
+			}
+		}
+		return p.parseBody(result.Body, ep.URL)
 	case http.StatusOK:
-		outages, err := p.parseBody(result.Body)
+		if ep.Archive != nil {
+			if err := ep.Archive.Store(result.ETag, result.Body); err != nil && p.logger != nil {
+				p.logger.Printf("outageapi: %v", err)
+			}
+		}
+		outages, next, err := p.parseBody(result.Body, ep.URL)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		if p.cacheFile != "" {
-			if saveErr := httpcache.Save(p.cacheFile, result.ETag, result.Body); saveErr != nil {
+		if ep.CacheFile != "" && !p.readOnlyCache {
+			if saveErr := httpcache.SaveEntry(ep.CacheFile, result.Entry()); saveErr != nil {
 				if p.logger != nil {
 					p.logger.Printf("outageapi: failed to save cache: %v", saveErr)
 				}
@@ -97,28 +320,65 @@ func (p *Provider) FetchOutages(ctx context.Context) ([]outage.RawOutage, error)
 				p.logger.Printf("outageapi: cache miss (200), updated cache")
 			}
 		}
-		return outages, nil
+		return outages, next, nil
 	default:
-		return nil, fmt.Errorf("outage API returned status %d", result.StatusCode)
+		return nil, "", fmt.Errorf("outage API returned status %d", result.StatusCode)
 	}
 }
 
-func (p *Provider) parseBody(body []byte) ([]outage.RawOutage, error) {
+// ParseResponse parses a saved API response body the way a live fetch would and reports
+// the rows it dropped or corrected, attributed to source. Rows without a date are dated at now.
+func ParseResponse(body []byte, source string, now time.Time) ([]outage.RawOutage, []outage.Diagnostic, error) {
+	p := NewProvider("", func() time.Time { return now }, nil)
+	rows, _, err := p.parseBody(body, source)
+	return rows, p.diagnostics, err
+}
+
+// parseBody parses one page of the API response and returns its outages and the link to the next page.
+// Rows that are dropped or corrected are added to the provider's diagnostics, attributed to source.
+func (p *Provider) parseBody(body []byte, source string) ([]outage.RawOutage, string, error) {
 	var apiResp apiResponse
 	if err := json.Unmarshal(body, &apiResp); err != nil {
-		return nil, fmt.Errorf("failed to parse API response: %w", err)
+		return nil, "", fmt.Errorf("failed to parse API response: %w", err)
 	}
 
-	var outages []outage.RawOutage
-	seen := make(map[string]int) // key → index in outages
+	var rows []outage.RawOutage
 
-	for _, raw := range apiResp.HydraMember {
+	for i, raw := range apiResp.HydraMember {
+		diagnostic := outage.Diagnostic{Source: source, Row: i + 1}
 		var row apiRow
 		if err := json.Unmarshal(raw, &row); err != nil {
+			diagnostic.Field, diagnostic.Reason, diagnostic.Detail, diagnostic.Dropped = "row", "undecodable row", err.Error(), true
+			p.diagnostics = append(p.diagnostics, diagnostic)
 			continue
 		}
 
 		id := toInt(row.ID)
+		diagnostic.ID = id
+		corrected := func(field, reason, detail string) {
+			d := diagnostic
+			d.Field, d.Reason, d.Detail = field, reason, detail
+			p.diagnostics = append(p.diagnostics, d)
+		}
+		decode := func(field string, raw json.RawMessage, v any) {
+			if raw == nil {
+				return
+			}
+			if err := json.Unmarshal(raw, v); err != nil {
+				corrected(field, "undecodable object", err.Error()+", left empty")
+			}
+		}
+		date := func(field, value string) time.Time {
+			t, ok := p.parseDate(value)
+			switch {
+			case ok:
+			case value == "":
+				corrected(field, "missing date", "used the fetch time")
+			default:
+				corrected(field, "invalid date", fmt.Sprintf("%q, used the fetch time", value))
+			}
+			return t
+		}
 
 		comment := newlineRegex.ReplaceAllString(row.Comment, " ")
 		comment = strings.TrimSpace(comment)
@@ -126,23 +386,20 @@ func (p *Provider) parseBody(body []byte) ([]outage.RawOutage, error) {
 		buildings := p.parseBuildings(row.BuildingNames)
 
 		var city cityObj
-		if row.City != nil {
-			json.Unmarshal(row.City, &city)
-		}
+		decode("city", row.City, &city)
 
 		var street streetObj
-		if row.Street != nil {
-			json.Unmarshal(row.Street, &street)
-		}
+		decode("street", row.Street, &street)
 
-		streetID := toInt(street.ID)
+		var otg otgObj
+		decode("otg", row.OTG, &otg)
 
-		start := p.parseDate(row.DateEvent)
-		end := p.parseDate(row.DatePlanIn)
+		streetID := toInt(street.ID)
 
-		key := fmt.Sprintf("%d|%s|%d|%d", streetID, strings.Join(buildings, ","), start.Unix(), end.Unix())
+		start := date("dateEvent", row.DateEvent)
+		end := date("datePlanIn", row.DatePlanIn)
 
-		dto := outage.RawOutage{
+		rows = append(rows, outage.RawOutage{
 			ID:         id,
 			Start:      start,
 			End:        end,
@@ -151,32 +408,45 @@ func (p *Provider) parseBody(body []byte) ([]outage.RawOutage, error) {
 			StreetName: street.Name,
 			Buildings:  buildings,
 			Comment:    comment,
-		}
+			TypeOff:    toInt(row.TypeOff),
+			SubReason:  toInt(row.SubReason),
+			OTG:        otg.Name,
+		})
+	}
 
+	return mergeOutages(nil, make(map[string]int), rows), apiResp.HydraView.Next, nil
+}
+
+// mergeOutages appends rows to outages, replacing earlier rows that share a dedup key
+// in place so that the last duplicate wins at the position of the first.
+// seen maps keys to indices in outages and is updated.
+func mergeOutages(outages []outage.RawOutage, seen map[string]int, rows []outage.RawOutage) []outage.RawOutage {
+	for _, row := range rows {
+		key := fmt.Sprintf("%d|%s|%d|%d", row.StreetID, strings.Join(row.Buildings, ","), row.Start.Unix(), row.End.Unix())
 		if idx, ok := seen[key]; ok {
-			outages[idx] = dto
+			outages[idx] = row
 		} else {
 			seen[key] = len(outages)
-			outages = append(outages, dto)
+			outages = append(outages, row)
 		}
 	}
-
-	return outages, nil
+	return outages
 }
 
-func (p *Provider) parseDate(dateStr string) time.Time {
+// parseDate parses a row date, falling back to the current time, and false, when it is missing or invalid.
+func (p *Provider) parseDate(dateStr string) (time.Time, bool) {
 	if dateStr == "" {
-		return p.clock()
+		return p.clock(), false
 	}
 	t, err := time.Parse(time.RFC3339, dateStr)
 	if err != nil {
 		// Try other common formats
 		t, err = time.Parse("2006-01-02T15:04:05", dateStr)
 		if err != nil {
-			return p.clock()
+			return p.clock(), false
 		}
 	}
-	return t
+	return t, true
 }
 
 func (p *Provider) parseBuildings(raw json.RawMessage) []string {
@@ -1,8 +1,11 @@
 package loe
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -10,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
 	"github.com/sl4wa/outages-bot/internal/shared/httpcache"
 
 	"github.com/stretchr/testify/assert"
@@ -128,6 +132,20 @@ func TestApiProvider_EmptyCityAndStreet(t *testing.T) {
 	assert.Equal(t, 0, result[0].StreetID)
 }
 
+func TestApiProvider_TypeReasonAndOTG(t *testing.T) {
+	body := `{"hydra:member":[{"id":1,"dateEvent":"2024-01-01T08:00:00+00:00","datePlanIn":"2024-01-01T16:00:00+00:00","koment":"Застосування ГАВ","buildingNames":"10","street":{"id":1,"name":"S"},"typeOff":1,"switchSubReason":"1","otg":{"id":28,"name":"Львівська"}}]}`
+	server := makeServer(t, 200, body)
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil)
+	result, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, 1, result[0].TypeOff)
+	assert.Equal(t, 1, result[0].SubReason)
+	assert.Equal(t, "Львівська", result[0].OTG)
+}
+
 func TestApiProvider_CommentWithCRLF(t *testing.T) {
 	body := `{"hydra:member":[{"id":1,"dateEvent":"2024-01-01T08:00:00+00:00","datePlanIn":"2024-01-01T16:00:00+00:00","koment":"line1\r\nline2","buildingNames":"10","street":{"id":1,"name":"S"}}]}`
 	server := makeServer(t, 200, body)
@@ -282,6 +300,38 @@ func TestProvider_200_WritesCacheAfterSuccessfulParse(t *testing.T) {
 	assert.Equal(t, etag, secondCallHeader)
 }
 
+func TestProvider_ArchivesChangedResponses_EvenUnparsable(t *testing.T) {
+	archiveDir := filepath.Join(t.TempDir(), httpcache.ArchiveDirName)
+	bodies := []string{validBody, validBody, "not json"}
+	etags := []string{`"v1"`, `"v1"`, `"v2"`}
+	call := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etags[call])
+		w.Write([]byte(bodies[call]))
+		call++
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil).
+		WithArchive(httpcache.NewArchive(archiveDir, "outages", httpcache.Retention{}))
+	for range 2 {
+		_, err := provider.FetchOutages(context.Background())
+		require.NoError(t, err)
+	}
+	time.Sleep(time.Millisecond)
+	_, err := provider.FetchOutages(context.Background())
+	require.Error(t, err)
+
+	archived, err := httpcache.ListArchive(archiveDir)
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	for i, etag := range []string{`"v1"`, `"v2"`} {
+		entry, err := httpcache.LoadArchived(archiveDir, archived[i].File)
+		require.NoError(t, err)
+		assert.Equal(t, etag, entry.ETag)
+	}
+}
+
 func TestProvider_304_WithCache_ReturnsCachedBody(t *testing.T) {
 	dir := t.TempDir()
 	cacheFile := filepath.Join(dir, "outages.http-cache")
@@ -392,3 +442,383 @@ func TestProvider_200_MalformedBody_PreservesExistingCache(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, originalContent, afterContent)
 }
+
+func TestCityURL_SetsOTGAndCity(t *testing.T) {
+	got, err := CityURL("https://power-api.loe.lviv.ua/api/pw_accidents?pagination=false&otg.id=28&city.id=693", 31, 700)
+	require.NoError(t, err)
+	assert.Contains(t, got, "pagination=false")
+	assert.Contains(t, got, "otg.id=31")
+	assert.Contains(t, got, "city.id=700")
+	assert.NotContains(t, got, "693")
+}
+
+func TestMultiProvider_MergesEndpointsWithSeparateCaches(t *testing.T) {
+	lviv := `{"hydra:member":[{"id":1,"dateEvent":"2024-01-01T08:00:00+00:00","datePlanIn":"2024-01-01T16:00:00+00:00","koment":"a","buildingNames":"10","city":{"name":"Львів"},"street":{"id":1,"name":"Стрийська"}}]}`
+	suburb := `{"hydra:member":[
+		{"id":2,"dateEvent":"2024-01-01T08:00:00+00:00","datePlanIn":"2024-01-01T16:00:00+00:00","koment":"b","buildingNames":"5","city":{"name":"Винники"},"street":{"id":2,"name":"Галицька"}},
+		{"id":3,"dateEvent":"2024-01-01T08:00:00+00:00","datePlanIn":"2024-01-01T16:00:00+00:00","koment":"c","buildingNames":"10","city":{"name":"Львів"},"street":{"id":1,"name":"Стрийська"}}
+	]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+r.URL.Query().Get("city.id")+`"`)
+		if r.URL.Query().Get("city.id") == "693" {
+			w.Write([]byte(lviv))
+			return
+		}
+		w.Write([]byte(suburb))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	lvivURL, err := CityURL(server.URL, 28, 693)
+	require.NoError(t, err)
+	suburbURL, err := CityURL(server.URL, 28, 700)
+	require.NoError(t, err)
+	provider := NewMultiProvider([]Endpoint{
+		{URL: lvivURL, CacheFile: filepath.Join(dir, "lviv.http-cache")},
+		{URL: suburbURL, CacheFile: filepath.Join(dir, "suburb.http-cache")},
+	}, fixedClock(), nil)
+
+	result, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result, 2, "duplicate row across endpoints is merged")
+	assert.Equal(t, 3, result[0].ID)
+	assert.Equal(t, "Винники", result[1].City)
+	assert.Equal(t, `"693"`, httpcache.Load(filepath.Join(dir, "lviv.http-cache")).ETag)
+	assert.Equal(t, `"700"`, httpcache.Load(filepath.Join(dir, "suburb.http-cache")).ETag)
+}
+
+func TestMigrateLegacyCacheFile(t *testing.T) {
+	dir := t.TempDir()
+	migrated, err := MigrateLegacyCacheFile(dir, 693)
+	require.NoError(t, err)
+	assert.False(t, migrated, "no legacy file")
+
+	require.NoError(t, httpcache.Save(filepath.Join(dir, DefaultCacheFileName), `"legacy"`, []byte(validBody)))
+	migrated, err = MigrateLegacyCacheFile(dir, 693)
+	require.NoError(t, err)
+	assert.True(t, migrated)
+	assert.Equal(t, `"legacy"`, httpcache.Load(filepath.Join(dir, CityCacheFileName(693))).ETag)
+	assert.NoFileExists(t, filepath.Join(dir, DefaultCacheFileName))
+
+	// A city cache file written since is never replaced.
+	require.NoError(t, httpcache.Save(filepath.Join(dir, DefaultCacheFileName), `"older"`, []byte(validBody)))
+	migrated, err = MigrateLegacyCacheFile(dir, 693)
+	require.NoError(t, err)
+	assert.False(t, migrated)
+	assert.Equal(t, `"legacy"`, httpcache.Load(filepath.Join(dir, CityCacheFileName(693))).ETag)
+}
+
+func TestMultiProvider_AnyEndpointFailureFailsFetch(t *testing.T) {
+	ok := makeServer(t, 200, validBody)
+	defer ok.Close()
+	broken := makeServer(t, 502, "bad gateway")
+	defer broken.Close()
+
+	provider := NewMultiProvider([]Endpoint{{URL: ok.URL}, {URL: broken.URL}}, fixedClock(), nil)
+	result, err := provider.FetchOutages(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+	assert.Nil(t, result)
+}
+
+func TestProvider_WithPolicy_RetriesTransientFailures(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(validBody))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil).
+		WithPolicy(httpcache.Policy{Attempts: 2, BaseDelay: time.Millisecond})
+	result, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, 2, calls)
+}
+
+func TestProvider_OpenBreakerSkipsRequests(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	breaker := httpcache.NewBreaker(1, time.Hour)
+
+	provider := NewProvider(server.URL, fixedClock(), nil).
+		WithPolicy(httpcache.Policy{Attempts: 1, Breaker: breaker})
+	_, err := provider.FetchOutages(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+	assert.Equal(t, httpcache.BreakerOpen, breaker.State())
+
+	_, err = provider.FetchOutages(context.Background())
+	require.ErrorIs(t, err, httpcache.ErrCircuitOpen)
+	assert.Equal(t, 1, calls)
+}
+
+func TestMultiProvider_EndpointBreakersAreIndependent(t *testing.T) {
+	okCalls := 0
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		okCalls++
+		w.Write([]byte(validBody))
+	}))
+	defer ok.Close()
+	broken := makeServer(t, 502, "bad gateway")
+	defer broken.Close()
+
+	var logs bytes.Buffer
+	brokenBreaker := httpcache.NewBreaker(1, time.Hour)
+	provider := NewMultiProvider([]Endpoint{
+		{URL: ok.URL, Breaker: httpcache.NewBreaker(1, time.Hour)},
+		{URL: broken.URL, Breaker: brokenBreaker},
+	}, fixedClock(), log.New(&logs, "", 0)).WithPolicy(httpcache.Policy{Attempts: 1})
+
+	_, err := provider.FetchOutages(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, httpcache.BreakerOpen, brokenBreaker.State())
+	assert.Contains(t, logs.String(), "circuit breaker for "+broken.URL+" closed -> open")
+
+	// The open breaker of the failing endpoint does not keep the other one from being fetched.
+	_, err = provider.FetchOutages(context.Background())
+	require.ErrorIs(t, err, httpcache.ErrCircuitOpen)
+	assert.Equal(t, 2, okCalls)
+}
+
+func TestProvider_StaleIfError_ServesCachedBodyWithAge(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "outages.http-cache")
+	entry := &httpcache.Entry{ETag: `"abc"`, Body: []byte(validBody), FetchedAt: time.Now().Add(-20 * time.Minute)}
+	require.NoError(t, httpcache.SaveEntry(cacheFile, entry))
+	server := makeServer(t, 502, "bad gateway")
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil).WithCacheFile(cacheFile).WithStaleIfError(time.Hour)
+	result, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.InDelta(t, 20*time.Minute, provider.DataAge(), float64(time.Minute))
+}
+
+func TestProvider_StaleIfError_RejectsTooOldCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "outages.http-cache")
+	entry := &httpcache.Entry{ETag: `"abc"`, Body: []byte(validBody), FetchedAt: time.Now().Add(-2 * time.Hour)}
+	require.NoError(t, httpcache.SaveEntry(cacheFile, entry))
+	server := makeServer(t, 502, "bad gateway")
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil).WithCacheFile(cacheFile).WithStaleIfError(time.Hour)
+	_, err := provider.FetchOutages(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2h0m0s old")
+	assert.Contains(t, err.Error(), "502")
+}
+
+func TestProvider_StaleIfError_DisabledByDefault(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "outages.http-cache")
+	require.NoError(t, httpcache.Save(cacheFile, `"abc"`, []byte(validBody)))
+	server := makeServer(t, 502, "bad gateway")
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil).WithCacheFile(cacheFile)
+	_, err := provider.FetchOutages(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
+
+func TestProvider_ReadOnlyCache_DoesNotSaveResponses(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "outages.http-cache")
+	server := makeServer(t, 200, validBody)
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil).WithCacheFile(cacheFile).WithReadOnlyCache()
+	result, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Zero(t, provider.DataAge())
+	assert.NoFileExists(t, cacheFile)
+}
+
+func TestProvider_304_MigratesLegacyCacheFile(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "outages.http-cache")
+	require.NoError(t, os.WriteFile(cacheFile, []byte(`"abc"`+"\n"+validBody), 0o644))
+	server := makeServer(t, http.StatusNotModified, "")
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil).WithCacheFile(cacheFile)
+	result, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+
+	data, err := os.ReadFile(cacheFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"version":2`)
+	assert.Equal(t, `"abc"`, httpcache.Load(cacheFile).ETag)
+}
+
+func TestProvider_FreshCache_SkipsRequest(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "outages.http-cache")
+	entry := &httpcache.Entry{ETag: `"abc"`, Body: []byte(validBody), FetchedAt: time.Now(), MaxAge: time.Minute}
+	require.NoError(t, httpcache.SaveEntry(cacheFile, entry))
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil).WithCacheFile(cacheFile)
+	result, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, 0, calls)
+}
+
+func pagedBody(id int, street, next string) string {
+	body := fmt.Sprintf(`{"hydra:member":[{"id":%d,"dateEvent":"2024-01-01T08:00:00+00:00","datePlanIn":"2024-01-01T16:00:00+00:00","koment":"test","buildingNames":"10","street":{"id":%d,"name":"%s"}}]`, id, id, street)
+	if next != "" {
+		body += fmt.Sprintf(`,"hydra:view":{"@id":"x","hydra:next":"%s"}`, next)
+	}
+	return body + "}"
+}
+
+func TestProvider_FollowsHydraNextLinks(t *testing.T) {
+	dir := t.TempDir()
+	cacheFile := filepath.Join(dir, "outages.http-cache")
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		conditional = append(conditional, page+":"+r.Header.Get("If-None-Match"))
+		etag := `"p` + page + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		switch page {
+		case "":
+			w.Write([]byte(pagedBody(1, "Стрийська", "/api?pagination=true&page=2")))
+		case "2":
+			w.Write([]byte(pagedBody(2, "Городоцька", "/api?pagination=true&page=3")))
+		default:
+			w.Write([]byte(pagedBody(3, "Личаківська", "")))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.URL+"/api?pagination=true", fixedClock(), nil).WithCacheFile(cacheFile)
+	result, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, []string{"Стрийська", "Городоцька", "Личаківська"}, []string{result[0].StreetName, result[1].StreetName, result[2].StreetName})
+	assert.Equal(t, `"p2"`, httpcache.Load(filepath.Join(dir, "outages-page2.http-cache")).ETag)
+	assert.Equal(t, `"p3"`, httpcache.Load(filepath.Join(dir, "outages-page3.http-cache")).ETag)
+
+	result, err = provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result, 3)
+	assert.Equal(t, []string{":", "2:", "3:", `:"p"`, `2:"p2"`, `3:"p3"`}, conditional)
+}
+
+func TestProvider_PaginationCapStopsLoops(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(pagedBody(calls, "Стрийська", r.URL.Path)))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.URL+"/api", fixedClock(), nil)
+	_, err := provider.FetchOutages(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than 100 pages")
+	assert.Equal(t, maxPages, calls)
+}
+
+func TestProvider_FailedPageFailsFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(pagedBody(1, "Стрийська", "?page=2")))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil)
+	result, err := provider.FetchOutages(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "page 2")
+	assert.Contains(t, err.Error(), "502")
+	assert.Nil(t, result)
+}
+
+func TestProvider_ArchivesEachPageSeparately(t *testing.T) {
+	archiveDir := filepath.Join(t.TempDir(), httpcache.ArchiveDirName)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(pagedBody(2, "Городоцька", "")))
+			return
+		}
+		w.Write([]byte(pagedBody(1, "Стрийська", "?page=2")))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil).
+		WithArchive(httpcache.NewArchive(archiveDir, "outages", httpcache.Retention{}))
+	_, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+
+	archived, err := httpcache.ListArchive(archiveDir)
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	assert.ElementsMatch(t, []string{"outages", "outages-page2"}, []string{archived[0].Source, archived[1].Source})
+}
+
+func TestProvider_Diagnostics_ReportsDroppedAndCorrectedRows(t *testing.T) {
+	body := `{"hydra:member":[` +
+		`{"id":1,"dateEvent":"2024-01-01T08:00:00+00:00","datePlanIn":"2024-01-01T16:00:00+00:00","buildingNames":"10","street":{"id":1,"name":"S"}},` +
+		`{"id":"x","dateEvent":5},` +
+		`{"id":3,"dateEvent":"","datePlanIn":"tomorrow","buildingNames":"10","street":"Стрийська"}]}`
+	server := makeServer(t, 200, body)
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil)
+	result, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+
+	diagnostics := provider.Diagnostics()
+	require.Len(t, diagnostics, 4)
+	assert.Equal(t, outage.Diagnostic{Source: server.URL, Row: 2, Field: "row", Reason: "undecodable row", Dropped: true,
+		Detail: diagnostics[0].Detail}, diagnostics[0])
+	assert.Equal(t, []string{"street: undecodable object", "dateEvent: missing date", "datePlanIn: invalid date"},
+		[]string{diagnostics[1].Kind(), diagnostics[2].Kind(), diagnostics[3].Kind()})
+	assert.Equal(t, 3, diagnostics[3].ID)
+	assert.Equal(t, 3, diagnostics[3].Row)
+	assert.Equal(t, `"tomorrow", used the fetch time`, diagnostics[3].Detail)
+
+}
+
+func TestProvider_Diagnostics_EmptyForCleanFeed(t *testing.T) {
+	server := makeServer(t, 200, validBody)
+	defer server.Close()
+
+	provider := NewProvider(server.URL, fixedClock(), nil)
+	_, err := provider.FetchOutages(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, provider.Diagnostics())
+}
+
+func TestParseResponse_ReturnsDiagnostics(t *testing.T) {
+	rows, diagnostics, err := ParseResponse([]byte(`{"hydra:member":[{"id":1,"buildingNames":"10"}]}`), "saved.json", time.Now())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Len(t, diagnostics, 2)
+	assert.Equal(t, "saved.json", diagnostics[0].Source)
+	assert.Equal(t, "dateEvent: missing date", diagnostics[0].Kind())
+}
@@ -1,15 +1,98 @@
 package notifier
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+)
+
+// Event identifies what a notification reports about an outage.
+type Event int
+
+const (
+	// EventOutage announces an outage affecting the user's address.
+	EventOutage Event = iota
+	// EventRestored reports that an outage the user was notified about is gone from the feed.
+	EventRestored
+	// EventUpdate reports that an outage the user was notified about changed its end time or comment.
+	EventUpdate
+	// EventReminder reminds that an outage the user was notified about is about to start.
+	EventReminder
+	// EventOverdue reports that an outage the user was notified about is still listed past its planned end.
+	EventOverdue
+	// EventCatchUp sums up in one message the notifications about an address held back during quiet hours.
+	EventCatchUp
+)
+
+var eventNames = map[Event]string{
+	EventOutage:   "outage",
+	EventRestored: "restored",
+	EventUpdate:   "update",
+	EventReminder: "reminder",
+	EventOverdue:  "overdue",
+	EventCatchUp:  "catch-up",
+}
+
+// String returns the event's stable name, as stored in the outbox.
+func (e Event) String() string {
+	if name, ok := eventNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("event(%d)", int(e))
+}
+
+// ParseEvent parses an event name produced by String.
+func ParseEvent(name string) (Event, bool) {
+	for e, n := range eventNames {
+		if n == name {
+			return e, true
+		}
+	}
+	return 0, false
+}
 
 // Content carries the structured data needed to render an outage notification.
 type Content struct {
-	City       string
-	StreetName string
-	Buildings  []string
-	Start      time.Time
-	End        time.Time
-	Comment    string
+	Event Event
+	// SavedAddress is the user's watched address the notification is about.
+	SavedAddress users.Address
+	City         string
+	StreetName   string
+	Buildings    []string
+	Start        time.Time
+	End          time.Time
+	Comment      string
+	Kind         outage.Kind
+	// PreviousEnd and PreviousComment hold what the user was last told; set for EventUpdate only.
+	PreviousEnd     time.Time
+	PreviousComment string
+	// Silent asks the sender to deliver without sound, as during quiet hours.
+	Silent bool
+	// Items lists the held notifications, in order; set for EventCatchUp only, which carries
+	// no outage of its own.
+	Items []Content
+}
+
+// catchUpContent merges the notifications about the saved address held back during quiet hours.
+func catchUpContent(saved users.Address, items []Content) Content {
+	return Content{Event: EventCatchUp, SavedAddress: saved, Items: items}
+}
+
+// newContent builds the notification content for an event about o affecting the saved address.
+func newContent(event Event, saved users.Address, o *outage.Outage) Content {
+	return Content{
+		Event:        event,
+		SavedAddress: saved,
+		City:         o.Address.City,
+		StreetName:   o.Address.StreetName,
+		Buildings:    o.Address.Buildings,
+		Start:        o.Period.StartDate,
+		End:          o.Period.EndDate,
+		Comment:      o.Description.Value,
+		Kind:         o.Kind,
+	}
 }
 
 // Sender sends notifications to users.
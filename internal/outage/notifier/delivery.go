@@ -0,0 +1,243 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+)
+
+// maxRateLimitRetries caps how many times a throttled message is resent before it counts as failed.
+const maxRateLimitRetries = 3
+
+// DefaultMaxSendAttempts is how many failed deliveries a message gets before it is given up on.
+const DefaultMaxSendAttempts = 5
+
+// A failed message is retried after retryBackoff, doubled with every further failure up to maxRetryBackoff.
+const (
+	retryBackoff    = time.Minute
+	maxRetryBackoff = time.Hour
+)
+
+// DeliveryLimits bounds how notifications are sent out. The zero value sends
+// from a single worker without pacing.
+type DeliveryLimits struct {
+	// Workers is the number of users notified concurrently. Messages to one user are always sent in order.
+	Workers int
+	// Interval is the minimum gap between any two messages.
+	Interval time.Duration
+	// PerChatInterval is the minimum gap between two messages to the same chat.
+	PerChatInterval time.Duration
+}
+
+// delivery is the queued messages of one user, in order, and the outcome of sending them.
+type delivery struct {
+	userID   int64
+	messages []Message
+	// errs holds the result of each attempted send, in order; messages past its end were not attempted.
+	errs []error
+}
+
+// enqueue plans the notifications for every user, queues them in the outbox and then saves
+// the users' state as if the messages were delivered. Once queued, a message is delivered
+// by this or a later run even if the process stops before sending it.
+func (n *NotifyUsers) enqueue(all []*users.User, current, resolved []*outage.Outage, now time.Time) error {
+	var messages []Message
+	var changed []*users.User
+	for _, user := range all {
+		updated, planned := n.plan(user, current, resolved, now)
+		messages = append(messages, planned...)
+		if updated != user {
+			changed = append(changed, updated)
+		}
+	}
+
+	if err := n.outbox.Add(messages); err != nil {
+		return fmt.Errorf("failed to queue notifications: %w", err)
+	}
+	for _, user := range changed {
+		if err := n.userRepo.Save(user); err != nil {
+			n.logger.Printf("failed to save user %d: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// plan lists one message per distinct outage event of every saved address and returns the user
+// state once they are delivered. During quiet hours messages are marked silent or held back until
+// the window ends, depending on the user's choice. Held messages about an address are sent as
+// one catch-up message once the window is over.
+func (n *NotifyUsers) plan(user *users.User, current, resolved []*outage.Outage, now time.Time) (*users.User, []Message) {
+	quiet := user.InQuietHours(now)
+	deferred := user.DefersNotifications(now)
+	updated := user
+	var messages []Message
+	for i := range user.Addresses {
+		var pending []notification
+		updated, pending = nextNotifications(updated, i, current, resolved, now, n.remindBefore)
+		if deferred {
+			if len(pending) > 0 && !updated.Addresses[i].CatchUp {
+				updated = updated.WithCatchUp(i, true)
+			}
+			continue
+		}
+		if updated.Addresses[i].CatchUp && len(pending) > 1 {
+			items := make([]Content, len(pending))
+			for k, p := range pending {
+				items[k] = p.content
+				updated = p.apply(updated)
+			}
+			content := catchUpContent(user.Addresses[i].Address, items)
+			content.Silent = quiet
+			messages = append(messages, newMessage(user.ID, content))
+			pending = nil
+		}
+		for _, p := range pending {
+			p.content.Silent = quiet
+			messages = append(messages, newMessage(user.ID, p.content))
+			updated = p.apply(updated)
+		}
+		if updated.Addresses[i].CatchUp {
+			updated = updated.WithCatchUp(i, false)
+		}
+	}
+	return updated, messages
+}
+
+// dueMessages picks the queued messages that may be sent at now. A user's messages are sent in
+// the order they were queued, so those following a message waiting for a retry wait as well.
+func dueMessages(queued []Message, now time.Time) []Message {
+	var due []Message
+	waiting := make(map[int64]bool)
+	for _, m := range queued {
+		if waiting[m.UserID] || !m.due(now) {
+			waiting[m.UserID] = true
+			continue
+		}
+		due = append(due, m)
+	}
+	return due
+}
+
+// drain delivers the messages from a pool of workers and settles the results one user at a time.
+func (n *NotifyUsers) drain(ctx context.Context, queued []Message, now time.Time) {
+	var deliveries []*delivery
+	byUser := make(map[int64]*delivery)
+	for _, m := range queued {
+		d := byUser[m.UserID]
+		if d == nil {
+			d = &delivery{userID: m.UserID}
+			byUser[m.UserID] = d
+			deliveries = append(deliveries, d)
+		}
+		d.messages = append(d.messages, m)
+	}
+
+	jobs := make(chan *delivery)
+	results := make(chan *delivery)
+	var wg sync.WaitGroup
+	for range min(max(n.limits.Workers, 1), len(deliveries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range jobs {
+				n.deliver(ctx, d)
+				results <- d
+			}
+		}()
+	}
+	go func() {
+		for _, d := range deliveries {
+			jobs <- d
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	for d := range results {
+		n.finish(d, now)
+	}
+}
+
+// deliver sends the user's messages in order, stopping at the first failure so that the
+// messages after it stay queued behind it and never reach the chat ahead of it.
+func (n *NotifyUsers) deliver(ctx context.Context, d *delivery) {
+	for _, m := range d.messages {
+		err := n.send(ctx, d.userID, m.Content)
+		d.errs = append(d.errs, err)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// send delivers a single message within the rate limits, resending it when it is throttled.
+func (n *NotifyUsers) send(ctx context.Context, chatID int64, content Content) error {
+	for attempt := 0; ; attempt++ {
+		if err := sleep(ctx, n.limiter.reserve(chatID)); err != nil {
+			return err
+		}
+		err := n.sender.Send(chatID, content)
+		var throttled *RetryAfterError
+		if !errors.As(err, &throttled) || attempt == maxRateLimitRetries {
+			return err
+		}
+		n.logger.Printf("sending to user %d throttled; retrying in %s", chatID, throttled.After)
+		n.limiter.pause(throttled.After)
+	}
+}
+
+// finish takes delivered messages off the outbox. A user who can no longer be reached is removed
+// together with all their queued messages. Other failures stay queued and are retried with backoff
+// by later runs until the attempt limit is reached.
+func (n *NotifyUsers) finish(d *delivery, now time.Time) {
+	for k, err := range d.errs {
+		if errors.Is(err, ErrRecipientUnavailable) {
+			if _, rmErr := n.userRepo.Remove(d.userID); rmErr != nil {
+				n.logger.Printf("failed to remove blocked user %d: %v", d.userID, rmErr)
+			}
+			for _, m := range d.messages {
+				n.done(m)
+			}
+			return
+		}
+		m := d.messages[k]
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			// Interrupted before the message could be sent; that is not an attempt.
+			continue
+		}
+		if err != nil {
+			n.retryLater(m, err, now)
+			continue
+		}
+		n.done(m)
+	}
+}
+
+// retryLater records a failed delivery of m and schedules the next attempt, or gives up on m
+// once it has failed maxSendAttempts times.
+func (n *NotifyUsers) retryLater(m Message, err error, now time.Time) {
+	attempts := m.Attempts + 1
+	if attempts >= n.maxSendAttempts {
+		n.logger.Printf("giving up on %s notification to user %d after %d attempts: %v", m.Content.Event, m.UserID, attempts, err)
+		n.done(m)
+		return
+	}
+	backoff := min(retryBackoff<<(attempts-1), maxRetryBackoff)
+	n.logger.Printf("failed to send %s notification to user %d (attempt %d of %d), retrying in %s: %v",
+		m.Content.Event, m.UserID, attempts, n.maxSendAttempts, backoff, err)
+	if err := n.outbox.Postpone(m.Key, attempts, now.Add(backoff)); err != nil {
+		n.logger.Printf("failed to postpone notification %s: %v", m.Key, err)
+	}
+}
+
+func (n *NotifyUsers) done(m Message) {
+	if err := n.outbox.Done(m.Key); err != nil {
+		n.logger.Printf("failed to remove notification %s from the outbox: %v", m.Key, err)
+	}
+}
@@ -1,6 +1,24 @@
 package notifier
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // ErrRecipientUnavailable indicates the recipient can no longer receive messages.
 var ErrRecipientUnavailable = errors.New("recipient unavailable")
+
+// RetryAfterError indicates the send was throttled and may be retried after the given delay.
+type RetryAfterError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s: %v", e.After, e.Err)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
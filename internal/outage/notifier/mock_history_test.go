@@ -0,0 +1,16 @@
+package notifier
+
+import (
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"time"
+)
+
+type mockHistory struct {
+	recorded []outage.Changes
+	err      error
+}
+
+func (m *mockHistory) Record(_ time.Time, changes outage.Changes) error {
+	m.recorded = append(m.recorded, changes)
+	return m.err
+}
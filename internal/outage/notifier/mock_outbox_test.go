@@ -0,0 +1,18 @@
+package notifier
+
+import "errors"
+
+// mockOutbox wraps the in-memory outbox with injectable failures.
+type mockOutbox struct {
+	memoryOutbox
+	addErr error
+}
+
+func (m *mockOutbox) Add(messages []Message) error {
+	if m.addErr != nil {
+		return m.addErr
+	}
+	return m.memoryOutbox.Add(messages)
+}
+
+var errOutboxFailed = errors.New("outbox failed")
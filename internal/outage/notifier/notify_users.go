@@ -2,11 +2,12 @@ package notifier
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/sl4wa/outages-bot/internal/outage/outage"
 	"github.com/sl4wa/outages-bot/internal/outage/users"
 	"log"
+	"slices"
+	"time"
 )
 
 // NotifyUsers fetches outages and sends notifications to all affected users.
@@ -16,6 +17,15 @@ type NotifyUsers struct {
 	userRepo     UserRepository
 	outageRepo   outage.SnapshotStore
 	logger       *log.Logger
+	clock        func() time.Time
+	zone         *time.Location
+	remindBefore time.Duration
+	history      outage.HistoryStore
+	limits       DeliveryLimits
+	limiter      *rateLimiter
+	outbox       Outbox
+	// maxSendAttempts is how many failed deliveries a message gets before it is dropped.
+	maxSendAttempts int
 }
 
 // UserRepository provides the user persistence operations required by notifications.
@@ -36,9 +46,56 @@ func NewNotifyUsers(fetchService *outage.FetchOutages, sender Sender, userRepo U
 		userRepo:     userRepo,
 		outageRepo:   outageRepo,
 		logger:       logger,
+		clock:        time.Now,
+		zone:         time.Local,
+		limiter:      newRateLimiter(0, 0),
+		outbox:       &memoryOutbox{},
+
+		maxSendAttempts: DefaultMaxSendAttempts,
 	}
 }
 
+// WithClock sets the clock and the time zone used to evaluate users' quiet hours.
+func (n *NotifyUsers) WithClock(clock func() time.Time, zone *time.Location) *NotifyUsers {
+	n.clock = clock
+	n.zone = zone
+	return n
+}
+
+// WithReminder enables reminders sent the given lead time before a notified outage starts.
+// A zero lead time disables reminders.
+func (n *NotifyUsers) WithReminder(before time.Duration) *NotifyUsers {
+	n.remindBefore = before
+	return n
+}
+
+// WithHistory records every fetch's outage changes in the given archive.
+func (n *NotifyUsers) WithHistory(history outage.HistoryStore) *NotifyUsers {
+	n.history = history
+	return n
+}
+
+// WithDelivery sets how many users are notified concurrently and the rate limits all messages must obey.
+func (n *NotifyUsers) WithDelivery(limits DeliveryLimits) *NotifyUsers {
+	n.limits = limits
+	n.limiter = newRateLimiter(limits.Interval, limits.PerChatInterval)
+	return n
+}
+
+// WithOutbox keeps queued notifications in the given outbox instead of in memory,
+// so that they survive the process.
+func (n *NotifyUsers) WithOutbox(outbox Outbox) *NotifyUsers {
+	n.outbox = outbox
+	return n
+}
+
+// WithMaxSendAttempts sets how many failed deliveries a message gets before it is given up on.
+// Values below one are treated as one.
+func (n *NotifyUsers) WithMaxSendAttempts(attempts int) *NotifyUsers {
+	n.maxSendAttempts = max(attempts, 1)
+	return n
+}
+
 // Handle fetches outages and sends notifications to all affected users.
 func (n *NotifyUsers) Handle(ctx context.Context) error {
 	outages, err := n.fetchService.Handle(ctx)
@@ -51,53 +108,175 @@ func (n *NotifyUsers) Handle(ctx context.Context) error {
 		return fmt.Errorf("failed to load outage data: %w", err)
 	}
 
-	if prev != nil && outage.OutagesEqual(prev, outages) {
-		n.logger.Printf("Outage data unchanged; checker/notifier logic skipped.")
+	now := n.clock().In(n.zone)
+	changes := outage.Diff(prev, outages)
+	if prev != nil && changes.Empty() {
+		n.recordHistory(now, changes)
+		due := n.dueUsers(outages, now)
+		if len(due) > 0 {
+			n.logger.Printf("Outage data unchanged; delivering held notifications, reminders and overdue alerts to %d user(s).", len(due))
+			if err := n.enqueue(due, outages, nil, now); err != nil {
+				return err
+			}
+		}
+		queued, err := n.outbox.Pending()
+		if err != nil {
+			return fmt.Errorf("failed to read notification outbox: %w", err)
+		}
+		ready := dueMessages(queued, now)
+		if len(ready) == 0 {
+			if len(due) == 0 {
+				n.logger.Printf("Outage data unchanged; checker/notifier logic skipped.")
+			}
+			return nil
+		}
+		if len(due) == 0 {
+			n.logger.Printf("Outage data unchanged; delivering %d queued notification(s).", len(ready))
+		}
+		n.drain(ctx, ready, now)
 		return nil
 	}
 
 	if prev == nil {
 		n.logger.Printf("No prior outage data found; saving and continuing.")
 	} else {
-		n.logger.Printf("Outage data changed; saving and continuing.")
+		n.logger.Printf("Outage data changed (%d new, %d changed, %d resolved); saving and continuing.",
+			len(changes.New), len(changes.Changed), len(changes.Resolved))
 	}
 
+	// Notifications are queued before the snapshot is committed: should the run stop midway,
+	// the next one still finds them in the outbox even though the data no longer looks changed.
+	if err := n.enqueue(n.userRepo.FindAll(), outages, changes.Resolved, now); err != nil {
+		return err
+	}
 	if err := n.outageRepo.Save(outages); err != nil {
 		return fmt.Errorf("failed to save outage data: %w", err)
 	}
+	// Recorded only once the snapshot is committed: a run failing before that diffs against
+	// the same snapshot again next time and would otherwise archive the same events twice.
+	n.recordHistory(now, changes)
+
+	queued, err := n.outbox.Pending()
+	if err != nil {
+		return fmt.Errorf("failed to read notification outbox: %w", err)
+	}
+	n.drain(ctx, dueMessages(queued, now), now)
+	return nil
+}
 
-	users := n.userRepo.FindAll()
+// recordHistory archives the changes found by the fetch made at now. The archive is
+// best-effort: failing to record it must not hold back notifications.
+func (n *NotifyUsers) recordHistory(now time.Time, changes outage.Changes) {
+	if n.history == nil {
+		return
+	}
+	if err := n.history.Record(now, changes); err != nil {
+		n.logger.Printf("failed to record outage history: %v", err)
+	}
+}
 
-	for _, user := range users {
-		outage := user.FindOutageForNotification(outages)
-		if outage == nil {
+// dueUsers returns users holding notifications from quiet hours that have now ended
+// and users with a pre-start reminder or an overdue alert due for one of the current outages.
+// Users whose notifications are held back right now are left for the end of their quiet hours.
+func (n *NotifyUsers) dueUsers(current []*outage.Outage, now time.Time) []*users.User {
+	var due []*users.User
+	for _, user := range n.userRepo.FindAll() {
+		if user.DefersNotifications(now) {
 			continue
 		}
-
-		content := Content{
-			City:       outage.Address.City,
-			StreetName: outage.Address.StreetName,
-			Buildings:  outage.Address.Buildings,
-			Start:      outage.Period.StartDate,
-			End:        outage.Period.EndDate,
-			Comment:    outage.Description.Value,
+		if (user.HasCatchUp() && !user.InQuietHours(now)) || user.HasReminderDue(current, now, n.remindBefore) || user.HasOverdueAlertDue(current, now) {
+			due = append(due, user)
 		}
+	}
+	return due
+}
+
+// notification is a message about one saved address together with the change it brings
+// to the user's state.
+type notification struct {
+	content Content
+	apply   func(user *users.User) *users.User
+}
 
-		if err := n.sender.Send(user.ID, content); err != nil {
-			if errors.Is(err, ErrRecipientUnavailable) {
-				if _, rmErr := n.userRepo.Remove(user.ID); rmErr != nil {
-					n.logger.Printf("failed to remove blocked user %d: %v", user.ID, rmErr)
-				}
+// nextNotifications lists what to tell the user about the saved address at index i: every
+// outage they have not been notified about yet, revisions of outages they were notified about,
+// notified outages that are gone, due pre-start reminders and overdue-restoration alerts.
+// Gone outages are reported as restored only once no other outage affects the address; until
+// then they are dropped from the user's state silently, which is returned along with the
+// notifications.
+func nextNotifications(user *users.User, i int, current, resolved []*outage.Outage, now time.Time, remindBefore time.Duration) (*users.User, []notification) {
+	saved := user.Addresses[i]
+	var pending []notification
+
+	affecting := user.AffectingOutages(i, current)
+	announced := user.Unnotified(i, affecting)
+	for _, o := range announced {
+		content := newContent(EventOutage, saved.Address, o)
+		if info := user.NotifiedAbout(i, o); info != nil {
+			content.Event = EventUpdate
+			content.PreviousEnd = info.Period.EndDate
+			content.PreviousComment = info.Description.Value
+		}
+		pending = append(pending, notification{content: content, apply: func(u *users.User) *users.User {
+			u = u.WithNotifiedOutage(i, o)
+			// An outage announced within the reminder lead time needs no separate reminder.
+			if u.ReminderDue(i, o, now, remindBefore) {
+				u = u.WithReminded(i, o)
+			}
+			// Likewise an outage announced past its planned end shows the delay already.
+			if u.OverdueAlertDue(i, o, now) {
+				u = u.WithOverdueAlerted(i, o)
 			}
-			// Non-blocking errors: user NOT removed, NOT saved, continue
+			return u
+		}})
+	}
+
+	stillOut := user.Affected(i, current)
+	for _, info := range user.GoneOutages(i, current) {
+		if stillOut {
+			user = user.WithoutNotifiedOutage(i, info)
 			continue
 		}
+		pending = append(pending, notification{content: restoredContent(saved.Address, info, resolved), apply: func(u *users.User) *users.User {
+			return u.WithoutNotifiedOutage(i, info)
+		}})
+	}
 
-		updatedUser := user.WithNotifiedOutage(outage)
-		if err := n.userRepo.Save(updatedUser); err != nil {
-			n.logger.Printf("failed to save user %d: %v", user.ID, err)
+	for _, o := range affecting {
+		if slices.Contains(announced, o) {
+			continue
+		}
+		if user.ReminderDue(i, o, now, remindBefore) {
+			pending = append(pending, notification{content: newContent(EventReminder, saved.Address, o), apply: func(u *users.User) *users.User {
+				return u.WithReminded(i, o)
+			}})
+		}
+		if user.OverdueAlertDue(i, o, now) {
+			pending = append(pending, notification{content: newContent(EventOverdue, saved.Address, o), apply: func(u *users.User) *users.User {
+				return u.WithOverdueAlerted(i, o)
+			}})
 		}
 	}
 
-	return nil
+	return user, pending
+}
+
+// restoredContent reports that the notified outage is gone. The resolved version from the feed
+// is used when available; an outage that vanished while notifications were held back is
+// described from what the user was told.
+func restoredContent(saved users.Address, info users.OutageInfo, resolved []*outage.Outage) Content {
+	for _, o := range resolved {
+		if saved.AffectedBy(o) && info.SameOutage(o) {
+			return newContent(EventRestored, saved, o)
+		}
+	}
+	return Content{
+		Event:        EventRestored,
+		SavedAddress: saved,
+		City:         saved.City,
+		StreetName:   saved.StreetName,
+		Start:        info.Period.StartDate,
+		End:          info.Period.EndDate,
+		Comment:      info.Description.Value,
+	}
 }
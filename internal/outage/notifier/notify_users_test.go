@@ -8,6 +8,8 @@ import (
 	"github.com/sl4wa/outages-bot/internal/outage/users"
 	"io"
 	"log"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -21,12 +23,22 @@ type sentNotification struct {
 }
 
 type mockSender struct {
+	mu   sync.Mutex
 	sent []sentNotification
 	err  error
+	// errs, when set, are returned by consecutive sends before falling back to err.
+	errs []error
 }
 
 func (m *mockSender) Send(userID int64, content Content) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.sent = append(m.sent, sentNotification{UserID: userID, Content: content})
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		m.errs = m.errs[1:]
+		return err
+	}
 	if m.err != nil {
 		return m.err
 	}
@@ -64,7 +76,7 @@ func TestNotifyUsers_MatchingSendsAndSaves(t *testing.T) {
 	sender := &mockSender{}
 	repo := newMockUserRepo()
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10", "12"})}}
 	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
@@ -82,7 +94,7 @@ func TestNotifyUsers_BlockedUserRemoved(t *testing.T) {
 	}
 	repo := newMockUserRepo()
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
 	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
@@ -96,7 +108,7 @@ func TestNotifyUsers_NonMatchingDoesNothing(t *testing.T) {
 	sender := &mockSender{}
 	repo := newMockUserRepo()
 	addr, _ := users.NewAddress(2, "Наукова", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
 	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
@@ -111,7 +123,7 @@ func TestNotifyUsers_DedupSecondRunSkips(t *testing.T) {
 	sender := &mockSender{}
 	repo := newMockUserRepo()
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
 	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
@@ -134,15 +146,148 @@ func TestNotifyUsers_NonBlockingSendError_UserNotRemoved(t *testing.T) {
 	}
 	repo := newMockUserRepo()
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
 	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
-	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0)).WithClock(clock.Now, time.UTC)
 
 	err := svc.Handle(context.Background())
 	require.NoError(t, err)
 	assert.Empty(t, repo.removed)
-	assert.Empty(t, repo.saved) // Not saved because send failed
+	queued := queuedMessages(t, svc)
+	require.Len(t, queued, 1, "kept queued because send failed")
+	assert.Equal(t, 1, queued[0].Attempts)
+	assert.Equal(t, clock.now.Add(time.Minute), queued[0].RetryAt)
+
+	// The next run delivers it once the backoff has elapsed, although the outage data did not change.
+	sender.err = nil
+	clock.now = clock.now.Add(time.Minute)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 2)
+	assert.Empty(t, queuedMessages(t, svc))
+}
+
+func TestNotifyUsers_FailedSend_NotRetriedBeforeBackoff(t *testing.T) {
+	sender := &mockSender{err: errors.New("send failed")}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	var buf bytes.Buffer
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(&buf, "", 0)).WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	sender.sent = nil
+	buf.Reset()
+
+	clock.now = clock.now.Add(30 * time.Second)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+	assert.Contains(t, buf.String(), "checker/notifier logic skipped")
+	assert.Equal(t, 1, queuedMessages(t, svc)[0].Attempts)
+}
+
+func TestNotifyUsers_FailedSend_BackoffDoubles(t *testing.T) {
+	sender := &mockSender{err: errors.New("send failed")}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0)).WithClock(clock.Now, time.UTC)
+
+	for _, backoff := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		require.NoError(t, svc.Handle(context.Background()))
+		queued := queuedMessages(t, svc)
+		require.Len(t, queued, 1)
+		assert.Equal(t, clock.now.Add(backoff), queued[0].RetryAt)
+		clock.now = queued[0].RetryAt
+	}
+}
+
+func TestNotifyUsers_FailedSend_GivesUpAfterMaxAttempts(t *testing.T) {
+	sender := &mockSender{err: errors.New("send failed")}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	var buf bytes.Buffer
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(&buf, "", 0)).
+		WithClock(clock.Now, time.UTC).
+		WithMaxSendAttempts(2)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, queuedMessages(t, svc), 1)
+
+	clock.now = clock.now.Add(time.Hour)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 2)
+	assert.Empty(t, queuedMessages(t, svc))
+	assert.Empty(t, repo.removed)
+	assert.Contains(t, buf.String(), "giving up on outage notification to user 100 after 2 attempts")
+
+	sender.sent = nil
+	clock.now = clock.now.Add(time.Hour)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+}
+
+func TestNotifyUsers_FailedSend_LaterMessagesOfUserWait(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0)).WithClock(clock.Now, time.UTC)
+
+	first := newMessage(100, Content{Event: EventOutage, Comment: "first"})
+	second := newMessage(100, Content{Event: EventOutage, Comment: "second"})
+	require.NoError(t, svc.outbox.Add([]Message{first, second}))
+	require.NoError(t, svc.outbox.Postpone(first.Key, 1, clock.now.Add(time.Minute)))
+
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent, "second waits behind the postponed first")
+
+	clock.now = clock.now.Add(time.Minute)
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, "first", sender.sent[0].Content.Comment)
+	assert.Equal(t, "second", sender.sent[1].Content.Comment)
+}
+
+func TestNotifyUsers_FailedSend_StopsUsersLaterMessagesInSameRun(t *testing.T) {
+	sender := &mockSender{errs: []error{errors.New("send failed")}}
+	repo := newMockUserRepo()
+	repo.users[100] = users.NewUser(100, users.Address{})
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	svc := newNotifyUsers(&mockProvider{}, sender, repo, log.New(io.Discard, "", 0)).WithClock(clock.Now, time.UTC)
+
+	outageMsg := newMessage(100, Content{Event: EventOutage, Comment: "outage"})
+	restored := newMessage(100, Content{Event: EventRestored, Comment: "restored"})
+	require.NoError(t, svc.outbox.Add([]Message{outageMsg, restored}))
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1, "the message after the failed one is not sent ahead of it")
+	queued := queuedMessages(t, svc)
+	require.Len(t, queued, 2)
+	assert.Equal(t, 1, queued[0].Attempts)
+	assert.Zero(t, queued[1].Attempts)
+
+	clock.now = clock.now.Add(time.Minute)
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 3)
+	assert.Equal(t, "outage", sender.sent[1].Content.Comment)
+	assert.Equal(t, "restored", sender.sent[2].Content.Comment)
+	assert.Empty(t, queuedMessages(t, svc))
 }
 
 func TestNotifyUsers_SaveError_LogsAndContinues(t *testing.T) {
@@ -150,7 +295,7 @@ func TestNotifyUsers_SaveError_LogsAndContinues(t *testing.T) {
 	repo := newMockUserRepo()
 	repo.saveErr = errors.New("disk full")
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	var buf bytes.Buffer
 	logger := log.New(&buf, "", 0)
@@ -170,7 +315,7 @@ func TestNotifyUsers_RemoveError_LogsAndContinues(t *testing.T) {
 	repo := newMockUserRepo()
 	repo.removeErr = errors.New("disk full")
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	var buf bytes.Buffer
 	logger := log.New(&buf, "", 0)
@@ -204,7 +349,7 @@ func TestNotifyUsers_Snapshot_FirstRun_SavesAndNotifies(t *testing.T) {
 	sender := &mockSender{}
 	repo := newMockUserRepo()
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	snap := &mockOutageRepo{} // no prior snapshot
 	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
@@ -220,7 +365,7 @@ func TestNotifyUsers_Snapshot_IdenticalSecondRun_SkipsNotification(t *testing.T)
 	sender := &mockSender{}
 	repo := newMockUserRepo()
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	outages := []outage.RawOutage{makeTestOutage(1, []string{"10"})}
 	provider := &mockProvider{outages: outages}
@@ -244,7 +389,7 @@ func TestNotifyUsers_Snapshot_ChangedSnapshot_SavesAndNotifies(t *testing.T) {
 	sender := &mockSender{}
 	repo := newMockUserRepo()
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	firstOutages := []outage.RawOutage{makeTestOutage(1, []string{"10"})}
 	provider := &mockProvider{outages: firstOutages}
@@ -262,7 +407,7 @@ func TestNotifyUsers_Snapshot_ChangedSnapshot_SavesAndNotifies(t *testing.T) {
 	provider.outages = secondOutages
 
 	// Reset user so it can be notified again
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 	sender.sent = nil
 
 	err = svc.Handle(context.Background())
@@ -274,7 +419,7 @@ func TestNotifyUsers_Snapshot_SaveFailure_AbortsBeforeNotifications(t *testing.T
 	sender := &mockSender{}
 	repo := newMockUserRepo()
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	repo.users[100] = &users.User{ID: 100, Address: addr}
+	repo.users[100] = users.NewUser(100, addr)
 
 	snap := &mockOutageRepo{saveErr: errSaveFailed}
 	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
@@ -381,3 +526,798 @@ func TestNotifyUsers_Snapshot_EmptyAfterNonEmpty_TreatedAsChange(t *testing.T) {
 	// Snapshot should be updated to empty
 	assert.Empty(t, snap.outages)
 }
+
+func TestNotifyUsers_Snapshot_ReorderedOutages_SkipsNotification(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	first := makeTestOutage(1, []string{"10"})
+	second := makeTestOutage(2, []string{"5"})
+	second.ID = 2
+	provider := &mockProvider{outages: []outage.RawOutage{first, second}}
+	snap := &mockOutageRepo{}
+
+	var buf bytes.Buffer
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, snap, log.New(&buf, "", 0))
+	require.NoError(t, svc.Handle(context.Background()))
+
+	provider.outages = []outage.RawOutage{second, first}
+	buf.Reset()
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Contains(t, buf.String(), "Outage data unchanged")
+}
+
+func TestNotifyUsers_ResolvedOutage_SendsRestoredAndClearsInfo(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	snap := &mockOutageRepo{}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, snap, log.New(io.Discard, "", 0))
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventOutage, sender.sent[0].Content.Event)
+	require.Len(t, repo.users[100].Addresses[0].Notified, 1)
+
+	provider.outages = nil
+	sender.sent = nil
+	require.NoError(t, svc.Handle(context.Background()))
+
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventRestored, sender.sent[0].Content.Event)
+	assert.Equal(t, "Стрийська", sender.sent[0].Content.StreetName)
+	assert.Empty(t, repo.users[100].Addresses[0].Notified, "outage info cleared after restore notice")
+}
+
+func TestNotifyUsers_ResolvedOutage_NotNotifiedUser_NothingSent(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	snap := &mockOutageRepo{}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, snap, log.New(io.Discard, "", 0))
+	require.NoError(t, svc.Handle(context.Background()))
+
+	// User subscribes after the outage was already announced to others.
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	provider.outages = nil
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+}
+
+func TestNotifyUsers_ResolvedOutage_ReplacedByNewOutage_SendsNewOutage(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	snap := &mockOutageRepo{}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, snap, log.New(io.Discard, "", 0))
+	require.NoError(t, svc.Handle(context.Background()))
+
+	next := makeTestOutage(1, []string{"10"})
+	next.ID = 2
+	next.Start = next.Start.Add(24 * time.Hour)
+	next.End = next.End.Add(24 * time.Hour)
+	provider.outages = []outage.RawOutage{next}
+	sender.sent = nil
+	require.NoError(t, svc.Handle(context.Background()))
+
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventOutage, sender.sent[0].Content.Event)
+	assert.Equal(t, 2, repo.users[100].Addresses[0].Notified[0].OutageID)
+}
+
+func TestNotifyUsers_NotifiedOutageExtended_SendsUpdate(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	original := makeTestOutage(1, []string{"10"})
+	provider := &mockProvider{outages: []outage.RawOutage{original}}
+	snap := &mockOutageRepo{}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, snap, log.New(io.Discard, "", 0))
+	require.NoError(t, svc.Handle(context.Background()))
+
+	extended := original
+	extended.End = original.End.Add(2 * time.Hour)
+	extended.Comment = "updated"
+	provider.outages = []outage.RawOutage{extended}
+	sender.sent = nil
+	require.NoError(t, svc.Handle(context.Background()))
+
+	require.Len(t, sender.sent, 1)
+	c := sender.sent[0].Content
+	assert.Equal(t, EventUpdate, c.Event)
+	assert.Equal(t, original.End, c.PreviousEnd)
+	assert.Equal(t, extended.End, c.End)
+	assert.Equal(t, "test", c.PreviousComment)
+	assert.Equal(t, "updated", c.Comment)
+	assert.Equal(t, extended.End.Unix(), repo.users[100].Addresses[0].Notified[0].Period.EndDate.Unix())
+}
+
+func TestNotifyUsers_DifferentOutageForNotifiedUser_SendsFullNotification(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0))
+	require.NoError(t, svc.Handle(context.Background()))
+
+	other := makeTestOutage(1, []string{"10"})
+	other.ID = 2
+	other.Comment = "other"
+	provider.outages = []outage.RawOutage{other}
+	sender.sent = nil
+	require.NoError(t, svc.Handle(context.Background()))
+
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventOutage, sender.sent[0].Content.Event)
+}
+
+func TestNotifyUsers_OverlappingOutages_NotifiesEachOnce(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	emergency := makeTestOutage(1, []string{"10"})
+	emergency.Comment = "Застосування ГАВ"
+	hourly := makeTestOutage(1, []string{"10", "12"})
+	hourly.ID = 2
+	hourly.Start = emergency.Start.Add(time.Hour)
+	hourly.Comment = "Застосування ГПВ"
+	duplicate := hourly
+	duplicate.ID = 3
+	duplicate.Buildings = []string{"10", "14"}
+	provider := &mockProvider{outages: []outage.RawOutage{emergency, hourly, duplicate}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0))
+	require.NoError(t, svc.Handle(context.Background()))
+
+	require.Len(t, sender.sent, 2, "identical outage listed twice is announced once")
+	assert.Equal(t, "Застосування ГАВ", sender.sent[0].Content.Comment)
+	assert.Equal(t, "Застосування ГПВ", sender.sent[1].Content.Comment)
+	require.Len(t, repo.users[100].Addresses[0].Notified, 2)
+
+	// The emergency outage ends while the hourly one goes on: nothing is restored yet.
+	provider.outages = []outage.RawOutage{hourly, duplicate}
+	sender.sent = nil
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+	require.Len(t, repo.users[100].Addresses[0].Notified, 1)
+
+	provider.outages = nil
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventRestored, sender.sent[0].Content.Event)
+	assert.Empty(t, repo.users[100].Addresses[0].Notified)
+}
+
+func TestNotifyUsers_MultipleAddresses_NotifiesEachAffectedAddress(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	home, _ := users.NewAddress(1, "Стрийська", "10")
+	office, _ := users.NewAddress(1, "Стрийська", "12")
+	parents, _ := users.NewAddress(1, "Стрийська", "14")
+	repo.users[100] = users.NewUser(100, home).WithAddress(office).WithAddress(parents)
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10", "12"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, home, sender.sent[0].Content.SavedAddress)
+	assert.Equal(t, office, sender.sent[1].Content.SavedAddress)
+
+	require.Len(t, repo.saved, 1, "user saved once after all addresses")
+	saved := repo.users[100]
+	assert.Len(t, saved.Addresses[0].Notified, 1)
+	assert.Len(t, saved.Addresses[1].Notified, 1)
+	assert.Empty(t, saved.Addresses[2].Notified)
+}
+
+func TestNotifyUsers_MultipleAddresses_BlockedUserStopsAfterFirstSend(t *testing.T) {
+	sender := &mockSender{err: ErrRecipientUnavailable}
+	repo := newMockUserRepo()
+	home, _ := users.NewAddress(1, "Стрийська", "10")
+	office, _ := users.NewAddress(1, "Стрийська", "12")
+	repo.users[100] = users.NewUser(100, home).WithAddress(office)
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10", "12"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
+
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 1)
+	assert.Equal(t, []int64{100}, repo.removed)
+	assert.Empty(t, queuedMessages(t, svc), "messages to a blocked user are dropped")
+}
+
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newQuietUser(t *testing.T, mode users.QuietMode) *users.User {
+	t.Helper()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	q, err := users.NewQuietHours(23, 7, mode)
+	require.NoError(t, err)
+	user := users.NewUser(100, addr)
+	user.QuietHours = &q
+	return user
+}
+
+func TestNotifyUsers_QuietHoursSilent_SendsWithoutSound(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	repo.users[100] = newQuietUser(t, users.QuietSilent)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0)).WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	assert.True(t, sender.sent[0].Content.Silent)
+}
+
+func TestNotifyUsers_OutsideQuietHours_SendsWithSound(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	repo.users[100] = newQuietUser(t, users.QuietSilent)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0)).WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	assert.False(t, sender.sent[0].Content.Silent)
+}
+
+func TestNotifyUsers_QuietHoursDefer_HoldsThenCatchesUp(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	repo.users[100] = newQuietUser(t, users.QuietDefer)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	snap := &mockOutageRepo{}
+	var buf bytes.Buffer
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, snap, log.New(&buf, "", 0)).WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent, "held during quiet hours")
+	assert.True(t, repo.users[100].Addresses[0].CatchUp)
+	assert.Empty(t, repo.users[100].Addresses[0].Notified)
+
+	// Still quiet, data unchanged: nothing happens.
+	clock.now = time.Date(2024, 1, 1, 5, 0, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+
+	// Window over, data unchanged: one catch-up is delivered.
+	clock.now = time.Date(2024, 1, 1, 7, 1, 0, 0, time.UTC)
+	buf.Reset()
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventOutage, sender.sent[0].Content.Event)
+	assert.False(t, sender.sent[0].Content.Silent)
+	assert.Contains(t, buf.String(), "delivering held notifications, reminders and overdue alerts")
+	assert.False(t, repo.users[100].Addresses[0].CatchUp)
+	assert.Len(t, repo.users[100].Addresses[0].Notified, 1)
+
+	// Nothing further held.
+	sender.sent = nil
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+}
+
+func TestNotifyUsers_QuietHoursDefer_MergesHeldNotificationsPerAddress(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	repo.users[100] = newQuietUser(t, users.QuietDefer)
+
+	// Notified about the first outage before quiet hours.
+	clock := &testClock{now: time.Date(2024, 1, 1, 21, 0, 0, 0, time.UTC)}
+	first := makeTestOutage(1, []string{"10"})
+	first.Start = time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	first.End = time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	provider := &mockProvider{outages: []outage.RawOutage{first}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0)).
+		WithClock(clock.Now, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	sender.sent = nil
+
+	// During the window its end moves and a second outage is announced.
+	clock.now = time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	revised := first
+	revised.End = time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	second := makeTestOutage(1, []string{"10"})
+	second.ID = 2
+	second.Start = time.Date(2024, 1, 2, 18, 0, 0, 0, time.UTC)
+	second.End = time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC)
+	provider.outages = []outage.RawOutage{revised, second}
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+
+	clock.now = time.Date(2024, 1, 2, 7, 1, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1, "held notifications arrive as one message")
+	content := sender.sent[0].Content
+	assert.Equal(t, EventCatchUp, content.Event)
+	assert.Equal(t, repo.users[100].Addresses[0].Address, content.SavedAddress)
+	require.Len(t, content.Items, 2)
+	assert.Equal(t, EventUpdate, content.Items[0].Event)
+	assert.Equal(t, EventOutage, content.Items[1].Event)
+	assert.Len(t, repo.users[100].Addresses[0].Notified, 2)
+	assert.False(t, repo.users[100].Addresses[0].CatchUp)
+
+	sender.sent = nil
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+}
+
+func TestNotifyUsers_QuietHoursDefer_OutageResolvedDuringWindow_SendsRestored(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	repo.users[100] = newQuietUser(t, users.QuietDefer)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0)).WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1, "notified before quiet hours")
+
+	clock.now = time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC)
+	provider.outages = nil
+	sender.sent = nil
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+
+	clock.now = time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventRestored, sender.sent[0].Content.Event)
+	assert.Empty(t, repo.users[100].Addresses[0].Notified)
+	assert.False(t, repo.users[100].Addresses[0].CatchUp)
+}
+
+func TestNotifyUsers_QuietHoursDefer_NewOutageGoneBeforeWindowEnds_NothingSent(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	repo.users[100] = newQuietUser(t, users.QuietDefer)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0)).WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	provider.outages = nil
+	require.NoError(t, svc.Handle(context.Background()))
+
+	clock.now = time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Empty(t, sender.sent)
+	assert.False(t, repo.users[100].Addresses[0].CatchUp)
+}
+
+func TestNotifyUsers_QuietHoursDefer_DueReminderNotDeliveredDuringWindow(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	repo.users[100] = newQuietUser(t, users.QuietDefer)
+
+	// Notified before quiet hours about an outage starting inside them.
+	clock := &testClock{now: time.Date(2024, 1, 1, 21, 0, 0, 0, time.UTC)}
+	o := makeTestOutage(1, []string{"10"})
+	o.Start = time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC)
+	o.End = time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	provider := &mockProvider{outages: []outage.RawOutage{o}}
+	var buf bytes.Buffer
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(&buf, "", 0)).
+		WithClock(clock.Now, time.UTC).
+		WithReminder(30 * time.Minute)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+
+	// The reminder is due but the window is on: two cycles pass without any delivery.
+	buf.Reset()
+	for _, at := range []time.Time{
+		time.Date(2024, 1, 2, 0, 35, 0, 0, time.UTC),
+		time.Date(2024, 1, 2, 0, 45, 0, 0, time.UTC),
+	} {
+		clock.now = at
+		require.NoError(t, svc.Handle(context.Background()))
+	}
+	assert.Len(t, sender.sent, 1)
+	assert.NotContains(t, buf.String(), "delivering held notifications")
+	assert.Equal(t, 2, strings.Count(buf.String(), "checker/notifier logic skipped"))
+}
+
+func TestNotifyUsers_Reminder_SentOnceBeforeStart(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	// The outage starts at 08:00.
+	clock := &testClock{now: time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0)).
+		WithClock(clock.Now, time.UTC).
+		WithReminder(30 * time.Minute)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventOutage, sender.sent[0].Content.Event)
+
+	clock.now = time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 1, "too early for a reminder")
+
+	clock.now = time.Date(2024, 1, 1, 7, 35, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, EventReminder, sender.sent[1].Content.Event)
+	assert.Equal(t, []string{"10"}, sender.sent[1].Content.Buildings)
+	assert.True(t, repo.users[100].Addresses[0].Notified[0].Reminded)
+
+	clock.now = time.Date(2024, 1, 1, 7, 45, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 2, "reminder sent only once")
+}
+
+func TestNotifyUsers_Reminder_NotSentWhenAnnouncedWithinLeadTime(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 7, 50, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0)).
+		WithClock(clock.Now, time.UTC).
+		WithReminder(30 * time.Minute)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventOutage, sender.sent[0].Content.Event)
+	assert.True(t, repo.users[100].Addresses[0].Notified[0].Reminded)
+}
+
+func TestNotifyUsers_Reminder_DisabledByDefault(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0)).
+		WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	clock.now = time.Date(2024, 1, 1, 7, 50, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 1)
+}
+
+func TestNotifyUsers_Overdue_AlertedOncePerPlannedEnd(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	// The outage is planned to end at 16:00.
+	clock := &testClock{now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	original := makeTestOutage(1, []string{"10"})
+	provider := &mockProvider{outages: []outage.RawOutage{original}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0)).
+		WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+
+	clock.now = time.Date(2024, 1, 1, 16, 10, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, EventOverdue, sender.sent[1].Content.Event)
+	assert.True(t, repo.users[100].Addresses[0].Notified[0].OverdueAlerted)
+
+	clock.now = time.Date(2024, 1, 1, 16, 40, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 2, "alerted only once for the same planned end")
+
+	// The planned end is pushed back; once it passes as well, the user is alerted again.
+	extended := original
+	extended.End = time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	provider.outages = []outage.RawOutage{extended}
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 3)
+	assert.Equal(t, EventUpdate, sender.sent[2].Content.Event)
+
+	clock.now = time.Date(2024, 1, 1, 18, 5, 0, 0, time.UTC)
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 4)
+	assert.Equal(t, EventOverdue, sender.sent[3].Content.Event)
+}
+
+func TestNotifyUsers_Overdue_NotAlertedWhenAnnouncedPastEnd(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	clock := &testClock{now: time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC)}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(io.Discard, "", 0)).
+		WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, EventOutage, sender.sent[0].Content.Event)
+
+	clock.now = clock.now.Add(10 * time.Minute)
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 1)
+}
+
+func TestNotifyUsers_Overdue_FilteredOutKindNotDue(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	var buf bytes.Buffer
+	clock := &testClock{now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	hourly := makeTestOutage(1, []string{"10"})
+	hourly.SubReason = 2
+	provider := &mockProvider{outages: []outage.RawOutage{hourly}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(&buf, "", 0)).
+		WithClock(clock.Now, time.UTC)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.Len(t, sender.sent, 1)
+
+	// The user stops following hourly outages after being notified about one that then runs late.
+	repo.users[100].Kinds = []outage.Kind{outage.KindEmergency}
+	buf.Reset()
+
+	for _, at := range []time.Time{
+		time.Date(2024, 1, 1, 16, 10, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 16, 20, 0, 0, time.UTC),
+	} {
+		clock.now = at
+		require.NoError(t, svc.Handle(context.Background()))
+	}
+	assert.Len(t, sender.sent, 1)
+	assert.NotContains(t, buf.String(), "delivering held notifications")
+	assert.Contains(t, buf.String(), "checker/notifier logic skipped")
+}
+
+func TestNotifyUsers_History_RecordsEveryFetch(t *testing.T) {
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	history := &mockHistory{}
+	svc := newNotifyUsersWithSnapshot(provider, &mockSender{}, newMockUserRepo(), &mockOutageRepo{}, log.New(io.Discard, "", 0)).
+		WithHistory(history)
+
+	require.NoError(t, svc.Handle(context.Background()))
+	require.NoError(t, svc.Handle(context.Background()))
+	provider.outages = nil
+	require.NoError(t, svc.Handle(context.Background()))
+
+	require.Len(t, history.recorded, 3)
+	assert.Len(t, history.recorded[0].New, 1)
+	assert.True(t, history.recorded[1].Empty(), "unchanged fetch still recorded as checked")
+	assert.Len(t, history.recorded[2].Resolved, 1)
+}
+
+func TestNotifyUsers_History_ErrorDoesNotBlockNotifications(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	var buf bytes.Buffer
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, &mockOutageRepo{}, log.New(&buf, "", 0)).
+		WithHistory(&mockHistory{err: errors.New("disk full")})
+
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 1)
+	assert.Contains(t, buf.String(), "failed to record outage history: disk full")
+}
+
+func TestNotifyUsers_Delivery_WorkersNotifyEveryUser(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	for id := int64(1); id <= 50; id++ {
+		repo.users[id] = users.NewUser(id, addr)
+	}
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0)).
+		WithDelivery(DeliveryLimits{Workers: 8})
+
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 50)
+	assert.Len(t, repo.saved, 50)
+	for id, user := range repo.users {
+		assert.Len(t, user.Addresses[0].Notified, 1, "user %d", id)
+	}
+}
+
+func TestNotifyUsers_Delivery_PacesMessages(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	for id := int64(1); id <= 3; id++ {
+		repo.users[id] = users.NewUser(id, addr)
+	}
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0)).
+		WithDelivery(DeliveryLimits{Workers: 3, Interval: 20 * time.Millisecond})
+
+	started := time.Now()
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 3)
+	assert.GreaterOrEqual(t, time.Since(started), 40*time.Millisecond)
+}
+
+func TestNotifyUsers_Delivery_RetriesThrottledSend(t *testing.T) {
+	throttled := &RetryAfterError{After: 10 * time.Millisecond, Err: errors.New("too many requests")}
+	sender := &mockSender{errs: []error{throttled}}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	var logBuf bytes.Buffer
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(&logBuf, "", 0))
+
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, 2, "resent after the throttling response")
+	assert.Len(t, repo.users[100].Addresses[0].Notified, 1)
+	assert.Contains(t, logBuf.String(), "sending to user 100 throttled; retrying in 10ms")
+}
+
+func TestNotifyUsers_Delivery_GivesUpAfterRepeatedThrottling(t *testing.T) {
+	sender := &mockSender{err: &RetryAfterError{After: time.Millisecond, Err: errors.New("too many requests")}}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
+
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, sender.sent, maxRateLimitRetries+1)
+	assert.Len(t, queuedMessages(t, svc), 1, "kept queued for the next run")
+	assert.Empty(t, repo.removed)
+}
+
+func TestNotifyUsers_Delivery_CanceledContextStopsSending(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, svc.Handle(ctx))
+	assert.Empty(t, sender.sent)
+	queued := queuedMessages(t, svc)
+	require.Len(t, queued, 1)
+	assert.Zero(t, queued[0].Attempts, "an interrupted send is not a failed attempt")
+}
+
+func queuedMessages(t *testing.T, svc *NotifyUsers) []Message {
+	t.Helper()
+	queued, err := svc.outbox.Pending()
+	require.NoError(t, err)
+	return queued
+}
+
+func TestNotifyUsers_Outbox_InterruptedRunDeliveredByNextOne(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+	repo.users[200] = users.NewUser(200, addr)
+
+	outbox := &memoryOutbox{}
+	snap := &mockOutageRepo{}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+
+	// The first run stops after committing the snapshot, before anything is sent.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	first := newNotifyUsersWithSnapshot(provider, sender, repo, snap, log.New(io.Discard, "", 0)).WithOutbox(outbox)
+	require.NoError(t, first.Handle(ctx))
+	require.NotNil(t, snap.saved)
+	require.Empty(t, sender.sent)
+
+	var logBuf bytes.Buffer
+	second := newNotifyUsersWithSnapshot(provider, sender, repo, snap, log.New(&logBuf, "", 0)).WithOutbox(outbox)
+	require.NoError(t, second.Handle(context.Background()))
+	assert.Contains(t, logBuf.String(), "Outage data unchanged; delivering 2 queued notification(s).")
+	sentIDs := []int64{sender.sent[0].UserID, sender.sent[1].UserID}
+	assert.ElementsMatch(t, []int64{100, 200}, sentIDs)
+	assert.Empty(t, outbox.messages)
+
+	require.NoError(t, second.Handle(context.Background()))
+	assert.Len(t, sender.sent, 2, "delivered once")
+}
+
+func TestNotifyUsers_Outbox_ReplannedMessageQueuedOnce(t *testing.T) {
+	sender := &mockSender{err: errors.New("send failed")}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	user := users.NewUser(100, addr)
+	repo.users[100] = user
+
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsers(provider, sender, repo, log.New(io.Discard, "", 0))
+	require.NoError(t, svc.Handle(context.Background()))
+
+	// The user file is rolled back, as if saving it was lost, so the outage is planned again.
+	repo.users[100] = user
+	svc.outageRepo = &mockOutageRepo{}
+	require.NoError(t, svc.Handle(context.Background()))
+	assert.Len(t, queuedMessages(t, svc), 1)
+}
+
+func TestNotifyUsers_Outbox_AddFailure_AbortsBeforeSnapshot(t *testing.T) {
+	sender := &mockSender{}
+	repo := newMockUserRepo()
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	repo.users[100] = users.NewUser(100, addr)
+
+	snap := &mockOutageRepo{}
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	svc := newNotifyUsersWithSnapshot(provider, sender, repo, snap, log.New(io.Discard, "", 0)).
+		WithOutbox(&mockOutbox{addErr: errOutboxFailed})
+
+	err := svc.Handle(context.Background())
+	require.ErrorIs(t, err, errOutboxFailed)
+	assert.Contains(t, err.Error(), "failed to queue notifications")
+	assert.Nil(t, snap.saved, "snapshot not committed")
+	assert.Empty(t, repo.saved)
+	assert.Empty(t, sender.sent)
+}
+
+func TestNotifyUsers_History_NotRecordedTwiceWhenSnapshotSaveFails(t *testing.T) {
+	provider := &mockProvider{outages: []outage.RawOutage{makeTestOutage(1, []string{"10"})}}
+	history := &mockHistory{}
+	snapshot := &mockOutageRepo{saveErr: errors.New("disk full")}
+	svc := newNotifyUsersWithSnapshot(provider, &mockSender{}, newMockUserRepo(), snapshot, log.New(io.Discard, "", 0)).
+		WithHistory(history)
+
+	require.Error(t, svc.Handle(context.Background()))
+	assert.Empty(t, history.recorded, "nothing archived before the snapshot is committed")
+
+	snapshot.saveErr = nil
+	require.NoError(t, svc.Handle(context.Background()))
+	require.NoError(t, svc.Handle(context.Background()))
+
+	seen := 0
+	for _, changes := range history.recorded {
+		seen += len(changes.New)
+	}
+	assert.Equal(t, 1, seen, "the outage is archived as seen exactly once")
+}
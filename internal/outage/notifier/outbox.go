@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Message is a notification queued for a user.
+type Message struct {
+	// Key identifies the message so that queuing it again has no effect.
+	Key     string
+	UserID  int64
+	Content Content
+	// Attempts counts the failed deliveries so far.
+	Attempts int
+	// RetryAt is when a failed message may be sent again; zero when it has not failed yet.
+	RetryAt time.Time
+}
+
+// due reports whether the message may be sent at now.
+func (m Message) due(now time.Time) bool {
+	return !m.RetryAt.After(now)
+}
+
+// newMessage queues content for the user under a key derived from what the message reports.
+func newMessage(userID int64, content Content) Message {
+	return Message{Key: messageKey(userID, content), UserID: userID, Content: content}
+}
+
+// messageKey hashes everything the message reports, so that the same notification planned twice,
+// e.g. after a crash between queuing it and committing the snapshot, shares one key. Silent is left
+// out since it depends on when the message was planned rather than on its content.
+func messageKey(userID int64, c Content) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%s|%d|%s|%s|%s|%s|%s|%d|%d|%s|%s|%d|%s",
+		userID, c.Event,
+		c.SavedAddress.StreetID, c.SavedAddress.Building, c.City, c.StreetName, strings.Join(c.Buildings, ","), c.Kind,
+		c.Start.Unix(), c.End.Unix(), c.Comment,
+		c.PreviousComment, c.PreviousEnd.Unix(), c.SavedAddress.City,
+	)
+	for _, item := range c.Items {
+		fmt.Fprintf(h, "|%s", messageKey(userID, item))
+	}
+	return fmt.Sprintf("%d-%s", userID, hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+// Outbox durably holds notifications from the moment they are planned until they are delivered,
+// so that a run interrupted midway does not lose them.
+type Outbox interface {
+	// Add queues the messages whose keys are not queued yet, keeping their order.
+	Add(messages []Message) error
+	// Pending returns the queued messages in the order they were added.
+	Pending() ([]Message, error)
+	// Postpone records a failed delivery of the message: the number of attempts so far and when to retry.
+	Postpone(key string, attempts int, retryAt time.Time) error
+	// Done removes a delivered or abandoned message.
+	Done(key string) error
+}
+
+// memoryOutbox is the Outbox used when none is configured. It lasts as long as the process.
+type memoryOutbox struct {
+	messages []Message
+}
+
+func (o *memoryOutbox) Add(messages []Message) error {
+	for _, m := range messages {
+		if !slices.ContainsFunc(o.messages, func(queued Message) bool { return queued.Key == m.Key }) {
+			o.messages = append(o.messages, m)
+		}
+	}
+	return nil
+}
+
+func (o *memoryOutbox) Pending() ([]Message, error) {
+	return slices.Clone(o.messages), nil
+}
+
+func (o *memoryOutbox) Postpone(key string, attempts int, retryAt time.Time) error {
+	for i := range o.messages {
+		if o.messages[i].Key == key {
+			o.messages[i].Attempts = attempts
+			o.messages[i].RetryAt = retryAt
+		}
+	}
+	return nil
+}
+
+func (o *memoryOutbox) Done(key string) error {
+	o.messages = slices.DeleteFunc(o.messages, func(m Message) bool { return m.Key == key })
+	return nil
+}
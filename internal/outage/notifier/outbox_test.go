@@ -0,0 +1,87 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessageContent() Content {
+	return Content{
+		Event:      EventOutage,
+		City:       "Львів",
+		StreetName: "Стрийська",
+		Buildings:  []string{"10"},
+		Start:      time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		End:        time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC),
+		Comment:    "test",
+	}
+}
+
+func TestMessageKey_IdentifiesContentPerUser(t *testing.T) {
+	c := testMessageContent()
+	key := newMessage(100, c).Key
+
+	silent := c
+	silent.Silent = true
+	assert.Equal(t, key, newMessage(100, silent).Key, "delivery mode is not part of the key")
+
+	assert.NotEqual(t, key, newMessage(200, c).Key)
+
+	restored := c
+	restored.Event = EventRestored
+	assert.NotEqual(t, key, newMessage(100, restored).Key)
+
+	extended := c
+	extended.End = c.End.Add(time.Hour)
+	assert.NotEqual(t, key, newMessage(100, extended).Key)
+}
+
+func TestMemoryOutbox_AddPendingDone(t *testing.T) {
+	outbox := &memoryOutbox{}
+	first := newMessage(100, testMessageContent())
+	restored := testMessageContent()
+	restored.Event = EventRestored
+	second := newMessage(100, restored)
+
+	require.NoError(t, outbox.Add([]Message{first, second}))
+	require.NoError(t, outbox.Add([]Message{first}))
+
+	pending, err := outbox.Pending()
+	require.NoError(t, err)
+	assert.Equal(t, []Message{first, second}, pending)
+
+	require.NoError(t, outbox.Done(first.Key))
+	pending, err = outbox.Pending()
+	require.NoError(t, err)
+	assert.Equal(t, []Message{second}, pending)
+}
+
+func TestEvent_StringRoundTrip(t *testing.T) {
+	for _, e := range []Event{EventOutage, EventRestored, EventUpdate, EventReminder, EventOverdue} {
+		parsed, ok := ParseEvent(e.String())
+		require.True(t, ok, e.String())
+		assert.Equal(t, e, parsed)
+	}
+	_, ok := ParseEvent("unknown")
+	assert.False(t, ok)
+}
+
+func TestMemoryOutbox_Postpone(t *testing.T) {
+	outbox := &memoryOutbox{}
+	m := newMessage(100, testMessageContent())
+	require.NoError(t, outbox.Add([]Message{m}))
+
+	retryAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, outbox.Postpone(m.Key, 1, retryAt))
+
+	pending, err := outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Equal(t, retryAt, pending[0].RetryAt)
+	assert.False(t, pending[0].due(retryAt.Add(-time.Second)))
+	assert.True(t, pending[0].due(retryAt))
+}
@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces sends out so that they stay within a global and a per-chat rate limit.
+// It is shared by all delivery workers.
+type rateLimiter struct {
+	mu       sync.Mutex
+	now      func() time.Time
+	interval time.Duration
+	perChat  time.Duration
+	next     time.Time
+	nextChat map[int64]time.Time
+}
+
+func newRateLimiter(interval, perChat time.Duration) *rateLimiter {
+	return &rateLimiter{
+		now:      time.Now,
+		interval: interval,
+		perChat:  perChat,
+		nextChat: make(map[int64]time.Time),
+	}
+}
+
+// reserve books the earliest slot allowed for a send to chatID and returns how long to wait for it.
+func (l *rateLimiter) reserve(chatID int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	slot := now
+	if l.next.After(slot) {
+		slot = l.next
+	}
+	if next := l.nextChat[chatID]; next.After(slot) {
+		slot = next
+	}
+	l.next = slot.Add(l.interval)
+	if l.perChat > 0 {
+		l.nextChat[chatID] = slot.Add(l.perChat)
+	}
+	return slot.Sub(now)
+}
+
+// pause holds back every send for d, as asked by a rate-limit response.
+func (l *rateLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := l.now().Add(d); until.After(l.next) {
+		l.next = until
+	}
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
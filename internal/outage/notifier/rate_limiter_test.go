@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRateLimiter(interval, perChat time.Duration) (*rateLimiter, *testClock) {
+	clock := &testClock{now: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)}
+	l := newRateLimiter(interval, perChat)
+	l.now = clock.Now
+	return l, clock
+}
+
+func TestRateLimiter_SpacesSendsGlobally(t *testing.T) {
+	l, _ := newTestRateLimiter(100*time.Millisecond, 0)
+
+	assert.Zero(t, l.reserve(1))
+	assert.Equal(t, 100*time.Millisecond, l.reserve(2))
+	assert.Equal(t, 200*time.Millisecond, l.reserve(3))
+}
+
+func TestRateLimiter_SpacesSendsPerChat(t *testing.T) {
+	l, clock := newTestRateLimiter(10*time.Millisecond, time.Second)
+
+	assert.Zero(t, l.reserve(1))
+	assert.Equal(t, 10*time.Millisecond, l.reserve(2), "other chats only wait for the global gap")
+	assert.Equal(t, time.Second, l.reserve(1))
+
+	clock.now = clock.now.Add(5 * time.Second)
+	assert.Zero(t, l.reserve(1), "slots in the past are not waited for")
+}
+
+func TestRateLimiter_PauseHoldsBackAllChats(t *testing.T) {
+	l, clock := newTestRateLimiter(0, 0)
+
+	l.pause(3 * time.Second)
+	assert.Equal(t, 3*time.Second, l.reserve(1))
+	assert.Equal(t, 3*time.Second, l.reserve(2))
+
+	clock.now = clock.now.Add(time.Second)
+	l.pause(time.Second)
+	assert.Equal(t, 2*time.Second, l.reserve(3), "a shorter pause does not cut the longer one")
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	l, _ := newTestRateLimiter(0, 0)
+	for range 5 {
+		assert.Zero(t, l.reserve(1))
+	}
+}
+
+func TestSleep_StopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, sleep(ctx, time.Hour), context.Canceled)
+	assert.ErrorIs(t, sleep(ctx, 0), context.Canceled)
+	assert.NoError(t, sleep(context.Background(), 0))
+}
@@ -0,0 +1,83 @@
+package outage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagnostic records a row of the outage feed that was dropped, or one of whose values was
+// replaced, because it could not be used as delivered.
+type Diagnostic struct {
+	// Source is the page or file the row came from, and Row its position there counting
+	// from 1. Both are empty for rows rejected after the pages were merged.
+	Source string
+	Row    int
+	// ID is the row's id, when it could be read.
+	ID int
+	// Field names the offending part of the row, e.g. "dateEvent" or "address".
+	Field string
+	// Reason is a short, fixed description used to count diagnostics of the same kind.
+	Reason string
+	// Detail gives the offending value or error.
+	Detail string
+	// Dropped is set when the row was left out rather than corrected.
+	Dropped bool
+}
+
+// Kind identifies diagnostics of the same kind.
+func (d Diagnostic) Kind() string {
+	return d.Field + ": " + d.Reason
+}
+
+// DiagnosingProvider is a RawProvider that reports the rows it dropped or corrected during
+// its last fetch.
+type DiagnosingProvider interface {
+	RawProvider
+	Diagnostics() []Diagnostic
+}
+
+// DiagnosticCount is the number of diagnostics of one kind.
+type DiagnosticCount struct {
+	Kind  string
+	Count int
+}
+
+// CountDiagnostics counts the diagnostics of every kind, in order of first appearance.
+func CountDiagnostics(diagnostics []Diagnostic) []DiagnosticCount {
+	var counts []DiagnosticCount
+	index := make(map[string]int)
+	for _, d := range diagnostics {
+		i, ok := index[d.Kind()]
+		if !ok {
+			i = len(counts)
+			index[d.Kind()] = i
+			counts = append(counts, DiagnosticCount{Kind: d.Kind()})
+		}
+		counts[i].Count++
+	}
+	return counts
+}
+
+// SummarizeDiagnostics describes the diagnostics in one line, e.g.
+// "1 row dropped, 2 values corrected (address: invalid address 1, dateEvent: missing date 2)".
+func SummarizeDiagnostics(diagnostics []Diagnostic) string {
+	dropped := 0
+	for _, d := range diagnostics {
+		if d.Dropped {
+			dropped++
+		}
+	}
+	var kinds []string
+	for _, c := range CountDiagnostics(diagnostics) {
+		kinds = append(kinds, fmt.Sprintf("%s %d", c.Kind, c.Count))
+	}
+	return fmt.Sprintf("%s dropped, %s corrected (%s)",
+		plural(dropped, "row"), plural(len(diagnostics)-dropped, "value"), strings.Join(kinds, ", "))
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,31 @@
+package outage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountDiagnostics_GroupsByKindInOrder(t *testing.T) {
+	diagnostics := []Diagnostic{
+		{Field: "datePlanIn", Reason: "missing date"},
+		{Field: "row", Reason: "undecodable row", Dropped: true},
+		{Field: "datePlanIn", Reason: "missing date"},
+	}
+
+	assert.Equal(t, []DiagnosticCount{
+		{Kind: "datePlanIn: missing date", Count: 2},
+		{Kind: "row: undecodable row", Count: 1},
+	}, CountDiagnostics(diagnostics))
+}
+
+func TestSummarizeDiagnostics(t *testing.T) {
+	diagnostics := []Diagnostic{
+		{Field: "address", Reason: "invalid address", Dropped: true},
+		{Field: "dateEvent", Reason: "missing date"},
+		{Field: "dateEvent", Reason: "missing date"},
+	}
+
+	assert.Equal(t, "1 row dropped, 2 values corrected (address: invalid address 1, dateEvent: missing date 2)",
+		SummarizeDiagnostics(diagnostics))
+}
@@ -0,0 +1,74 @@
+package outage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Changes classifies the differences between two outage snapshots.
+type Changes struct {
+	// New holds outages present only in the current snapshot.
+	New []*Outage
+	// Changed holds the current version of outages whose details differ from the previous snapshot.
+	Changed []*Outage
+	// Resolved holds the previous version of outages that are gone from the current snapshot.
+	Resolved []*Outage
+}
+
+// Empty reports whether there are no differences.
+func (c Changes) Empty() bool {
+	return len(c.New) == 0 && len(c.Changed) == 0 && len(c.Resolved) == 0
+}
+
+// Diff compares the previous and current snapshots outage by outage.
+// Outages are paired by API ID when both sides have one, otherwise by street,
+// start time and buildings, so that snapshots written before IDs were stored still pair up.
+// Order within a snapshot is irrelevant.
+func Diff(prev, curr []*Outage) Changes {
+	var changes Changes
+	paired := make(map[*Outage]bool, len(prev))
+
+	byID := make(map[int]*Outage)
+	byNaturalKey := make(map[string][]*Outage)
+	for _, o := range prev {
+		if o.ID != 0 {
+			byID[o.ID] = o
+		}
+		key := o.naturalKey()
+		byNaturalKey[key] = append(byNaturalKey[key], o)
+	}
+
+	for _, o := range curr {
+		match := byID[o.ID]
+		if o.ID == 0 || match == nil || paired[match] {
+			match = nil
+			for _, candidate := range byNaturalKey[o.naturalKey()] {
+				if !paired[candidate] && (candidate.ID == 0 || o.ID == 0 || candidate.ID == o.ID) {
+					match = candidate
+					break
+				}
+			}
+		}
+
+		switch {
+		case match == nil:
+			changes.New = append(changes.New, o)
+		case !match.SameDetails(o):
+			paired[match] = true
+			changes.Changed = append(changes.Changed, o)
+		default:
+			paired[match] = true
+		}
+	}
+
+	for _, o := range prev {
+		if !paired[o] {
+			changes.Resolved = append(changes.Resolved, o)
+		}
+	}
+	return changes
+}
+
+func (o *Outage) naturalKey() string {
+	return fmt.Sprintf("%d|%d|%s", o.Address.StreetID, o.Period.StartDate.Unix(), strings.Join(o.Address.Buildings, ","))
+}
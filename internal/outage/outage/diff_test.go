@@ -0,0 +1,74 @@
+package outage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func withID(o *Outage, id int) *Outage {
+	o.ID = id
+	return o
+}
+
+func TestDiff_NoPrevious_AllNew(t *testing.T) {
+	curr := []*Outage{withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 1)}
+	changes := Diff(nil, curr)
+	assert.Equal(t, curr, changes.New)
+	assert.Empty(t, changes.Changed)
+	assert.Empty(t, changes.Resolved)
+}
+
+func TestDiff_Unchanged_Empty(t *testing.T) {
+	prev := []*Outage{withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 1)}
+	curr := []*Outage{withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 1)}
+	assert.True(t, Diff(prev, curr).Empty())
+}
+
+func TestDiff_ReorderedOutages_Empty(t *testing.T) {
+	a := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 1)
+	b := withID(makeTestOutage(2, "Наукова", []string{"5"}, ot0, ot1, "c"), 2)
+	assert.True(t, Diff([]*Outage{a, b}, []*Outage{b, a}).Empty())
+}
+
+func TestDiff_EndExtended_Changed(t *testing.T) {
+	prev := []*Outage{withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 1)}
+	extended := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1.Add(2*time.Hour), "c"), 1)
+	changes := Diff(prev, []*Outage{extended})
+	assert.Empty(t, changes.New)
+	assert.Equal(t, []*Outage{extended}, changes.Changed)
+	assert.Empty(t, changes.Resolved)
+}
+
+func TestDiff_Disappeared_Resolved(t *testing.T) {
+	gone := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 1)
+	kept := withID(makeTestOutage(2, "Наукова", []string{"5"}, ot0, ot1, "c"), 2)
+	changes := Diff([]*Outage{gone, kept}, []*Outage{kept})
+	assert.Empty(t, changes.New)
+	assert.Empty(t, changes.Changed)
+	assert.Equal(t, []*Outage{gone}, changes.Resolved)
+}
+
+func TestDiff_DifferentIDSameAddress_NewAndResolved(t *testing.T) {
+	prev := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 1)
+	curr := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 2)
+	changes := Diff([]*Outage{prev}, []*Outage{curr})
+	assert.Equal(t, []*Outage{curr}, changes.New)
+	assert.Equal(t, []*Outage{prev}, changes.Resolved)
+}
+
+func TestDiff_PreviousWithoutIDs_PairsByNaturalKey(t *testing.T) {
+	// Snapshots written before IDs were persisted load with ID 0.
+	prev := makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c")
+	curr := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "new comment"), 7)
+	changes := Diff([]*Outage{prev}, []*Outage{curr})
+	assert.Empty(t, changes.New)
+	assert.Equal(t, []*Outage{curr}, changes.Changed)
+	assert.Empty(t, changes.Resolved)
+}
+
+func TestChanges_Empty(t *testing.T) {
+	assert.True(t, Changes{}.Empty())
+	assert.False(t, Changes{Resolved: []*Outage{{}}}.Empty())
+}
@@ -2,11 +2,16 @@ package outage
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"time"
 )
 
 // FetchOutages converts provider DTOs into domain Outage entities.
 type FetchOutages struct {
-	provider RawProvider
+	provider    RawProvider
+	logger      *log.Logger
+	diagnostics []Diagnostic
 }
 
 // NewFetchOutages creates a new FetchOutages.
@@ -14,25 +19,62 @@ func NewFetchOutages(provider RawProvider) *FetchOutages {
 	return &FetchOutages{provider: provider}
 }
 
+// WithLogger logs a summary of the rows dropped or corrected by every Handle.
+func (s *FetchOutages) WithLogger(logger *log.Logger) *FetchOutages {
+	s.logger = logger
+	return s
+}
+
+// Diagnostics returns the rows dropped or corrected by the last Handle, both by the provider
+// and in the conversion to domain entities.
+func (s *FetchOutages) Diagnostics() []Diagnostic {
+	return s.diagnostics
+}
+
 // Handle fetches outages from the provider and converts them to domain entities.
 func (s *FetchOutages) Handle(ctx context.Context) ([]*Outage, error) {
+	s.diagnostics = nil
 	dtos, err := s.provider.FetchOutages(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return dtosToOutages(dtos), nil
+	if p, ok := s.provider.(DiagnosingProvider); ok {
+		s.diagnostics = append(s.diagnostics, p.Diagnostics()...)
+	}
+	outages, dropped := dtosToOutages(dtos)
+	s.diagnostics = append(s.diagnostics, dropped...)
+	if s.logger != nil && len(s.diagnostics) > 0 {
+		s.logger.Printf("outage feed: %s", SummarizeDiagnostics(s.diagnostics))
+	}
+	return outages, nil
 }
 
-func dtosToOutages(dtos []RawOutage) []*Outage {
+// dtosToOutages converts the rows to outages, leaving out those without a valid period or address.
+func dtosToOutages(dtos []RawOutage) ([]*Outage, []Diagnostic) {
 	outages := make([]*Outage, 0, len(dtos))
+	var dropped []Diagnostic
 	for _, dto := range dtos {
 		period, err := NewPeriod(dto.Start, dto.End)
 		if err != nil {
+			dropped = append(dropped, Diagnostic{
+				ID:      dto.ID,
+				Field:   "period",
+				Reason:  "invalid period",
+				Detail:  fmt.Sprintf("%v: %s – %s", err, dto.Start.Format(time.DateTime), dto.End.Format(time.DateTime)),
+				Dropped: true,
+			})
 			continue
 		}
 		addr, err := NewAddress(dto.StreetID, dto.StreetName, dto.Buildings, dto.City)
 		if err != nil {
+			dropped = append(dropped, Diagnostic{
+				ID:      dto.ID,
+				Field:   "address",
+				Reason:  "invalid address",
+				Detail:  fmt.Sprintf("%v: street %d %q, buildings %q", err, dto.StreetID, dto.StreetName, dto.Buildings),
+				Dropped: true,
+			})
 			continue
 		}
 		outages = append(outages, &Outage{
@@ -40,7 +82,9 @@ func dtosToOutages(dtos []RawOutage) []*Outage {
 			Period:      period,
 			Address:     addr,
 			Description: NewDescription(dto.Comment),
+			Kind:        NewKind(dto.TypeOff, dto.SubReason),
+			OTG:         dto.OTG,
 		})
 	}
-	return outages
+	return outages, dropped
 }
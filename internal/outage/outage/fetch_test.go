@@ -1,8 +1,10 @@
 package outage
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"log"
 	"testing"
 	"time"
 
@@ -129,3 +131,65 @@ func TestFetchOutages_NilProvider(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, outages)
 }
+
+func TestFetchOutages_DerivesKind(t *testing.T) {
+	provider := &mockProvider{
+		outages: []RawOutage{
+			{ID: 1, Start: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC), StreetID: 1, StreetName: "S", Buildings: []string{"1"}, TypeOff: 1, SubReason: 1, OTG: "Львівська"},
+			{ID: 2, Start: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC), StreetID: 1, StreetName: "S", Buildings: []string{"2"}, TypeOff: 2},
+		},
+	}
+	svc := NewFetchOutages(provider)
+	outages, err := svc.Handle(context.Background())
+	require.NoError(t, err)
+	require.Len(t, outages, 2)
+	assert.Equal(t, KindEmergency, outages[0].Kind)
+	assert.Equal(t, KindPlanned, outages[1].Kind)
+	assert.Equal(t, "Львівська", outages[0].OTG)
+}
+
+type diagnosingProvider struct {
+	mockProvider
+	diagnostics []Diagnostic
+}
+
+func (m *diagnosingProvider) Diagnostics() []Diagnostic {
+	return m.diagnostics
+}
+
+func TestFetchOutages_ReportsDroppedRows(t *testing.T) {
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	provider := &diagnosingProvider{
+		mockProvider: mockProvider{outages: []RawOutage{
+			{ID: 1, Start: start, End: start.Add(8 * time.Hour), StreetID: 1, StreetName: "Стрийська", Buildings: []string{"10"}},
+			{ID: 2, Start: start, End: start.Add(-time.Hour), StreetID: 1, StreetName: "Стрийська", Buildings: []string{"10"}},
+			{ID: 3, Start: start, End: start.Add(time.Hour), StreetID: 1, StreetName: "Стрийська"},
+		}},
+		diagnostics: []Diagnostic{{Source: "page", Row: 4, Field: "row", Reason: "undecodable row", Dropped: true}},
+	}
+	var logs bytes.Buffer
+	svc := NewFetchOutages(provider).WithLogger(log.New(&logs, "", 0))
+
+	outages, err := svc.Handle(context.Background())
+	require.NoError(t, err)
+	require.Len(t, outages, 1)
+
+	diagnostics := svc.Diagnostics()
+	require.Len(t, diagnostics, 3)
+	assert.Equal(t, "row", diagnostics[0].Field)
+	assert.Equal(t, Diagnostic{ID: 2, Field: "period", Reason: "invalid period", Dropped: true,
+		Detail: ErrInvalidDateRange.Error() + ": 2024-01-01 08:00:00 – 2024-01-01 07:00:00"}, diagnostics[1])
+	assert.Equal(t, 3, diagnostics[2].ID)
+	assert.Equal(t, "invalid address", diagnostics[2].Reason)
+	assert.Equal(t, "outage feed: 3 rows dropped, 0 values corrected (row: undecodable row 1, period: invalid period 1, address: invalid address 1)\n", logs.String())
+}
+
+func TestFetchOutages_LogsNothingForCleanFeed(t *testing.T) {
+	var logs bytes.Buffer
+	svc := NewFetchOutages(&mockProvider{}).WithLogger(log.New(&logs, "", 0))
+
+	_, err := svc.Handle(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, svc.Diagnostics())
+	assert.Empty(t, logs.String())
+}
@@ -0,0 +1,147 @@
+package outage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// HistoryEventType identifies what happened to an outage in the history archive.
+type HistoryEventType string
+
+const (
+	// HistorySeen records an outage appearing in the feed.
+	HistorySeen HistoryEventType = "seen"
+	// HistoryRevised records a change of an outage's period, comment or other details.
+	HistoryRevised HistoryEventType = "revised"
+	// HistoryResolved records an outage disappearing from the feed.
+	HistoryResolved HistoryEventType = "resolved"
+)
+
+// HistoryEvent is a single append-only entry of the outage history archive.
+type HistoryEvent struct {
+	Type   HistoryEventType
+	At     time.Time
+	Outage *Outage
+	// LastSeen is the last time the outage was observed in the feed; set for HistoryResolved only.
+	LastSeen time.Time
+}
+
+// HistoryEvents turns the differences between two consecutive snapshots into archive events.
+// lastSeen is the time of the previous fetch, when resolved outages were observed for the last time.
+func HistoryEvents(changes Changes, at, lastSeen time.Time) []HistoryEvent {
+	events := make([]HistoryEvent, 0, len(changes.New)+len(changes.Changed)+len(changes.Resolved))
+	for _, o := range changes.New {
+		events = append(events, HistoryEvent{Type: HistorySeen, At: at, Outage: o})
+	}
+	for _, o := range changes.Changed {
+		events = append(events, HistoryEvent{Type: HistoryRevised, At: at, Outage: o})
+	}
+	for _, o := range changes.Resolved {
+		events = append(events, HistoryEvent{Type: HistoryResolved, At: at, Outage: o, LastSeen: lastSeen})
+	}
+	return events
+}
+
+// HistoryStore is an append-only archive of outage events.
+type HistoryStore interface {
+	// Record appends the events observed by the fetch made at the given time.
+	Record(at time.Time, changes Changes) error
+}
+
+// HistoryReader reads the outage history archive.
+type HistoryReader interface {
+	Entries() ([]*HistoryEntry, error)
+}
+
+// HistoryEntry is the archived life of a single outage, rebuilt from its events.
+type HistoryEntry struct {
+	// Outage is the latest known version of the outage.
+	Outage    *Outage
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// Periods lists every distinct period the outage had, oldest first.
+	Periods []Period
+	// ResolvedAt is when the outage disappeared from the feed, or zero while it is still listed.
+	ResolvedAt time.Time
+}
+
+// Resolved reports whether the outage is gone from the feed.
+func (e *HistoryEntry) Resolved() bool {
+	return !e.ResolvedAt.IsZero()
+}
+
+// BuildHistory folds chronological archive events into one entry per outage, ordered by first appearance.
+// Events are paired by API ID when known, otherwise by street, start time and buildings.
+// lastChecked is the time of the latest fetch and becomes the last-seen time of outages still listed.
+func BuildHistory(events []HistoryEvent, lastChecked time.Time) []*HistoryEntry {
+	var entries []*HistoryEntry
+	byKey := make(map[string]*HistoryEntry)
+
+	for _, ev := range events {
+		key := ev.Outage.historyKey()
+		entry := byKey[key]
+		if entry == nil {
+			entry = &HistoryEntry{Outage: ev.Outage, FirstSeen: ev.At}
+			byKey[key] = entry
+			entries = append(entries, entry)
+		}
+
+		switch ev.Type {
+		case HistoryResolved:
+			entry.ResolvedAt = ev.At
+			entry.LastSeen = ev.LastSeen
+		default:
+			entry.Outage = ev.Outage
+			entry.ResolvedAt = time.Time{}
+		}
+		if !slices.ContainsFunc(entry.Periods, ev.Outage.Period.Equals) {
+			entry.Periods = append(entry.Periods, ev.Outage.Period)
+		}
+	}
+
+	for _, entry := range entries {
+		if !entry.Resolved() {
+			entry.LastSeen = lastChecked
+		}
+		if entry.LastSeen.Before(entry.FirstSeen) {
+			entry.LastSeen = entry.FirstSeen
+		}
+	}
+	return entries
+}
+
+// HistoryFilter selects archive entries. Zero fields match everything.
+type HistoryFilter struct {
+	// Street matches street names case-insensitively by substring.
+	Street   string
+	Building string
+	// From and To bound the outage period; entries whose any period overlaps [From, To) match.
+	From time.Time
+	To   time.Time
+}
+
+// Matches reports whether the entry passes the filter.
+func (f HistoryFilter) Matches(e *HistoryEntry) bool {
+	addr := e.Outage.Address
+	if f.Street != "" && !strings.Contains(strings.ToLower(addr.StreetName), strings.ToLower(f.Street)) {
+		return false
+	}
+	if f.Building != "" && !slices.Contains(addr.Buildings, f.Building) {
+		return false
+	}
+	if f.From.IsZero() && f.To.IsZero() {
+		return true
+	}
+	return slices.ContainsFunc(e.Periods, func(p Period) bool {
+		return (f.To.IsZero() || p.StartDate.Before(f.To)) && (f.From.IsZero() || p.EndDate.After(f.From))
+	})
+}
+
+func (o *Outage) historyKey() string {
+	if o.ID != 0 {
+		return fmt.Sprintf("id:%d", o.ID)
+	}
+	return o.naturalKey()
+}
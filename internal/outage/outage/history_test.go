@@ -0,0 +1,65 @@
+package outage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildHistory_TracksRevisionsAndResolution(t *testing.T) {
+	original := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 7)
+	extended := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1.Add(2*time.Hour), "c"), 7)
+	seenAt := ot0.Add(-time.Hour)
+	revisedAt := ot0.Add(time.Hour)
+	resolvedAt := ot1.Add(3 * time.Hour)
+
+	var events []HistoryEvent
+	events = append(events, HistoryEvents(Changes{New: []*Outage{original}}, seenAt, seenAt)...)
+	events = append(events, HistoryEvents(Changes{Changed: []*Outage{extended}}, revisedAt, seenAt)...)
+	events = append(events, HistoryEvents(Changes{Resolved: []*Outage{extended}}, resolvedAt, resolvedAt.Add(-time.Minute))...)
+
+	entries := BuildHistory(events, resolvedAt.Add(time.Hour))
+	require.Len(t, entries, 1)
+	e := entries[0]
+	assert.Same(t, extended, e.Outage)
+	assert.Equal(t, seenAt, e.FirstSeen)
+	assert.Equal(t, resolvedAt.Add(-time.Minute), e.LastSeen)
+	assert.Equal(t, resolvedAt, e.ResolvedAt)
+	assert.True(t, e.Resolved())
+	assert.Equal(t, []Period{original.Period, extended.Period}, e.Periods)
+}
+
+func TestBuildHistory_ActiveOutageLastSeenAtLatestCheck(t *testing.T) {
+	o := makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c")
+	checked := ot0.Add(30 * time.Minute)
+
+	entries := BuildHistory(HistoryEvents(Changes{New: []*Outage{o}}, ot0, ot0), checked)
+	require.Len(t, entries, 1)
+	assert.False(t, entries[0].Resolved())
+	assert.Equal(t, checked, entries[0].LastSeen)
+}
+
+func TestBuildHistory_SeparatesOutagesByID(t *testing.T) {
+	a := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 1)
+	b := withID(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), 2)
+
+	entries := BuildHistory(HistoryEvents(Changes{New: []*Outage{a, b}}, ot0, ot0), ot0)
+	assert.Len(t, entries, 2)
+}
+
+func TestHistoryFilter_Matches(t *testing.T) {
+	o := makeTestOutage(1, "Стрийська", []string{"10", "12"}, ot0, ot1, "c")
+	e := &HistoryEntry{Outage: o, Periods: []Period{o.Period}}
+
+	assert.True(t, HistoryFilter{}.Matches(e))
+	assert.True(t, HistoryFilter{Street: "стрий"}.Matches(e))
+	assert.False(t, HistoryFilter{Street: "Наукова"}.Matches(e))
+	assert.True(t, HistoryFilter{Building: "12"}.Matches(e))
+	assert.False(t, HistoryFilter{Building: "1"}.Matches(e))
+	assert.True(t, HistoryFilter{From: ot0.Add(time.Hour)}.Matches(e), "overlaps the start of the range")
+	assert.False(t, HistoryFilter{From: ot1}.Matches(e))
+	assert.True(t, HistoryFilter{To: ot0.Add(time.Minute)}.Matches(e))
+	assert.False(t, HistoryFilter{To: ot0}.Matches(e))
+}
@@ -10,6 +10,9 @@ type Outage struct {
 	Period      Period
 	Address     Address
 	Description Description
+	Kind        Kind
+	// OTG is the name of the territorial community (ОТГ) reporting the outage.
+	OTG string
 }
 
 // OutagesEqual reports whether a and b contain the same outages in the same order.
@@ -19,16 +22,21 @@ func OutagesEqual(a, b []*Outage) bool {
 		return false
 	}
 	for i := range a {
-		oa, ob := a[i], b[i]
-		if oa.Address.StreetID != ob.Address.StreetID ||
-			oa.Address.City != ob.Address.City ||
-			oa.Address.StreetName != ob.Address.StreetName ||
-			!slices.Equal(oa.Address.Buildings, ob.Address.Buildings) ||
-			oa.Period.StartDate.Unix() != ob.Period.StartDate.Unix() ||
-			oa.Period.EndDate.Unix() != ob.Period.EndDate.Unix() ||
-			oa.Description.Value != ob.Description.Value {
+		if !a[i].SameDetails(b[i]) {
 			return false
 		}
 	}
 	return true
 }
+
+// SameDetails reports whether two versions of an outage carry the same address, period, comment and kind.
+// The API ID is not compared.
+func (o *Outage) SameDetails(other *Outage) bool {
+	return o.Address.StreetID == other.Address.StreetID &&
+		o.Address.City == other.Address.City &&
+		o.Address.StreetName == other.Address.StreetName &&
+		slices.Equal(o.Address.Buildings, other.Address.Buildings) &&
+		o.Period.Equals(other.Period) &&
+		o.Description.Equals(other.Description) &&
+		o.Kind == other.Kind
+}
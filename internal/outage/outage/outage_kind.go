@@ -0,0 +1,82 @@
+package outage
+
+// Kind classifies an outage by the reason reported by the API.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindPlanned
+	KindEmergency
+	KindHourly
+)
+
+// API values of switchSubReason and typeOff that determine the outage kind. The sub-reasons
+// are those of the recorded feed in test/integration/testdata/loe_data.json, where rows with
+// switchSubReason 1 and 2 carry the comments "Застосування ГАВ" and "Застосування ГПВ"; rows
+// still being clarified have 0. Every row there has typeOff 1. That typeOff 2 marks planned
+// work is not confirmed by a recorded response.
+const (
+	subReasonEmergency = 1 // ГАВ — графік аварійних відключень
+	subReasonHourly    = 2 // ГПВ — графік погодинних відключень
+	typeOffPlanned     = 2
+)
+
+var kindCodes = map[Kind]string{
+	KindUnknown:   "unknown",
+	KindPlanned:   "planned",
+	KindEmergency: "emergency",
+	KindHourly:    "hourly",
+}
+
+var kindLabels = map[Kind]string{
+	KindUnknown:   "Не визначено",
+	KindPlanned:   "Планові роботи",
+	KindEmergency: "Аварійне відключення (ГАВ)",
+	KindHourly:    "Погодинне відключення (ГПВ)",
+}
+
+// SelectableKinds lists the kinds a user can filter notifications on. KindPlanned stays out
+// until a recorded response confirms typeOffPlanned; outages of kinds not listed here are
+// delivered regardless of the user's filter.
+var SelectableKinds = []Kind{KindEmergency, KindHourly}
+
+// NewKind derives the outage kind from the API typeOff and switchSubReason values.
+// The sub-reason takes precedence because it distinguishes ГАВ from ГПВ shutdowns.
+func NewKind(typeOff, subReason int) Kind {
+	switch {
+	case subReason == subReasonEmergency:
+		return KindEmergency
+	case subReason == subReasonHourly:
+		return KindHourly
+	case typeOff == typeOffPlanned:
+		return KindPlanned
+	default:
+		return KindUnknown
+	}
+}
+
+// ParseKind parses a kind code as produced by String. Unrecognised codes yield KindUnknown and false.
+func ParseKind(code string) (Kind, bool) {
+	for k, c := range kindCodes {
+		if c == code {
+			return k, true
+		}
+	}
+	return KindUnknown, false
+}
+
+// String returns the stable code used in persisted files.
+func (k Kind) String() string {
+	if c, ok := kindCodes[k]; ok {
+		return c
+	}
+	return kindCodes[KindUnknown]
+}
+
+// Label returns the human-readable Ukrainian name of the kind.
+func (k Kind) Label() string {
+	if l, ok := kindLabels[k]; ok {
+		return l
+	}
+	return kindLabels[KindUnknown]
+}
@@ -0,0 +1,48 @@
+package outage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKind(t *testing.T) {
+	tests := []struct {
+		name      string
+		typeOff   int
+		subReason int
+		want      Kind
+	}{
+		{"emergency sub-reason", 1, 1, KindEmergency},
+		{"hourly sub-reason", 1, 2, KindHourly},
+		{"planned type", 2, 0, KindPlanned},
+		{"sub-reason wins over type", 2, 1, KindEmergency},
+		{"unclear", 1, 0, KindUnknown},
+		{"missing fields", 0, 0, KindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewKind(tt.typeOff, tt.subReason))
+		})
+	}
+}
+
+func TestKind_StringParseRoundTrip(t *testing.T) {
+	for _, k := range []Kind{KindUnknown, KindPlanned, KindEmergency, KindHourly} {
+		parsed, ok := ParseKind(k.String())
+		assert.True(t, ok)
+		assert.Equal(t, k, parsed)
+	}
+}
+
+func TestParseKind_Unrecognised(t *testing.T) {
+	k, ok := ParseKind("bogus")
+	assert.False(t, ok)
+	assert.Equal(t, KindUnknown, k)
+}
+
+func TestKind_Label(t *testing.T) {
+	assert.Equal(t, "Аварійне відключення (ГАВ)", KindEmergency.Label())
+	assert.Equal(t, "Не визначено", Kind(99).Label())
+}
@@ -16,6 +16,11 @@ func NewPeriod(startDate, endDate time.Time) (Period, error) {
 	return Period{StartDate: startDate, EndDate: endDate}, nil
 }
 
+// Overdue reports whether the planned end has passed by now.
+func (p Period) Overdue(now time.Time) bool {
+	return p.EndDate.Before(now)
+}
+
 // Equals checks if two Period values are equal by comparing Unix timestamps.
 func (p Period) Equals(other Period) bool {
 	return p.StartDate.Unix() == other.StartDate.Unix() &&
@@ -107,3 +107,10 @@ func TestOutagesEqual_ReorderedBuildings_False(t *testing.T) {
 	b := []*Outage{makeTestOutage(1, "Стрийська", []string{"10", "14", "12"}, ot0, ot1, "c")}
 	assert.False(t, OutagesEqual(a, b))
 }
+
+func TestOutagesEqual_DifferentKind_False(t *testing.T) {
+	a := []*Outage{makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c")}
+	b := []*Outage{makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c")}
+	b[0].Kind = KindEmergency
+	assert.False(t, OutagesEqual(a, b))
+}
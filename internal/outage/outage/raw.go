@@ -20,4 +20,7 @@ type RawOutage struct {
 	StreetName string
 	Buildings  []string
 	Comment    string
+	TypeOff    int
+	SubReason  int
+	OTG        string
 }
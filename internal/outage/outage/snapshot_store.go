@@ -1,7 +1,12 @@
 package outage
 
+// SnapshotReader reads the outages saved by the last notifier run.
+type SnapshotReader interface {
+	Load() ([]*Outage, error)
+}
+
 // SnapshotStore stores normalized outages for deduplication.
 type SnapshotStore interface {
-	Load() ([]*Outage, error)
+	SnapshotReader
 	Save(outages []*Outage) error
 }
@@ -0,0 +1,184 @@
+package outage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// StatsGrouping selects what outage statistics are aggregated by.
+type StatsGrouping int
+
+const (
+	// StatsByStreet aggregates outages per street.
+	StatsByStreet StatsGrouping = iota
+	// StatsByBuilding aggregates outages per building; an outage counts once for every building it lists.
+	StatsByBuilding
+)
+
+// ParseStatsGrouping parses "street" or "building".
+func ParseStatsGrouping(s string) (StatsGrouping, bool) {
+	switch s {
+	case "street":
+		return StatsByStreet, true
+	case "building":
+		return StatsByBuilding, true
+	}
+	return 0, false
+}
+
+// Stats aggregates archived outages of one street or building.
+type Stats struct {
+	City       string
+	StreetID   int
+	StreetName string
+	// Building is empty when grouping by street.
+	Building string
+	Outages  int
+	// Duration is the total outage time within the report period.
+	Duration time.Duration
+	// Resolved counts outages that are gone from the feed; only they have a known restoration time.
+	Resolved int
+	// Late counts resolved outages still listed after the end time originally planned for them.
+	Late int
+}
+
+// AverageDuration returns the mean outage duration, or zero without outages.
+func (s Stats) AverageDuration() time.Duration {
+	if s.Outages == 0 {
+		return 0
+	}
+	return s.Duration / time.Duration(s.Outages)
+}
+
+// LateShare returns the fraction of resolved outages restored later than planned.
+func (s Stats) LateShare() float64 {
+	if s.Resolved == 0 {
+		return 0
+	}
+	return float64(s.Late) / float64(s.Resolved)
+}
+
+// ComputeStats aggregates the entries matching filter. Durations run from the start of the
+// latest period to the resolution time, or to now while the outage is still listed, and are
+// clipped to the filter's date range. The feed may split one outage of a street across several
+// rows, so entries of a group sharing a period and comment count as one outage. Outages
+// resolved before they started are treated as cancelled and skipped. Results are ordered by
+// street name, then building.
+func ComputeStats(entries []*HistoryEntry, filter HistoryFilter, by StatsGrouping, now time.Time) []Stats {
+	groups := make(map[string]*Stats)
+	outages := make(map[string]map[string]*statsOutage)
+	var keys []string
+
+	for _, e := range entries {
+		if !filter.Matches(e) {
+			continue
+		}
+		duration, ok := e.duration(filter.From, filter.To, now)
+		if !ok {
+			continue
+		}
+		occurrence := statsOutage{
+			duration: duration,
+			resolved: e.Resolved(),
+			late:     e.Resolved() && e.LastSeen.After(e.plannedEnd()),
+		}
+		period := e.Outage.Period
+		outageKey := fmt.Sprintf("%d|%d|%s", period.StartDate.Unix(), period.EndDate.Unix(), e.Outage.Description.Value)
+
+		addr := e.Outage.Address
+		buildings := []string{""}
+		if by == StatsByBuilding {
+			buildings = addr.Buildings
+			if filter.Building != "" {
+				buildings = []string{filter.Building}
+			}
+		}
+		for _, building := range buildings {
+			key := fmt.Sprintf("%s|%d|%s", addr.City, addr.StreetID, building)
+			if groups[key] == nil {
+				groups[key] = &Stats{City: addr.City, StreetID: addr.StreetID, StreetName: addr.StreetName, Building: building}
+				outages[key] = make(map[string]*statsOutage)
+				keys = append(keys, key)
+			}
+			if o := outages[key][outageKey]; o != nil {
+				o.merge(occurrence)
+				continue
+			}
+			o := occurrence
+			outages[key][outageKey] = &o
+		}
+	}
+
+	stats := make([]Stats, 0, len(keys))
+	for _, key := range keys {
+		s := *groups[key]
+		for _, o := range outages[key] {
+			s.Outages++
+			s.Duration += o.duration
+			if o.resolved {
+				s.Resolved++
+			}
+			if o.late {
+				s.Late++
+			}
+		}
+		stats = append(stats, s)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].StreetName != stats[j].StreetName {
+			return stats[i].StreetName < stats[j].StreetName
+		}
+		return stats[i].Building < stats[j].Building
+	})
+	return stats
+}
+
+// statsOutage is one outage of a stats group, possibly made of several feed rows.
+type statsOutage struct {
+	duration time.Duration
+	resolved bool
+	late     bool
+}
+
+// merge folds another row of the same outage in: the outage lasts as long as its longest row
+// and is resolved only once every row is gone.
+func (o *statsOutage) merge(other statsOutage) {
+	o.duration = max(o.duration, other.duration)
+	o.resolved = o.resolved && other.resolved
+	o.late = o.resolved && (o.late || other.late)
+}
+
+// plannedEnd returns the end time originally announced for the outage.
+func (e *HistoryEntry) plannedEnd() time.Time {
+	if len(e.Periods) == 0 {
+		return e.Outage.Period.EndDate
+	}
+	return e.Periods[0].EndDate
+}
+
+// duration returns how long the outage lasted within [from, to); zero bounds are open.
+// An outage still listed is counted until now, or its planned end if that is earlier.
+// It reports false for outages resolved before they started.
+func (e *HistoryEntry) duration(from, to, now time.Time) (time.Duration, bool) {
+	start := e.Outage.Period.StartDate
+	end := e.Outage.Period.EndDate
+	if e.Resolved() {
+		if !e.ResolvedAt.After(start) {
+			return 0, false
+		}
+		end = e.ResolvedAt
+	} else if !now.IsZero() && end.After(now) {
+		end = now
+	}
+	if !from.IsZero() && start.Before(from) {
+		start = from
+	}
+	if !to.IsZero() && end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0, true
+	}
+	return end.Sub(start), true
+}
@@ -0,0 +1,109 @@
+package outage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeEntry(o *Outage, resolvedAt, lastSeen time.Time) *HistoryEntry {
+	return &HistoryEntry{
+		Outage:     o,
+		FirstSeen:  o.Period.StartDate.Add(-time.Hour),
+		LastSeen:   lastSeen,
+		Periods:    []Period{o.Period},
+		ResolvedAt: resolvedAt,
+	}
+}
+
+func TestComputeStats_ByStreet(t *testing.T) {
+	// ot0..ot1 is an 8-hour outage.
+	onTime := makeEntry(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), ot1.Add(-time.Hour), ot1.Add(-2*time.Hour))
+	late := makeEntry(makeTestOutage(1, "Стрийська", []string{"12"}, ot0.AddDate(0, 0, 1), ot1.AddDate(0, 0, 1), "c"),
+		ot1.AddDate(0, 0, 1).Add(2*time.Hour), ot1.AddDate(0, 0, 1).Add(time.Hour))
+	other := makeEntry(makeTestOutage(2, "Наукова", []string{"5"}, ot0, ot1, "c"), time.Time{}, ot0)
+
+	stats := ComputeStats([]*HistoryEntry{onTime, late, other}, HistoryFilter{}, StatsByStreet, ot1)
+	require.Len(t, stats, 2)
+
+	assert.Equal(t, "Наукова", stats[0].StreetName)
+	assert.Equal(t, 1, stats[0].Outages)
+	assert.Equal(t, 8*time.Hour, stats[0].Duration, "active outage counted until its planned end")
+	assert.Equal(t, 0, stats[0].Resolved)
+
+	s := stats[1]
+	assert.Equal(t, "Стрийська", s.StreetName)
+	assert.Empty(t, s.Building)
+	assert.Equal(t, 2, s.Outages)
+	assert.Equal(t, 17*time.Hour, s.Duration)
+	assert.Equal(t, 8*time.Hour+30*time.Minute, s.AverageDuration())
+	assert.Equal(t, 2, s.Resolved)
+	assert.Equal(t, 1, s.Late)
+	assert.InDelta(t, 0.5, s.LateShare(), 1e-9)
+}
+
+func TestComputeStats_ByBuilding(t *testing.T) {
+	e := makeEntry(makeTestOutage(1, "Стрийська", []string{"10", "12"}, ot0, ot1, "c"), ot1, ot1)
+
+	stats := ComputeStats([]*HistoryEntry{e}, HistoryFilter{}, StatsByBuilding, ot1)
+	require.Len(t, stats, 2)
+	assert.Equal(t, "10", stats[0].Building)
+	assert.Equal(t, "12", stats[1].Building)
+	assert.Equal(t, 8*time.Hour, stats[1].Duration)
+
+	stats = ComputeStats([]*HistoryEntry{e}, HistoryFilter{Building: "12"}, StatsByBuilding, ot1)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "12", stats[0].Building)
+}
+
+func TestComputeStats_ClipsToRangeAndSkipsCancelled(t *testing.T) {
+	e := makeEntry(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), ot1, ot1)
+	cancelled := makeEntry(makeTestOutage(1, "Стрийська", []string{"10"}, ot0.Add(time.Hour), ot1, "c"), ot0, ot0)
+
+	stats := ComputeStats([]*HistoryEntry{e, cancelled}, HistoryFilter{From: ot0.Add(6 * time.Hour)}, StatsByStreet, ot1)
+	require.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].Outages)
+	assert.Equal(t, 2*time.Hour, stats[0].Duration)
+}
+
+func TestComputeStats_CountsSplitRowsOnce(t *testing.T) {
+	// The feed lists one outage of a street in several rows, 10 in two of them.
+	first := makeEntry(makeTestOutage(1, "Стрийська", []string{"10", "12"}, ot0, ot1, "c"), ot1, ot1.Add(time.Hour))
+	second := makeEntry(makeTestOutage(1, "Стрийська", []string{"10", "14"}, ot0, ot1, "c"), ot1.Add(-time.Hour), ot1.Add(-time.Hour))
+	other := makeEntry(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "інше"), ot1, ot1)
+
+	stats := ComputeStats([]*HistoryEntry{first, second, other}, HistoryFilter{}, StatsByStreet, ot1)
+	require.Len(t, stats, 1)
+	assert.Equal(t, 2, stats[0].Outages, "rows sharing a period and comment are one outage")
+	assert.Equal(t, 16*time.Hour, stats[0].Duration)
+	assert.Equal(t, 2, stats[0].Resolved)
+	assert.Equal(t, 1, stats[0].Late)
+
+	stats = ComputeStats([]*HistoryEntry{first, second}, HistoryFilter{Building: "10"}, StatsByBuilding, ot1)
+	require.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].Outages)
+	assert.Equal(t, 8*time.Hour, stats[0].Duration)
+	assert.Equal(t, 1, stats[0].Late)
+}
+
+func TestComputeStats_OpenOutageCountedUntilNow(t *testing.T) {
+	e := makeEntry(makeTestOutage(1, "Стрийська", []string{"10"}, ot0, ot1, "c"), time.Time{}, ot0.Add(time.Hour))
+
+	stats := ComputeStats([]*HistoryEntry{e}, HistoryFilter{}, StatsByStreet, ot0.Add(3*time.Hour))
+	require.Len(t, stats, 1)
+	assert.Equal(t, 3*time.Hour, stats[0].Duration, "the planned end is still ahead")
+
+	stats = ComputeStats([]*HistoryEntry{e}, HistoryFilter{To: ot0.Add(2 * time.Hour)}, StatsByStreet, ot0.Add(3*time.Hour))
+	require.Len(t, stats, 1)
+	assert.Equal(t, 2*time.Hour, stats[0].Duration, "the report ends before now")
+}
+
+func TestParseStatsGrouping(t *testing.T) {
+	by, ok := ParseStatsGrouping("building")
+	assert.True(t, ok)
+	assert.Equal(t, StatsByBuilding, by)
+	_, ok = ParseStatsGrouping("city")
+	assert.False(t, ok)
+}
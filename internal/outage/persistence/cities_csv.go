@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+)
+
+const CitiesFileName = "cities.csv"
+
+// CityConfig describes a served city: its LOE API identifiers and street catalog.
+type CityConfig struct {
+	ID          int
+	OTGID       int
+	Name        string
+	StreetsFile string
+}
+
+// City returns the domain city for this configuration.
+func (c CityConfig) City() users.City {
+	return users.City{ID: c.ID, Name: c.Name}
+}
+
+// LoadCities reads the city catalog with columns id,otg_id,name,streets_file.
+// Relative streets_file paths are resolved against the catalog's directory.
+// Returns (nil, nil) when the file does not exist.
+func LoadCities(path string) ([]CityConfig, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cities file: %w", err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cities file: %w", err)
+	}
+	if len(records) < 1 {
+		return nil, nil
+	}
+
+	dir := filepath.Dir(path)
+	cities := make([]CityConfig, 0, len(records)-1)
+	for _, record := range records[1:] {
+		if len(record) < 4 {
+			return nil, fmt.Errorf("invalid cities row %q: expected 4 columns", strings.Join(record, ","))
+		}
+		id, err := strconv.Atoi(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid city id %q: %w", record[0], err)
+		}
+		otgID, err := strconv.Atoi(record[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid otg id %q: %w", record[1], err)
+		}
+		streetsFile := record[3]
+		if !filepath.IsAbs(streetsFile) {
+			streetsFile = filepath.Join(dir, streetsFile)
+		}
+		cities = append(cities, CityConfig{ID: id, OTGID: otgID, Name: record[2], StreetsFile: streetsFile})
+	}
+	return cities, nil
+}
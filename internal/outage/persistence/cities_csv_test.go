@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCities_ParsesRowsAndResolvesStreetFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, CitiesFileName)
+	content := "id,otg_id,name,streets_file\n693,28,Львів,streets.csv\n700,28,Винники,/abs/vynnyky.csv\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	cities, err := LoadCities(path)
+	require.NoError(t, err)
+	require.Len(t, cities, 2)
+	assert.Equal(t, CityConfig{ID: 693, OTGID: 28, Name: "Львів", StreetsFile: filepath.Join(dir, "streets.csv")}, cities[0])
+	assert.Equal(t, "/abs/vynnyky.csv", cities[1].StreetsFile)
+	assert.Equal(t, 700, cities[1].City().ID)
+}
+
+func TestLoadCities_MissingFileReturnsNil(t *testing.T) {
+	cities, err := LoadCities(filepath.Join(t.TempDir(), CitiesFileName))
+	require.NoError(t, err)
+	assert.Nil(t, cities)
+}
+
+func TestLoadCities_InvalidRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"short row", "id,otg_id,name\n693,28,Львів\n", "expected 4 columns"},
+		{"ragged row", "id,otg_id,name,streets_file\n693,28,Львів\n", "failed to parse cities file"},
+		{"bad id", "id,otg_id,name,streets_file\nx,28,Львів,streets.csv\n", "invalid city id"},
+		{"bad otg", "id,otg_id,name,streets_file\n693,x,Львів,streets.csv\n", "invalid otg id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), CitiesFileName)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			_, err := LoadCities(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+)
+
+const OutageHistoryFileName = "outage-history.csv"
+
+// historyOutageColumns are the outage columns of the archive, named as in the snapshot. Rows are
+// appended to archives written by earlier versions, so the list is fixed: otg is left out on
+// purpose, as the archive header cannot grow and statistics do not use it.
+var historyOutageColumns = []string{"start", "end", "city", "street_id", "street_name", "buildings", "comment", "kind", "id"}
+
+var historyHeader = append([]string{"at", "event", "last_seen"}, historyOutageColumns...)
+
+// FileOutageHistory is an append-only CSV archive of every outage ever seen.
+// Rows are only ever appended; the time of the latest fetch is kept in a
+// small companion file next to the archive.
+type FileOutageHistory struct {
+	path string
+}
+
+// NewFileOutageHistory creates a FileOutageHistory that appends to the archive at path.
+func NewFileOutageHistory(path string) *FileOutageHistory {
+	return &FileOutageHistory{path: path}
+}
+
+// Record appends one row per new, revised and resolved outage and remembers at as the latest fetch time.
+func (h *FileOutageHistory) Record(at time.Time, changes outage.Changes) error {
+	lastChecked, err := h.lastChecked()
+	if err != nil {
+		return err
+	}
+	if lastChecked.IsZero() {
+		lastChecked = at
+	}
+
+	if events := outage.HistoryEvents(changes, at, lastChecked); len(events) > 0 {
+		if err := h.append(events); err != nil {
+			return err
+		}
+	}
+
+	tmpPath := h.checkedPath() + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(at.UTC().Format(time.RFC3339)+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write temp history checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpPath, h.checkedPath()); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename history checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Entries reads the archive and folds it into one entry per outage.
+// Returns nil when nothing was archived yet.
+func (h *FileOutageHistory) Entries() ([]*outage.HistoryEntry, error) {
+	events, err := h.events()
+	if err != nil {
+		return nil, err
+	}
+	lastChecked, err := h.lastChecked()
+	if err != nil {
+		return nil, err
+	}
+	return outage.BuildHistory(events, lastChecked), nil
+}
+
+func (h *FileOutageHistory) append(events []outage.HistoryEvent) error {
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open outage history: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat outage history: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if info.Size() == 0 {
+		if err := writer.Write(historyHeader); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+	}
+	for _, ev := range events {
+		lastSeen := ""
+		if !ev.LastSeen.IsZero() {
+			lastSeen = ev.LastSeen.UTC().Format(time.RFC3339)
+		}
+		row := append([]string{ev.At.UTC().Format(time.RFC3339), string(ev.Type), lastSeen}, historyOutageRow(ev.Outage)...)
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to marshal outage history: %w", err)
+	}
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to append outage history: %w", err)
+	}
+	return nil
+}
+
+// historyOutageRow picks the archived columns out of the snapshot row of o.
+func historyOutageRow(o *outage.Outage) []string {
+	values := make(map[string]string, len(snapshotHeader))
+	for i, v := range outageRow(o) {
+		values[snapshotHeader[i]] = v
+	}
+	row := make([]string, 0, len(historyOutageColumns))
+	for _, name := range historyOutageColumns {
+		row = append(row, values[name])
+	}
+	return row
+}
+
+func (h *FileOutageHistory) events() ([]outage.HistoryEvent, error) {
+	data, err := os.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outage history: %w", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse outage history: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	columns, err := csvColumns(records[0], historyHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]outage.HistoryEvent, 0, len(records)-1)
+	for _, row := range records[1:] {
+		if len(row) != len(columns) {
+			return nil, fmt.Errorf("unexpected column count %d", len(row))
+		}
+		col := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return row[i]
+			}
+			return ""
+		}
+
+		at, err := time.Parse(time.RFC3339, col("at"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse event time: %w", err)
+		}
+		ev := outage.HistoryEvent{Type: outage.HistoryEventType(col("event")), At: at}
+		switch ev.Type {
+		case outage.HistorySeen, outage.HistoryRevised, outage.HistoryResolved:
+		default:
+			return nil, fmt.Errorf("unknown history event %q", col("event"))
+		}
+		if v := col("last_seen"); v != "" {
+			if ev.LastSeen, err = time.Parse(time.RFC3339, v); err != nil {
+				return nil, fmt.Errorf("failed to parse last_seen: %w", err)
+			}
+		}
+		if ev.Outage, err = parseOutageRow(col); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+func (h *FileOutageHistory) lastChecked() (time.Time, error) {
+	data, err := os.ReadFile(h.checkedPath())
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read history checkpoint: %w", err)
+	}
+	at, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse history checkpoint: %w", err)
+	}
+	return at, nil
+}
+
+func (h *FileOutageHistory) checkedPath() string {
+	return strings.TrimSuffix(h.path, ".csv") + ".checked"
+}
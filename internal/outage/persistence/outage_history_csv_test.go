@@ -0,0 +1,87 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileOutageHistory_Entries_MissingFile_ReturnsNil(t *testing.T) {
+	history := NewFileOutageHistory(filepath.Join(t.TempDir(), OutageHistoryFileName))
+
+	entries, err := history.Entries()
+	require.NoError(t, err)
+	assert.Nil(t, entries)
+}
+
+func TestFileOutageHistory_RecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), OutageHistoryFileName)
+	history := NewFileOutageHistory(path)
+
+	o := makeOutage(1, "Стрийська", []string{"10", "12"}, t0, t1, "test")
+	o.ID = 42
+	o.Kind = outage.KindPlanned
+	o.OTG = "Львівська"
+	extended := makeOutage(1, "Стрийська", []string{"10", "12"}, t0, t1.Add(time.Hour), "test")
+	extended.ID = 42
+
+	seenAt := t0.Add(-2 * time.Hour)
+	require.NoError(t, history.Record(seenAt, outage.Changes{New: []*outage.Outage{o}}))
+	require.NoError(t, history.Record(seenAt.Add(time.Minute), outage.Changes{}))
+	require.NoError(t, history.Record(t0, outage.Changes{Changed: []*outage.Outage{extended}}))
+	lastSeen := t1.Add(30 * time.Minute)
+	require.NoError(t, history.Record(lastSeen, outage.Changes{}))
+	resolvedAt := t1.Add(31 * time.Minute)
+	require.NoError(t, history.Record(resolvedAt, outage.Changes{Resolved: []*outage.Outage{extended}}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 4, "header plus one row per event")
+	assert.Equal(t, "at,event,last_seen,start,end,city,street_id,street_name,buildings,comment,kind,id", lines[0])
+
+	entries, err := history.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	e := entries[0]
+	assert.Equal(t, 42, e.Outage.ID)
+	assert.Equal(t, []string{"10", "12"}, e.Outage.Address.Buildings)
+	assert.Empty(t, e.Outage.OTG, "the OTG is not archived")
+	assert.Equal(t, seenAt.Unix(), e.FirstSeen.Unix())
+	assert.Equal(t, lastSeen.Unix(), e.LastSeen.Unix())
+	assert.Equal(t, resolvedAt.Unix(), e.ResolvedAt.Unix())
+	require.Len(t, e.Periods, 2)
+	assert.Equal(t, t1.Add(time.Hour).Unix(), e.Periods[1].EndDate.Unix())
+}
+
+func TestFileOutageHistory_ActiveOutageLastSeenAtLatestCheck(t *testing.T) {
+	history := NewFileOutageHistory(filepath.Join(t.TempDir(), OutageHistoryFileName))
+	o := makeOutage(1, "Стрийська", []string{"10"}, t0, t1, "test")
+
+	require.NoError(t, history.Record(t0, outage.Changes{New: []*outage.Outage{o}}))
+	require.NoError(t, history.Record(t0.Add(time.Hour), outage.Changes{}))
+
+	entries, err := history.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.False(t, entries[0].Resolved())
+	assert.Equal(t, t0.Add(time.Hour).Unix(), entries[0].LastSeen.Unix())
+}
+
+func TestFileOutageHistory_UnknownEvent_ReturnsError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), OutageHistoryFileName)
+	content := "at,event,last_seen,start,end,city,street_id,street_name,buildings,comment,kind,id\n" +
+		"2024-01-01T08:00:00Z,moved,,2024-01-01T08:00:00Z,2024-01-01T16:00:00Z,Львів,1,Стрийська,10,test,,0\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	_, err := NewFileOutageHistory(path).Entries()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown history event")
+}
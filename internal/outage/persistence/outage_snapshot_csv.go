@@ -14,6 +14,12 @@ import (
 
 const OutageSnapshotFileName = "outages.csv"
 
+var snapshotHeader = []string{"start", "end", "city", "street_id", "street_name", "buildings", "comment", "kind", "id", "otg"}
+
+// requiredSnapshotColumns are present in every snapshot version; later columns are optional
+// so that files written before they were introduced still load.
+var requiredSnapshotColumns = snapshotHeader[:7]
+
 // FileOutageRepository persists outage data for deduplication as a CSV file.
 type FileOutageRepository struct {
 	path string
@@ -41,50 +47,31 @@ func (r *FileOutageRepository) Load() ([]*outage.Outage, error) {
 		return nil, fmt.Errorf("failed to parse outage data: %w", err)
 	}
 
-	// skip header row
 	if len(records) == 0 {
 		return nil, nil
 	}
+	columns, err := csvColumns(records[0], requiredSnapshotColumns)
+	if err != nil {
+		return nil, err
+	}
 	records = records[1:]
 
 	outages := make([]*outage.Outage, 0, len(records))
 	for _, row := range records {
-		if len(row) != 7 {
+		if len(row) != len(columns) {
 			return nil, fmt.Errorf("unexpected column count %d", len(row))
 		}
-		start, err := time.Parse(time.RFC3339, row[0])
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse start time: %w", err)
-		}
-		end, err := time.Parse(time.RFC3339, row[1])
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse end time: %w", err)
+		col := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return row[i]
+			}
+			return ""
 		}
-		city := row[2]
-		streetID, err := strconv.Atoi(row[3])
+		o, err := parseOutageRow(col)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse street_id: %w", err)
-		}
-		streetName := row[4]
-		var buildings []string
-		if row[5] != "" {
-			buildings = strings.Split(row[5], "|")
+			return nil, err
 		}
-		comment := row[6]
-
-		period, err := outage.NewPeriod(start, end)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse outage period: %w", err)
-		}
-		addr, err := outage.NewAddress(streetID, streetName, buildings, city)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse outage address: %w", err)
-		}
-		outages = append(outages, &outage.Outage{
-			Period:      period,
-			Address:     addr,
-			Description: outage.NewDescription(comment),
-		})
+		outages = append(outages, o)
 	}
 	return outages, nil
 }
@@ -94,20 +81,11 @@ func (r *FileOutageRepository) Save(outages []*outage.Outage) error {
 	var buf bytes.Buffer
 	writer := csv.NewWriter(&buf)
 
-	if err := writer.Write([]string{"start", "end", "city", "street_id", "street_name", "buildings", "comment"}); err != nil {
+	if err := writer.Write(snapshotHeader); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 	for _, o := range outages {
-		row := []string{
-			o.Period.StartDate.UTC().Format(time.RFC3339),
-			o.Period.EndDate.UTC().Format(time.RFC3339),
-			o.Address.City,
-			strconv.Itoa(o.Address.StreetID),
-			o.Address.StreetName,
-			strings.Join(o.Address.Buildings, "|"),
-			o.Description.Value,
-		}
-		if err := writer.Write(row); err != nil {
+		if err := writer.Write(outageRow(o)); err != nil {
 			return fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
@@ -126,3 +104,80 @@ func (r *FileOutageRepository) Save(outages []*outage.Outage) error {
 	}
 	return nil
 }
+
+// outageRow encodes an outage as the snapshot columns.
+func outageRow(o *outage.Outage) []string {
+	return []string{
+		o.Period.StartDate.UTC().Format(time.RFC3339),
+		o.Period.EndDate.UTC().Format(time.RFC3339),
+		o.Address.City,
+		strconv.Itoa(o.Address.StreetID),
+		o.Address.StreetName,
+		strings.Join(o.Address.Buildings, "|"),
+		o.Description.Value,
+		o.Kind.String(),
+		strconv.Itoa(o.ID),
+		o.OTG,
+	}
+}
+
+// parseOutageRow decodes an outage from the snapshot columns returned by col.
+func parseOutageRow(col func(name string) string) (*outage.Outage, error) {
+	start, err := time.Parse(time.RFC3339, col("start"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse start time: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, col("end"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse end time: %w", err)
+	}
+	city := col("city")
+	streetID, err := strconv.Atoi(col("street_id"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse street_id: %w", err)
+	}
+	streetName := col("street_name")
+	var buildings []string
+	if b := col("buildings"); b != "" {
+		buildings = strings.Split(b, "|")
+	}
+	comment := col("comment")
+	kind, _ := outage.ParseKind(col("kind"))
+	var id int
+	if v := col("id"); v != "" {
+		if id, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("failed to parse id: %w", err)
+		}
+	}
+
+	period, err := outage.NewPeriod(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse outage period: %w", err)
+	}
+	addr, err := outage.NewAddress(streetID, streetName, buildings, city)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse outage address: %w", err)
+	}
+	return &outage.Outage{
+		ID:          id,
+		Period:      period,
+		Address:     addr,
+		Description: outage.NewDescription(comment),
+		Kind:        kind,
+		OTG:         col("otg"),
+	}, nil
+}
+
+// csvColumns maps column names to their index and checks that the required ones are present.
+func csvColumns(header, required []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	return columns, nil
+}
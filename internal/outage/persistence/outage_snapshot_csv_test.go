@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
@@ -80,3 +81,70 @@ func TestFileOutageRepository_UTCNormalization(t *testing.T) {
 	assert.Equal(t, t0.Unix(), got[0].Period.StartDate.Unix())
 	assert.Equal(t, t1.Unix(), got[0].Period.EndDate.Unix())
 }
+
+func TestFileOutageRepository_SaveAndLoad_PreservesKind(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewFileOutageRepository(filepath.Join(dir, "snap.csv"))
+
+	o := makeOutage(1, "Стрийська", []string{"10"}, t0, t1, "c")
+	o.Kind = outage.KindEmergency
+	require.NoError(t, repo.Save([]*outage.Outage{o}))
+
+	got, err := repo.Load()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, outage.KindEmergency, got[0].Kind)
+}
+
+func TestFileOutageRepository_SaveAndLoad_PreservesOTG(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewFileOutageRepository(filepath.Join(dir, "snap.csv"))
+
+	o := makeOutage(1, "Стрийська", []string{"10"}, t0, t1, "c")
+	o.OTG = "Львівська"
+	require.NoError(t, repo.Save([]*outage.Outage{o}))
+
+	got, err := repo.Load()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "Львівська", got[0].OTG)
+}
+
+func TestFileOutageRepository_SaveAndLoad_PreservesID(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewFileOutageRepository(filepath.Join(dir, "snap.csv"))
+
+	o := makeOutage(1, "Стрийська", []string{"10"}, t0, t1, "c")
+	o.ID = 1234
+	require.NoError(t, repo.Save([]*outage.Outage{o}))
+
+	got, err := repo.Load()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 1234, got[0].ID)
+}
+
+func TestFileOutageRepository_Load_LegacyFileWithoutKind(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snap.csv")
+	legacy := "start,end,city,street_id,street_name,buildings,comment\n" +
+		"2024-01-01T08:00:00Z,2024-01-01T16:00:00Z,Львів,1,Стрийська,10|12,c\n"
+	require.NoError(t, os.WriteFile(path, []byte(legacy), 0o644))
+
+	got, err := NewFileOutageRepository(path).Load()
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, outage.KindUnknown, got[0].Kind)
+	assert.Zero(t, got[0].ID)
+	assert.Equal(t, []string{"10", "12"}, got[0].Address.Buildings)
+}
+
+func TestFileOutageRepository_Load_MissingRequiredColumn(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snap.csv")
+	require.NoError(t, os.WriteFile(path, []byte("start,end\n2024-01-01T08:00:00Z,2024-01-01T16:00:00Z\n"), 0o644))
+
+	_, err := NewFileOutageRepository(path).Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing column")
+}
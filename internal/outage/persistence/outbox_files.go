@@ -0,0 +1,324 @@
+package persistence
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/notifier"
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+const OutboxDirName = "outbox"
+
+const outboxExt = ".toml"
+
+// badOutboxExt is appended to malformed message files so that they are reported only once
+// and kept for inspection.
+const badOutboxExt = ".bad"
+
+type outboxFile struct {
+	UserID int64 `toml:"user_id"`
+	// QueuedAt and Seq keep the messages in the order they were queued.
+	QueuedAt string         `toml:"queued_at"`
+	Seq      int            `toml:"seq"`
+	Event    string         `toml:"event"`
+	Silent   bool           `toml:"silent,omitempty"`
+	Address  outboxAddress  `toml:"address"`
+	Outage   outboxOutage   `toml:"outage"`
+	Previous *outboxOutcome `toml:"previous,omitempty"`
+	// Items holds the notifications summed up by a catch-up message.
+	Items []outboxItem `toml:"items,omitempty"`
+	// Attempts and RetryAt are set once a delivery has failed.
+	Attempts int    `toml:"attempts,omitempty"`
+	RetryAt  string `toml:"retry_at,omitempty"`
+}
+
+type outboxAddress struct {
+	StreetID   int    `toml:"street_id"`
+	StreetName string `toml:"street_name"`
+	Building   string `toml:"building,omitempty"`
+	City       string `toml:"city,omitempty"`
+}
+
+type outboxOutage struct {
+	City       string   `toml:"city"`
+	StreetName string   `toml:"street_name"`
+	Buildings  []string `toml:"buildings"`
+	Start      string   `toml:"start"`
+	End        string   `toml:"end"`
+	Comment    string   `toml:"comment"`
+	Kind       string   `toml:"kind"`
+}
+
+// outboxItem is one of the notifications summed up by a catch-up message, about the
+// message's address.
+type outboxItem struct {
+	Event    string         `toml:"event"`
+	Outage   outboxOutage   `toml:"outage"`
+	Previous *outboxOutcome `toml:"previous,omitempty"`
+}
+
+// outboxOutcome is what the user was told before an update.
+type outboxOutcome struct {
+	End     string `toml:"end"`
+	Comment string `toml:"comment"`
+}
+
+// FileOutbox keeps queued notifications as individual TOML files named after their keys.
+type FileOutbox struct {
+	dir string
+	now func() time.Time
+}
+
+// NewFileOutbox creates a FileOutbox storing messages in dir.
+func NewFileOutbox(dir string) (*FileOutbox, error) {
+	if err := os.MkdirAll(dir, 0o770); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+	return &FileOutbox{dir: dir, now: time.Now}, nil
+}
+
+// Add writes a file for every message not queued yet, using atomic writes (temp file + rename).
+func (o *FileOutbox) Add(messages []notifier.Message) error {
+	queuedAt := o.now().UTC().Format(time.RFC3339Nano)
+	for seq, m := range messages {
+		path := o.path(m.Key)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := writeOutboxFile(path, encodeOutboxFile(m, queuedAt, seq)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending reads every queued message, oldest first. Malformed files are set aside with a warning.
+func (o *FileOutbox) Pending() ([]notifier.Message, error) {
+	entries, err := os.ReadDir(o.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox directory: %w", err)
+	}
+
+	type queued struct {
+		message  notifier.Message
+		queuedAt time.Time
+		seq      int
+	}
+	var all []queued
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != outboxExt {
+			continue
+		}
+		key := strings.TrimSuffix(name, outboxExt)
+		data, err := os.ReadFile(filepath.Join(o.dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read outbox message %s: %w", key, err)
+		}
+		var f outboxFile
+		if err := toml.Unmarshal(data, &f); err != nil {
+			o.setAside(name, err)
+			continue
+		}
+		m, queuedAt, err := decodeOutboxFile(f, key)
+		if err != nil {
+			o.setAside(name, err)
+			continue
+		}
+		all = append(all, queued{message: m, queuedAt: queuedAt, seq: f.Seq})
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if !all[i].queuedAt.Equal(all[j].queuedAt) {
+			return all[i].queuedAt.Before(all[j].queuedAt)
+		}
+		return all[i].seq < all[j].seq
+	})
+	messages := make([]notifier.Message, len(all))
+	for i, q := range all {
+		messages[i] = q.message
+	}
+	return messages, nil
+}
+
+// Postpone records the failed attempts and the retry time in the message's file, keeping its
+// place in the queue. A message already gone is not an error.
+func (o *FileOutbox) Postpone(key string, attempts int, retryAt time.Time) error {
+	path := o.path(key)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read outbox message %s: %w", key, err)
+	}
+	var f outboxFile
+	if err := toml.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("failed to parse outbox message %s: %w", key, err)
+	}
+	f.Attempts = attempts
+	f.RetryAt = retryAt.UTC().Format(time.RFC3339)
+	return writeOutboxFile(path, &f)
+}
+
+// Done deletes the message's file. A message already gone is not an error.
+func (o *FileOutbox) Done(key string) error {
+	if err := os.Remove(o.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove outbox message: %w", err)
+	}
+	return nil
+}
+
+// setAside renames the malformed message file name so that it is no longer read.
+func (o *FileOutbox) setAside(name string, cause error) {
+	path := filepath.Join(o.dir, name)
+	if err := os.Rename(path, path+badOutboxExt); err != nil {
+		log.Printf("WARNING: skipping malformed outbox message %s: %v (failed to set it aside: %v)", name, cause, err)
+		return
+	}
+	log.Printf("WARNING: malformed outbox message %s moved to %s: %v", name, name+badOutboxExt, cause)
+}
+
+func (o *FileOutbox) path(key string) string {
+	return filepath.Join(o.dir, key+outboxExt)
+}
+
+// writeOutboxFile stores f at path using an atomic write (temp file + rename).
+func writeOutboxFile(path string, f *outboxFile) error {
+	content, err := toml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox message: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write temp outbox message: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename outbox message: %w", err)
+	}
+	return nil
+}
+
+func encodeOutboxFile(m notifier.Message, queuedAt string, seq int) *outboxFile {
+	c := m.Content
+	f := &outboxFile{
+		UserID:   m.UserID,
+		QueuedAt: queuedAt,
+		Seq:      seq,
+		Event:    c.Event.String(),
+		Silent:   c.Silent,
+		Address: outboxAddress{
+			StreetID:   c.SavedAddress.StreetID,
+			StreetName: c.SavedAddress.StreetName,
+			Building:   c.SavedAddress.Building,
+			City:       c.SavedAddress.City,
+		},
+		Outage:   encodeOutboxOutage(c),
+		Previous: encodeOutboxOutcome(c),
+	}
+	for _, item := range c.Items {
+		f.Items = append(f.Items, outboxItem{Event: item.Event.String(), Outage: encodeOutboxOutage(item), Previous: encodeOutboxOutcome(item)})
+	}
+	return f
+}
+
+func encodeOutboxOutage(c notifier.Content) outboxOutage {
+	return outboxOutage{
+		City:       c.City,
+		StreetName: c.StreetName,
+		Buildings:  c.Buildings,
+		Start:      c.Start.Format(time.RFC3339),
+		End:        c.End.Format(time.RFC3339),
+		Comment:    c.Comment,
+		Kind:       c.Kind.String(),
+	}
+}
+
+func encodeOutboxOutcome(c notifier.Content) *outboxOutcome {
+	if c.Event != notifier.EventUpdate {
+		return nil
+	}
+	return &outboxOutcome{End: c.PreviousEnd.Format(time.RFC3339), Comment: c.PreviousComment}
+}
+
+func decodeOutboxFile(f outboxFile, key string) (notifier.Message, time.Time, error) {
+	queuedAt, err := time.Parse(time.RFC3339Nano, f.QueuedAt)
+	if err != nil {
+		return notifier.Message{}, time.Time{}, fmt.Errorf("invalid queued_at in outbox message %s: %w", key, err)
+	}
+	saved := users.Address{
+		StreetID:   f.Address.StreetID,
+		StreetName: f.Address.StreetName,
+		Building:   f.Address.Building,
+		City:       f.Address.City,
+	}
+	c, err := decodeOutboxContent(outboxItem{Event: f.Event, Outage: f.Outage, Previous: f.Previous}, saved, key)
+	if err != nil {
+		return notifier.Message{}, time.Time{}, err
+	}
+	c.Silent = f.Silent
+	for _, item := range f.Items {
+		itemContent, err := decodeOutboxContent(item, saved, key)
+		if err != nil {
+			return notifier.Message{}, time.Time{}, err
+		}
+		c.Items = append(c.Items, itemContent)
+	}
+	m := notifier.Message{Key: key, UserID: f.UserID, Content: c, Attempts: f.Attempts}
+	if f.RetryAt != "" {
+		if m.RetryAt, err = time.Parse(time.RFC3339, f.RetryAt); err != nil {
+			return notifier.Message{}, time.Time{}, fmt.Errorf("invalid retry_at in outbox message %s: %w", key, err)
+		}
+	}
+	return m, queuedAt, nil
+}
+
+// decodeOutboxContent decodes the notification about the saved address described by item.
+func decodeOutboxContent(item outboxItem, saved users.Address, key string) (notifier.Content, error) {
+	event, ok := notifier.ParseEvent(item.Event)
+	if !ok {
+		return notifier.Content{}, fmt.Errorf("unknown event %q in outbox message %s", item.Event, key)
+	}
+	start, err := time.Parse(time.RFC3339, item.Outage.Start)
+	if err != nil {
+		return notifier.Content{}, fmt.Errorf("invalid start in outbox message %s: %w", key, err)
+	}
+	end, err := time.Parse(time.RFC3339, item.Outage.End)
+	if err != nil {
+		return notifier.Content{}, fmt.Errorf("invalid end in outbox message %s: %w", key, err)
+	}
+	// An unrecognised kind only loses the kind line of the message.
+	kind, _ := outage.ParseKind(item.Outage.Kind)
+
+	c := notifier.Content{
+		Event:        event,
+		SavedAddress: saved,
+		City:         item.Outage.City,
+		StreetName:   item.Outage.StreetName,
+		Buildings:    item.Outage.Buildings,
+		Start:        start,
+		End:          end,
+		Comment:      item.Outage.Comment,
+		Kind:         kind,
+	}
+	if item.Previous != nil {
+		if c.PreviousEnd, err = time.Parse(time.RFC3339, item.Previous.End); err != nil {
+			return notifier.Content{}, fmt.Errorf("invalid previous end in outbox message %s: %w", key, err)
+		}
+		c.PreviousComment = item.Previous.Comment
+	}
+	return c, nil
+}
@@ -0,0 +1,196 @@
+package persistence
+
+import (
+	"github.com/sl4wa/outages-bot/internal/outage/notifier"
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupOutbox(t *testing.T) (*FileOutbox, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), OutboxDirName)
+	outbox, err := NewFileOutbox(dir)
+	require.NoError(t, err)
+	return outbox, dir
+}
+
+func makeOutboxMessage(key string, userID int64, event notifier.Event) notifier.Message {
+	zone := time.FixedZone("EET", 2*60*60)
+	return notifier.Message{
+		Key:    key,
+		UserID: userID,
+		Content: notifier.Content{
+			Event:        event,
+			SavedAddress: users.Address{StreetID: 1, StreetName: "Стрийська", Building: "10", City: "Львів"},
+			City:         "Львів",
+			StreetName:   "Стрийська",
+			Buildings:    []string{"10", "12"},
+			Start:        time.Date(2024, 1, 1, 8, 0, 0, 0, zone),
+			End:          time.Date(2024, 1, 1, 16, 0, 0, 0, zone),
+			Comment:      "Планове відключення",
+			Kind:         outage.KindPlanned,
+		},
+	}
+}
+
+func TestFileOutbox_AddAndPending_RoundTrip(t *testing.T) {
+	outbox, _ := setupOutbox(t)
+	announced := makeOutboxMessage("100-a", 100, notifier.EventOutage)
+	announced.Content.Silent = true
+	updated := makeOutboxMessage("100-b", 100, notifier.EventUpdate)
+	updated.Content.PreviousEnd = updated.Content.End.Add(-2 * time.Hour)
+	updated.Content.PreviousComment = "було"
+	require.NoError(t, outbox.Add([]notifier.Message{announced, updated}))
+
+	pending, err := outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+
+	got := pending[0]
+	assert.Equal(t, "100-a", got.Key)
+	assert.Equal(t, int64(100), got.UserID)
+	assert.Equal(t, notifier.EventOutage, got.Content.Event)
+	assert.True(t, got.Content.Silent)
+	assert.Equal(t, announced.Content.SavedAddress, got.Content.SavedAddress)
+	assert.Equal(t, []string{"10", "12"}, got.Content.Buildings)
+	assert.True(t, announced.Content.Start.Equal(got.Content.Start))
+	assert.Equal(t, "16:00", got.Content.End.Format("15:04"), "the outage's time zone is kept")
+	assert.Equal(t, "Планове відключення", got.Content.Comment)
+	assert.Equal(t, outage.KindPlanned, got.Content.Kind)
+	assert.True(t, got.Content.PreviousEnd.IsZero())
+
+	got = pending[1]
+	assert.Equal(t, notifier.EventUpdate, got.Content.Event)
+	assert.True(t, updated.Content.PreviousEnd.Equal(got.Content.PreviousEnd))
+	assert.Equal(t, "було", got.Content.PreviousComment)
+}
+
+func TestFileOutbox_AddAndPending_CatchUpRoundTrip(t *testing.T) {
+	outbox, _ := setupOutbox(t)
+	announced := makeOutboxMessage("", 100, notifier.EventOutage).Content
+	updated := makeOutboxMessage("", 100, notifier.EventUpdate).Content
+	updated.PreviousEnd = updated.End.Add(-2 * time.Hour)
+	updated.PreviousComment = "було"
+	catchUp := notifier.Message{Key: "100-a", UserID: 100, Content: notifier.Content{
+		Event:        notifier.EventCatchUp,
+		SavedAddress: announced.SavedAddress,
+		Items:        []notifier.Content{announced, updated},
+	}}
+	require.NoError(t, outbox.Add([]notifier.Message{catchUp}))
+
+	pending, err := outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	got := pending[0].Content
+	assert.Equal(t, notifier.EventCatchUp, got.Event)
+	require.Len(t, got.Items, 2)
+	assert.Equal(t, notifier.EventOutage, got.Items[0].Event)
+	assert.Equal(t, announced.SavedAddress, got.Items[0].SavedAddress)
+	assert.Equal(t, []string{"10", "12"}, got.Items[0].Buildings)
+	assert.Equal(t, outage.KindPlanned, got.Items[0].Kind)
+	assert.Equal(t, notifier.EventUpdate, got.Items[1].Event)
+	assert.True(t, updated.PreviousEnd.Equal(got.Items[1].PreviousEnd))
+	assert.Equal(t, "було", got.Items[1].PreviousComment)
+}
+
+func TestFileOutbox_KeepsQueueOrderAcrossAdds(t *testing.T) {
+	outbox, _ := setupOutbox(t)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	outbox.now = func() time.Time { return now }
+	require.NoError(t, outbox.Add([]notifier.Message{
+		makeOutboxMessage("200-z", 200, notifier.EventOutage),
+		makeOutboxMessage("100-y", 100, notifier.EventOutage),
+	}))
+	now = now.Add(time.Minute)
+	require.NoError(t, outbox.Add([]notifier.Message{makeOutboxMessage("100-a", 100, notifier.EventRestored)}))
+
+	pending, err := outbox.Pending()
+	require.NoError(t, err)
+	keys := make([]string, len(pending))
+	for i, m := range pending {
+		keys[i] = m.Key
+	}
+	assert.Equal(t, []string{"200-z", "100-y", "100-a"}, keys)
+}
+
+func TestFileOutbox_AddSkipsQueuedKeys(t *testing.T) {
+	outbox, _ := setupOutbox(t)
+	require.NoError(t, outbox.Add([]notifier.Message{makeOutboxMessage("100-a", 100, notifier.EventOutage)}))
+
+	again := makeOutboxMessage("100-a", 100, notifier.EventOutage)
+	again.Content.Comment = "changed"
+	require.NoError(t, outbox.Add([]notifier.Message{again}))
+
+	pending, err := outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "Планове відключення", pending[0].Content.Comment)
+}
+
+func TestFileOutbox_Done(t *testing.T) {
+	outbox, dir := setupOutbox(t)
+	require.NoError(t, outbox.Add([]notifier.Message{makeOutboxMessage("100-a", 100, notifier.EventOutage)}))
+
+	require.NoError(t, outbox.Done("100-a"))
+	require.NoError(t, outbox.Done("100-a"), "already gone")
+
+	pending, err := outbox.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFileOutbox_Postpone_KeepsOrder(t *testing.T) {
+	outbox, _ := setupOutbox(t)
+	require.NoError(t, outbox.Add([]notifier.Message{
+		makeOutboxMessage("100-a", 100, notifier.EventOutage),
+		makeOutboxMessage("100-b", 100, notifier.EventRestored),
+	}))
+
+	retryAt := time.Date(2024, 1, 1, 12, 5, 0, 0, time.UTC)
+	require.NoError(t, outbox.Postpone("100-a", 2, retryAt))
+	require.NoError(t, outbox.Postpone("100-gone", 1, retryAt), "already gone")
+
+	pending, err := outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "100-a", pending[0].Key)
+	assert.Equal(t, 2, pending[0].Attempts)
+	assert.True(t, retryAt.Equal(pending[0].RetryAt))
+	assert.Equal(t, "Планове відключення", pending[0].Content.Comment)
+	assert.Zero(t, pending[1].Attempts)
+	assert.True(t, pending[1].RetryAt.IsZero())
+}
+
+func TestFileOutbox_SetsAsideMalformedFiles(t *testing.T) {
+	outbox, dir := setupOutbox(t)
+	require.NoError(t, outbox.Add([]notifier.Message{makeOutboxMessage("100-a", 100, notifier.EventOutage)}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "100-b.toml"), []byte("not = [valid"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "100-c.toml"), []byte("user_id = 100\nqueued_at = \"2024-01-01T00:00:00Z\"\nevent = \"bogus\"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o644))
+
+	pending, err := outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "100-a", pending[0].Key)
+
+	for _, name := range []string{"100-b.toml", "100-c.toml"} {
+		assert.NoFileExists(t, filepath.Join(dir, name))
+		assert.FileExists(t, filepath.Join(dir, name+".bad"), "kept for inspection")
+	}
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+
+	// Set-aside files are not read again.
+	pending, err = outbox.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+}
@@ -15,6 +15,27 @@ type FileStreetRepository struct {
 
 // NewFileStreetRepository creates a FileStreetRepository by loading streets from the given file path.
 func NewFileStreetRepository(filePath string) (*FileStreetRepository, error) {
+	streets, err := loadStreets(filePath, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStreetRepository{streets: streets}, nil
+}
+
+// NewCityStreetRepository loads the street catalog of every city, tagging each street with its city ID.
+func NewCityStreetRepository(cities []CityConfig) (*FileStreetRepository, error) {
+	var all []users.Street
+	for _, city := range cities {
+		streets, err := loadStreets(city.StreetsFile, city.ID)
+		if err != nil {
+			return nil, fmt.Errorf("city %s: %w", city.Name, err)
+		}
+		all = append(all, streets...)
+	}
+	return &FileStreetRepository{streets: all}, nil
+}
+
+func loadStreets(filePath string, cityID int) ([]users.Street, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open streets file: %w", err)
@@ -28,7 +49,7 @@ func NewFileStreetRepository(filePath string) (*FileStreetRepository, error) {
 	}
 
 	if len(records) < 1 {
-		return &FileStreetRepository{}, nil
+		return nil, nil
 	}
 
 	streets := make([]users.Street, 0, len(records)-1)
@@ -40,10 +61,10 @@ func NewFileStreetRepository(filePath string) (*FileStreetRepository, error) {
 		if err != nil {
 			return nil, fmt.Errorf("invalid street id %q: %w", record[0], err)
 		}
-		streets = append(streets, users.Street{ID: id, Name: record[1]})
+		streets = append(streets, users.Street{ID: id, Name: record[1], CityID: cityID})
 	}
 
-	return &FileStreetRepository{streets: streets}, nil
+	return streets, nil
 }
 
 // GetAllStreets returns all loaded streets.
@@ -67,3 +67,27 @@ func TestFileStreetRepository_EmptyFile(t *testing.T) {
 	streets := repo.GetAllStreets()
 	assert.Empty(t, streets)
 }
+
+func TestNewCityStreetRepository_TagsStreetsWithCity(t *testing.T) {
+	dir := t.TempDir()
+	suburb := filepath.Join(dir, "vynnyky.csv")
+	require.NoError(t, os.WriteFile(suburb, []byte("id,name\n50001,Галицька\n"), 0o644))
+
+	repo, err := NewCityStreetRepository([]CityConfig{
+		{ID: 693, Name: "Львів", StreetsFile: "testdata/streets.csv"},
+		{ID: 700, Name: "Винники", StreetsFile: suburb},
+	})
+	require.NoError(t, err)
+
+	streets := repo.GetAllStreets()
+	require.Len(t, streets, 3)
+	assert.Equal(t, 693, streets[0].CityID)
+	assert.Equal(t, "Галицька", streets[2].Name)
+	assert.Equal(t, 700, streets[2].CityID)
+}
+
+func TestNewCityStreetRepository_MissingCatalog(t *testing.T) {
+	_, err := NewCityStreetRepository([]CityConfig{{ID: 700, Name: "Винники", StreetsFile: "nonexistent.csv"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Винники")
+}
@@ -15,12 +15,50 @@ import (
 )
 
 type userFile struct {
-	StreetID   int    `toml:"street_id"`
-	StreetName string `toml:"street_name"`
-	Building   string `toml:"building"`
-	StartDate  string `toml:"start_date,omitempty"`
-	EndDate    string `toml:"end_date,omitempty"`
-	Comment    string `toml:"comment,omitempty"`
+	Kinds      []string        `toml:"kinds,omitempty"`
+	QuietHours *quietHoursFile `toml:"quiet_hours,omitempty"`
+	Addresses  []addressFile   `toml:"addresses,omitempty"`
+
+	// Files written before multiple addresses were supported keep a single
+	// address at the top level. They are read as the only address and
+	// rewritten in the current layout on the next save.
+	addressFile
+}
+
+type addressFile struct {
+	StreetID   int          `toml:"street_id,omitempty"`
+	StreetName string       `toml:"street_name,omitempty"`
+	Building   string       `toml:"building,omitempty"`
+	City       string       `toml:"city,omitempty"`
+	Outages    []outageFile `toml:"outages,omitempty"`
+
+	// WholeStreet marks an address without a building that covers the whole street.
+	WholeStreet bool `toml:"whole_street,omitempty"`
+	// CatchUp marks notifications held back during quiet hours.
+	CatchUp bool `toml:"catch_up,omitempty"`
+
+	// Files written before several outages per address were tracked keep the
+	// single notified outage inline. It is read as the only notified outage.
+	outageFile
+}
+
+type outageFile struct {
+	StartDate string `toml:"start_date,omitempty"`
+	EndDate   string `toml:"end_date,omitempty"`
+	Comment   string `toml:"comment,omitempty"`
+	OutageID  int    `toml:"outage_id,omitempty"`
+	// Reminded marks that the pre-start reminder for the outage was sent.
+	Reminded bool `toml:"reminded,omitempty"`
+	// OverdueAlerted marks that the user was told restoration is overdue for the stored end date.
+	OverdueAlerted bool `toml:"overdue_alerted,omitempty"`
+	// Buildings lists the buildings the user was told about; kept for whole-street addresses.
+	Buildings []string `toml:"buildings,omitempty"`
+}
+
+type quietHoursFile struct {
+	Start int    `toml:"start"`
+	End   int    `toml:"end"`
+	Mode  string `toml:"mode"`
 }
 
 // FileUserRepository persists users as individual TOML files.
@@ -70,16 +108,36 @@ func (r *FileUserRepository) Find(chatID int64) (*users.User, error) {
 
 // Save persists a user to disk as TOML using atomic write (temp file + rename).
 func (r *FileUserRepository) Save(user *users.User) error {
-	uf := userFile{
-		StreetID:   user.Address.StreetID,
-		StreetName: user.Address.StreetName,
-		Building:   user.Address.Building,
+	var uf userFile
+	for _, saved := range user.Addresses {
+		af := addressFile{
+			StreetID:    saved.Address.StreetID,
+			StreetName:  saved.Address.StreetName,
+			Building:    saved.Address.Building,
+			WholeStreet: saved.Address.WholeStreet(),
+			City:        saved.Address.City,
+			CatchUp:     saved.CatchUp,
+		}
+		for _, info := range saved.Notified {
+			af.Outages = append(af.Outages, outageFile{
+				StartDate:      info.Period.StartDate.Format(time.RFC3339),
+				EndDate:        info.Period.EndDate.Format(time.RFC3339),
+				Comment:        info.Description.Value,
+				OutageID:       info.OutageID,
+				Reminded:       info.Reminded,
+				OverdueAlerted: info.OverdueAlerted,
+				Buildings:      info.Buildings,
+			})
+		}
+		uf.Addresses = append(uf.Addresses, af)
 	}
 
-	if user.OutageInfo != nil {
-		uf.StartDate = user.OutageInfo.Period.StartDate.Format(time.RFC3339)
-		uf.EndDate = user.OutageInfo.Period.EndDate.Format(time.RFC3339)
-		uf.Comment = user.OutageInfo.Description.Value
+	for _, k := range user.Kinds {
+		uf.Kinds = append(uf.Kinds, k.String())
+	}
+
+	if q := user.QuietHours; q != nil {
+		uf.QuietHours = &quietHoursFile{Start: q.Start, End: q.End, Mode: q.Mode.String()}
 	}
 
 	content, err := toml.Marshal(&uf)
@@ -112,33 +170,95 @@ func (r *FileUserRepository) load(chatID int64) (*users.User, error) {
 }
 
 func decodeUserFile(uf userFile, id int64) (*users.User, error) {
-	addr, err := users.NewAddress(uf.StreetID, uf.StreetName, uf.Building)
-	if err != nil {
-		return nil, fmt.Errorf("invalid user address in %d: %w", id, err)
+	files := uf.Addresses
+	if len(files) == 0 {
+		files = []addressFile{uf.addressFile}
 	}
 
-	var outageInfo *users.OutageInfo
-	if uf.StartDate != "" && uf.EndDate != "" {
-		startDate, err := time.Parse(time.RFC3339, uf.StartDate)
+	addresses := make([]users.SavedAddress, 0, len(files))
+	for _, af := range files {
+		saved, err := decodeAddressFile(af, id)
 		if err != nil {
-			return nil, fmt.Errorf("invalid start_date in %d: %w", id, err)
+			return nil, err
 		}
-		endDate, err := time.Parse(time.RFC3339, uf.EndDate)
-		if err != nil {
-			return nil, fmt.Errorf("invalid end_date in %d: %w", id, err)
+		addresses = append(addresses, saved)
+	}
+
+	var kinds []outage.Kind
+	for _, code := range uf.Kinds {
+		kind, ok := outage.ParseKind(code)
+		if !ok {
+			return nil, fmt.Errorf("invalid kind %q in %d", code, id)
 		}
-		period, err := outage.NewPeriod(startDate, endDate)
+		kinds = append(kinds, kind)
+	}
+
+	var quietHours *users.QuietHours
+	if qf := uf.QuietHours; qf != nil {
+		mode, ok := users.ParseQuietMode(qf.Mode)
+		if !ok {
+			return nil, fmt.Errorf("invalid quiet hours mode %q in %d", qf.Mode, id)
+		}
+		q, err := users.NewQuietHours(qf.Start, qf.End, mode)
 		if err != nil {
-			return nil, fmt.Errorf("invalid outage period in %d: %w", id, err)
+			return nil, fmt.Errorf("invalid quiet hours in %d: %w", id, err)
 		}
-		desc := outage.NewDescription(uf.Comment)
-		info := users.NewOutageInfo(period, desc)
-		outageInfo = &info
+		quietHours = &q
 	}
 
 	return &users.User{
 		ID:         id,
-		Address:    addr,
-		OutageInfo: outageInfo,
+		Addresses:  addresses,
+		Kinds:      kinds,
+		QuietHours: quietHours,
 	}, nil
 }
+
+func decodeAddressFile(af addressFile, id int64) (users.SavedAddress, error) {
+	var addr users.Address
+	var err error
+	if af.WholeStreet {
+		addr, err = users.NewStreetAddress(af.StreetID, af.StreetName)
+	} else {
+		addr, err = users.NewAddress(af.StreetID, af.StreetName, af.Building)
+	}
+	if err != nil {
+		return users.SavedAddress{}, fmt.Errorf("invalid user address in %d: %w", id, err)
+	}
+	addr.City = af.City
+
+	saved := users.SavedAddress{Address: addr, CatchUp: af.CatchUp}
+	outages := af.Outages
+	if af.StartDate != "" && af.EndDate != "" {
+		outages = append(outages, af.outageFile)
+	}
+	for _, of := range outages {
+		info, err := decodeOutageFile(of, id)
+		if err != nil {
+			return users.SavedAddress{}, err
+		}
+		saved.Notified = append(saved.Notified, info)
+	}
+	return saved, nil
+}
+
+func decodeOutageFile(of outageFile, id int64) (users.OutageInfo, error) {
+	startDate, err := time.Parse(time.RFC3339, of.StartDate)
+	if err != nil {
+		return users.OutageInfo{}, fmt.Errorf("invalid start_date in %d: %w", id, err)
+	}
+	endDate, err := time.Parse(time.RFC3339, of.EndDate)
+	if err != nil {
+		return users.OutageInfo{}, fmt.Errorf("invalid end_date in %d: %w", id, err)
+	}
+	period, err := outage.NewPeriod(startDate, endDate)
+	if err != nil {
+		return users.OutageInfo{}, fmt.Errorf("invalid outage period in %d: %w", id, err)
+	}
+	info := users.NewOutageInfo(period, outage.NewDescription(of.Comment))
+	info.OutageID = of.OutageID
+	info.Reminded = of.Reminded
+	info.OverdueAlerted = of.OverdueAlerted
+	info.Buildings = of.Buildings
+	return info, nil
+}
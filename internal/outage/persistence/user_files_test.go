@@ -25,7 +25,7 @@ func makeTestUser(t *testing.T, id int64) *users.User {
 	t.Helper()
 	addr, err := users.NewAddress(1, "Стрийська", "10")
 	require.NoError(t, err)
-	return &users.User{ID: id, Address: addr}
+	return users.NewUser(id, addr)
 }
 
 func TestFileUserRepository_SaveAndFind(t *testing.T) {
@@ -39,10 +39,10 @@ func TestFileUserRepository_SaveAndFind(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, found)
 	assert.Equal(t, int64(12345), found.ID)
-	assert.Equal(t, "Стрийська", found.Address.StreetName)
-	assert.Equal(t, "10", found.Address.Building)
-	assert.Equal(t, 1, found.Address.StreetID)
-	assert.Nil(t, found.OutageInfo)
+	assert.Equal(t, "Стрийська", found.Addresses[0].Address.StreetName)
+	assert.Equal(t, "10", found.Addresses[0].Address.Building)
+	assert.Equal(t, 1, found.Addresses[0].Address.StreetID)
+	assert.Empty(t, found.Addresses[0].Notified)
 }
 
 func TestFileUserRepository_SaveWithOutageInfo(t *testing.T) {
@@ -53,7 +53,9 @@ func TestFileUserRepository_SaveWithOutageInfo(t *testing.T) {
 	period, _ := outage.NewPeriod(start, end)
 	desc := outage.NewDescription("Планове відключення")
 	info := users.NewOutageInfo(period, desc)
-	user := &users.User{ID: 12345, Address: addr, OutageInfo: &info}
+	info.OutageID = 987
+	info.Reminded = true
+	user := &users.User{ID: 12345, Addresses: []users.SavedAddress{{Address: addr, Notified: []users.OutageInfo{info}}}}
 
 	err := repo.Save(user)
 	require.NoError(t, err)
@@ -61,10 +63,41 @@ func TestFileUserRepository_SaveWithOutageInfo(t *testing.T) {
 	found, err := repo.Find(12345)
 	require.NoError(t, err)
 	require.NotNil(t, found)
-	require.NotNil(t, found.OutageInfo)
-	assert.Equal(t, start.Unix(), found.OutageInfo.Period.StartDate.Unix())
-	assert.Equal(t, end.Unix(), found.OutageInfo.Period.EndDate.Unix())
-	assert.Equal(t, "Планове відключення", found.OutageInfo.Description.Value)
+	require.Len(t, found.Addresses[0].Notified, 1)
+	assert.Equal(t, start.Unix(), found.Addresses[0].Notified[0].Period.StartDate.Unix())
+	assert.Equal(t, end.Unix(), found.Addresses[0].Notified[0].Period.EndDate.Unix())
+	assert.Equal(t, "Планове відключення", found.Addresses[0].Notified[0].Description.Value)
+	assert.Equal(t, 987, found.Addresses[0].Notified[0].OutageID)
+	assert.True(t, found.Addresses[0].Notified[0].Reminded)
+}
+
+func TestFileUserRepository_SaveWithSeveralNotifiedOutages(t *testing.T) {
+	repo := setupUserRepo(t)
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	emergency, _ := outage.NewPeriod(time.Date(2024, 1, 1, 6, 30, 0, 0, time.UTC), time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC))
+	hourly, _ := outage.NewPeriod(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	first := users.NewOutageInfo(emergency, outage.NewDescription("Застосування ГАВ"))
+	first.OutageID = 1
+	second := users.NewOutageInfo(hourly, outage.NewDescription("Застосування ГПВ"))
+	second.OutageID = 2
+	second.Reminded = true
+	second.OverdueAlerted = true
+	user := &users.User{ID: 12345, Addresses: []users.SavedAddress{{Address: addr, Notified: []users.OutageInfo{first, second}}}}
+	require.NoError(t, repo.Save(user))
+
+	found, err := repo.Find(12345)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	notified := found.Addresses[0].Notified
+	require.Len(t, notified, 2)
+	assert.Equal(t, 1, notified[0].OutageID)
+	assert.Equal(t, "Застосування ГАВ", notified[0].Description.Value)
+	assert.False(t, notified[0].Reminded)
+	assert.Equal(t, 2, notified[1].OutageID)
+	assert.Equal(t, hourly.StartDate.Unix(), notified[1].Period.StartDate.Unix())
+	assert.True(t, notified[1].Reminded)
+	assert.True(t, notified[1].OverdueAlerted)
+	assert.False(t, notified[0].OverdueAlerted)
 }
 
 func TestFileUserRepository_FindNotFound(t *testing.T) {
@@ -211,13 +244,182 @@ func TestFileUserRepository_SaveOverwriteExisting(t *testing.T) {
 	// Overwrite with different address
 	addr, err := users.NewAddress(2, "Молдавська", "5")
 	require.NoError(t, err)
-	updatedUser := &users.User{ID: 12345, Address: addr}
+	updatedUser := users.NewUser(12345, addr)
 	require.NoError(t, repo.Save(updatedUser))
 
 	found, err := repo.Find(12345)
 	require.NoError(t, err)
 	require.NotNil(t, found)
-	assert.Equal(t, "Молдавська", found.Address.StreetName)
-	assert.Equal(t, "5", found.Address.Building)
-	assert.Equal(t, 2, found.Address.StreetID)
+	assert.Equal(t, "Молдавська", found.Addresses[0].Address.StreetName)
+	assert.Equal(t, "5", found.Addresses[0].Address.Building)
+	assert.Equal(t, 2, found.Addresses[0].Address.StreetID)
+}
+
+func TestFileUserRepository_SaveWithKinds(t *testing.T) {
+	repo := setupUserRepo(t)
+	user := makeTestUser(t, 12345)
+	user.Kinds = []outage.Kind{outage.KindEmergency, outage.KindPlanned}
+	require.NoError(t, repo.Save(user))
+
+	data, err := os.ReadFile(filepath.Join(repo.store.Dir, "12345.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "emergency")
+
+	found, err := repo.Find(12345)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, []outage.Kind{outage.KindEmergency, outage.KindPlanned}, found.Kinds)
+}
+
+func TestFileUserRepository_LoadFromFile_InvalidKind(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileUserRepository(dir)
+	require.NoError(t, err)
+
+	badTOML := "street_id = 1\nstreet_name = \"Test\"\nbuilding = \"10\"\nkinds = [\"bogus\"]\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "999.toml"), []byte(badTOML), 0o644))
+
+	user, err := repo.Find(999)
+	assert.Error(t, err)
+	assert.Nil(t, user)
+	assert.Contains(t, err.Error(), "invalid kind")
+}
+
+func TestFileUserRepository_SaveWithCity(t *testing.T) {
+	repo := setupUserRepo(t)
+	user := makeTestUser(t, 12345)
+	user.Addresses[0].Address.City = "Винники"
+	require.NoError(t, repo.Save(user))
+
+	found, err := repo.Find(12345)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "Винники", found.Addresses[0].Address.City)
+}
+
+func TestFileUserRepository_SaveAndFind_MultipleAddresses(t *testing.T) {
+	repo := setupUserRepo(t)
+	office, err := users.NewAddress(2, "Наукова", "5")
+	require.NoError(t, err)
+	period, _ := outage.NewPeriod(
+		time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC),
+	)
+	info := users.NewOutageInfo(period, outage.NewDescription("test"))
+	user := makeTestUser(t, 12345).WithAddress(office)
+	user.Addresses[1].Notified = []users.OutageInfo{info}
+	require.NoError(t, repo.Save(user))
+
+	found, err := repo.Find(12345)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	require.Len(t, found.Addresses, 2)
+	assert.Equal(t, "Стрийська", found.Addresses[0].Address.StreetName)
+	assert.Empty(t, found.Addresses[0].Notified)
+	assert.Equal(t, "Наукова", found.Addresses[1].Address.StreetName)
+	require.Len(t, found.Addresses[1].Notified, 1)
+	assert.Equal(t, "test", found.Addresses[1].Notified[0].Description.Value)
+}
+
+func TestFileUserRepository_LoadFromFile_LegacySingleAddress(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileUserRepository(dir)
+	require.NoError(t, err)
+
+	legacy := "street_id = 1\nstreet_name = \"Стрийська\"\nbuilding = \"10\"\n" +
+		"start_date = \"2024-01-01T08:00:00Z\"\nend_date = \"2024-01-01T16:00:00Z\"\ncomment = \"test\"\nkinds = [\"hourly\"]\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "999.toml"), []byte(legacy), 0o644))
+
+	user, err := repo.Find(999)
+	require.NoError(t, err)
+	require.NotNil(t, user)
+	require.Len(t, user.Addresses, 1)
+	assert.Equal(t, "Стрийська", user.Addresses[0].Address.StreetName)
+	require.Len(t, user.Addresses[0].Notified, 1)
+	assert.Equal(t, "test", user.Addresses[0].Notified[0].Description.Value)
+	assert.Equal(t, []outage.Kind{outage.KindHourly}, user.Kinds)
+
+	// Saving migrates the file to the list layout.
+	require.NoError(t, repo.Save(user))
+	data, err := os.ReadFile(filepath.Join(dir, "999.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "[[addresses]]")
+
+	reloaded, err := repo.Find(999)
+	require.NoError(t, err)
+	assert.Equal(t, user, reloaded)
+}
+
+func TestFileUserRepository_SaveAndFind_WholeStreet(t *testing.T) {
+	repo := setupUserRepo(t)
+	addr, err := users.NewStreetAddress(1, "Стрийська")
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(users.NewUser(12345, addr)))
+
+	found, err := repo.Find(12345)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.True(t, found.Addresses[0].Address.WholeStreet())
+	assert.Equal(t, "Стрийська", found.Addresses[0].Address.StreetName)
+}
+
+func TestFileUserRepository_SaveAndFind_WholeStreetKeepsNotifiedBuildings(t *testing.T) {
+	repo := setupUserRepo(t)
+	addr, err := users.NewStreetAddress(1, "Стрийська")
+	require.NoError(t, err)
+	period, err := outage.NewPeriod(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	o := &outage.Outage{ID: 1, Period: period, Address: outage.Address{StreetID: 1, StreetName: "Стрийська", Buildings: []string{"10", "12"}}}
+	require.NoError(t, repo.Save(users.NewUser(12345, addr).WithNotifiedOutage(0, o)))
+
+	found, err := repo.Find(12345)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, []string{"10", "12"}, found.Addresses[0].Notified[0].Buildings)
+}
+
+func TestFileUserRepository_LoadFromFile_MissingBuildingWithoutWholeStreet(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileUserRepository(dir)
+	require.NoError(t, err)
+
+	badTOML := "[[addresses]]\nstreet_id = 1\nstreet_name = \"Test\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "999.toml"), []byte(badTOML), 0o644))
+
+	_, err = repo.Find(999)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, users.ErrEmptyBuilding)
+}
+
+func TestFileUserRepository_SaveWithQuietHoursAndCatchUp(t *testing.T) {
+	repo := setupUserRepo(t)
+	q, err := users.NewQuietHours(23, 7, users.QuietDefer)
+	require.NoError(t, err)
+	user := makeTestUser(t, 12345).WithCatchUp(0, true)
+	user.QuietHours = &q
+	require.NoError(t, repo.Save(user))
+
+	found, err := repo.Find(12345)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	require.NotNil(t, found.QuietHours)
+	assert.Equal(t, q, *found.QuietHours)
+	assert.True(t, found.Addresses[0].CatchUp)
+}
+
+func TestFileUserRepository_LoadFromFile_InvalidQuietHours(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := NewFileUserRepository(dir)
+	require.NoError(t, err)
+
+	base := "[[addresses]]\nstreet_id = 1\nstreet_name = \"Test\"\nbuilding = \"10\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1.toml"), []byte("[quiet_hours]\nstart = 23\nend = 7\nmode = \"loud\"\n"+base), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2.toml"), []byte("[quiet_hours]\nstart = 5\nend = 5\nmode = \"silent\"\n"+base), 0o644))
+
+	_, err = repo.Find(1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid quiet hours mode")
+
+	_, err = repo.Find(2)
+	assert.ErrorIs(t, err, users.ErrInvalidQuietHours)
 }
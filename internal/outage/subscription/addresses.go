@@ -0,0 +1,55 @@
+package subscription
+
+import "strings"
+
+func (w *Workflow) handleRemove(chatID int64) Response {
+	user, err := w.userRepo.Find(chatID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if user == nil {
+		delete(w.pending, chatID)
+		return textResponse(messageNoSubscription)
+	}
+
+	w.pending[chatID] = State{Step: StepRemoveAddress, StartedAt: w.now()}
+	return addressOptionsResponse(messagePromptRemoveAddress, user)
+}
+
+func (w *Workflow) handleRemoveAddress(chatID int64, text string) Response {
+	user, err := w.userRepo.Find(chatID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if user == nil {
+		delete(w.pending, chatID)
+		return textResponse(messageNoSubscription)
+	}
+
+	text = strings.TrimSpace(text)
+	index := -1
+	for i, saved := range user.Addresses {
+		if saved.Address.Label() == text {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return addressOptionsResponse(messageAddressNotFound, user)
+	}
+
+	removed := user.Addresses[index].Address
+	if len(user.Addresses) == 1 {
+		if _, err := w.userRepo.Remove(chatID); err != nil {
+			return errorResponse(err)
+		}
+		delete(w.pending, chatID)
+		return textResponse(messageUnsubscribed)
+	}
+
+	if err := w.userRepo.Save(user.WithoutAddress(index)); err != nil {
+		return errorResponse(err)
+	}
+	delete(w.pending, chatID)
+	return addressRemovedResponse(removed)
+}
@@ -1,17 +1,36 @@
 package subscription
 
-import "github.com/sl4wa/outages-bot/internal/outage/users"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+)
 
 func (w *Workflow) handleStart(chatID int64) Response {
-	w.pending[chatID] = State{Step: StepSearchStreet, StartedAt: w.now()}
+	state := State{Step: StepSearchStreet, StartedAt: w.now()}
+	switch {
+	case len(w.cities) > 1:
+		state.Step = StepSelectCity
+	case len(w.cities) == 1:
+		state.SelectedCityID = w.cities[0].ID
+		state.SelectedCityName = w.cities[0].Name
+	}
+	w.pending[chatID] = state
+
+	prompt := promptStreetResponse
+	if state.Step == StepSelectCity {
+		prompt = func(current *users.User) Response { return promptCityResponse(current, w.cities) }
+	}
 
 	current, err := w.userRepo.Find(chatID)
 	if err != nil {
-		resp := promptStreetResponse(nil)
+		resp := prompt(nil)
 		resp.Err = err
 		return resp
 	}
-	return promptStreetResponse(current)
+	return prompt(current)
 }
 
 func (w *Workflow) handleStop(chatID int64) Response {
@@ -50,16 +69,42 @@ func (w *Workflow) handleText(chatID int64, text string) Response {
 	}
 
 	switch state.Step {
+	case StepSelectCity:
+		return w.handleSelectCity(chatID, text, state)
 	case StepSearchStreet:
 		return w.handleSearchStreet(chatID, text)
 	case StepSaveSubscription:
 		return w.handleSaveSubscription(chatID, text, state)
+	case StepSelectKinds:
+		return w.handleSelectKinds(chatID, text, state)
+	case StepRemoveAddress:
+		return w.handleRemoveAddress(chatID, text)
+	case StepQuietHours:
+		return w.handleQuietHours(chatID, text, state)
+	case StepQuietMode:
+		return w.handleQuietMode(chatID, text, state)
 	}
 	return ignoredResponse()
 }
 
+func (w *Workflow) handleSelectCity(chatID int64, text string, state State) Response {
+	city, ok := w.searchCity(text)
+	if !ok {
+		return cityOptionsResponse(messageCityNotFound, w.cities)
+	}
+
+	w.pending[chatID] = State{
+		Step:             StepSearchStreet,
+		SelectedCityID:   city.ID,
+		SelectedCityName: city.Name,
+		StartedAt:        state.StartedAt,
+	}
+	return promptStreetInCityResponse(city.Name)
+}
+
 func (w *Workflow) handleSearchStreet(chatID int64, text string) Response {
-	result, err := w.searchStreet(text)
+	existing := w.pending[chatID]
+	result, err := w.searchStreet(text, existing.SelectedCityID)
 	if err != nil {
 		return invalidInputResponse(err)
 	}
@@ -67,9 +112,10 @@ func (w *Workflow) handleSearchStreet(chatID int64, text string) Response {
 		return streetOptionsResponse(result.options)
 	}
 
-	existing := w.pending[chatID]
 	w.pending[chatID] = State{
 		Step:               StepSaveSubscription,
+		SelectedCityID:     existing.SelectedCityID,
+		SelectedCityName:   existing.SelectedCityName,
 		SelectedStreetID:   result.street.ID,
 		SelectedStreetName: result.street.Name,
 		StartedAt:          existing.StartedAt,
@@ -78,16 +124,59 @@ func (w *Workflow) handleSearchStreet(chatID int64, text string) Response {
 }
 
 func (w *Workflow) handleSaveSubscription(chatID int64, text string, state State) Response {
-	addr, err := users.NewAddress(state.SelectedStreetID, state.SelectedStreetName, text)
+	var addr users.Address
+	var err error
+	if strings.TrimSpace(text) == buttonWholeStreet {
+		addr, err = users.NewStreetAddress(state.SelectedStreetID, state.SelectedStreetName)
+	} else {
+		addr, err = users.NewAddress(state.SelectedStreetID, state.SelectedStreetName, text)
+	}
 	if err != nil {
 		return invalidInputResponse(err)
 	}
+	addr.City = state.SelectedCityName
+
+	existing, err := w.userRepo.Find(chatID)
+	if err != nil {
+		return errorResponse(err)
+	}
 
-	user := &users.User{ID: chatID, Address: addr}
+	user := users.NewUser(chatID, addr)
+	if existing != nil {
+		if existing.HasAddress(addr) {
+			delete(w.pending, chatID)
+			return addressExistsResponse(addr)
+		}
+		user = existing.WithAddress(addr)
+	}
+	i := len(user.Addresses) - 1
+	current, lookupErr := w.currentOutages(user, i)
+	for _, o := range current {
+		user = user.WithNotifiedOutage(i, o)
+		// The confirmation already shows the planned end, so a delay is not news either.
+		if user.OverdueAlertDue(i, o, w.now()) {
+			user = user.WithOverdueAlerted(i, o)
+		}
+	}
 	if err := w.userRepo.Save(user); err != nil {
 		return errorResponse(err)
 	}
 
 	delete(w.pending, chatID)
-	return savedSubscriptionResponse(user)
+	resp := savedSubscriptionResponse(addr, current)
+	resp.Err = lookupErr
+	return resp
+}
+
+// currentOutages returns the outages from the last notifier run that affect the address at index i.
+// They are recorded as notified, so the notifier reports their restoration instead of announcing them again.
+func (w *Workflow) currentOutages(user *users.User, i int) ([]*outage.Outage, error) {
+	if w.outages == nil {
+		return nil, nil
+	}
+	all, err := w.outages.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current outages: %w", err)
+	}
+	return user.FindOutagesForNotification(i, all), nil
 }
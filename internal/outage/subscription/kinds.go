@@ -0,0 +1,99 @@
+package subscription
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+)
+
+func (w *Workflow) handleKinds(chatID int64) Response {
+	user, err := w.userRepo.Find(chatID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if user == nil {
+		delete(w.pending, chatID)
+		return textResponse(messageNoSubscription)
+	}
+
+	selected := slices.Clone(user.Kinds)
+	if len(selected) == 0 {
+		selected = slices.Clone(outage.SelectableKinds)
+	}
+	w.pending[chatID] = State{Step: StepSelectKinds, SelectedKinds: selected, StartedAt: w.now()}
+	return promptKindsResponse(selected)
+}
+
+func (w *Workflow) handleSelectKinds(chatID int64, text string, state State) Response {
+	text = strings.TrimSpace(text)
+
+	if text == buttonSaveKinds {
+		if len(state.SelectedKinds) == 0 {
+			return kindsOptionsResponse(messageKindsEmpty, state.SelectedKinds)
+		}
+		user, err := w.userRepo.Find(chatID)
+		if err != nil {
+			return errorResponse(err)
+		}
+		if user == nil {
+			delete(w.pending, chatID)
+			return textResponse(messageNoSubscription)
+		}
+
+		updated := *user
+		updated.Kinds = normalizeKinds(state.SelectedKinds)
+		if err := w.userRepo.Save(&updated); err != nil {
+			return errorResponse(err)
+		}
+		delete(w.pending, chatID)
+		return savedKindsResponse(state.SelectedKinds)
+	}
+
+	kind, ok := kindFromOption(text)
+	if !ok {
+		return kindsOptionsResponse(messagePromptKinds, state.SelectedKinds)
+	}
+	if i := slices.Index(state.SelectedKinds, kind); i >= 0 {
+		state.SelectedKinds = slices.Delete(slices.Clone(state.SelectedKinds), i, i+1)
+	} else {
+		state.SelectedKinds = append(slices.Clone(state.SelectedKinds), kind)
+	}
+	w.pending[chatID] = state
+	return promptKindsResponse(state.SelectedKinds)
+}
+
+// normalizeKinds orders the selection like SelectableKinds and collapses a full selection
+// to nil so that kinds added later are delivered by default.
+func normalizeKinds(selected []outage.Kind) []outage.Kind {
+	var result []outage.Kind
+	for _, k := range outage.SelectableKinds {
+		if slices.Contains(selected, k) {
+			result = append(result, k)
+		}
+	}
+	if len(result) == len(outage.SelectableKinds) {
+		return nil
+	}
+	return result
+}
+
+func kindOption(kind outage.Kind, selected []outage.Kind) string {
+	mark := kindUncheckedMark
+	if slices.Contains(selected, kind) {
+		mark = kindCheckedMark
+	}
+	return mark + " " + kind.Label()
+}
+
+func kindFromOption(text string) (outage.Kind, bool) {
+	text = strings.TrimPrefix(text, kindCheckedMark)
+	text = strings.TrimPrefix(text, kindUncheckedMark)
+	text = strings.TrimSpace(text)
+	for _, k := range outage.SelectableKinds {
+		if k.Label() == text {
+			return k, true
+		}
+	}
+	return outage.KindUnknown, false
+}
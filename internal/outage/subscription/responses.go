@@ -3,24 +3,71 @@ package subscription
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
 	"github.com/sl4wa/outages-bot/internal/outage/users"
 )
 
 const (
-	messagePromptStreet       = "Будь ласка, введіть назву вулиці:"
-	messageNoSubscription     = "Ви не маєте активної підписки."
-	messageStreetOptions      = "Будь ласка, оберіть вулицю:"
-	messageUnsubscribed       = "Ви успішно відписалися від сповіщень про відключення електроенергії."
-	messageGenericError       = "Сталася помилка. Спробуйте пізніше."
-	messageEmptyStreetQuery   = "Введіть назву вулиці."
-	messageStreetNotFound     = "Вулицю не знайдено. Спробуйте ще раз."
-	messagePromptStreetUpdate = "Ваша поточна підписка:\nВулиця: %s\nБудинок: %s\n\nБудь ласка, введіть нову назву вулиці для оновлення підписки:"
-	messageCurrent            = "Ваша поточна підписка:\nВулиця: %s\nБудинок: %s"
-	messagePromptBuilding     = "Ви обрали вулицю: %s\nБудь ласка, введіть номер будинку:"
-	messageSaved              = "Ви підписалися на сповіщення про відключення електроенергії для вулиці %s, будинок %s."
+	messagePromptStreet        = "Будь ласка, введіть назву вулиці:"
+	messageNoSubscription      = "Ви не маєте активної підписки."
+	messageStreetOptions       = "Будь ласка, оберіть вулицю:"
+	messageUnsubscribed        = "Ви успішно відписалися від сповіщень про відключення електроенергії."
+	messageGenericError        = "Сталася помилка. Спробуйте пізніше."
+	messageEmptyStreetQuery    = "Введіть назву вулиці."
+	messageStreetNotFound      = "Вулицю не знайдено. Спробуйте ще раз."
+	messagePromptStreetUpdate  = "Ваша поточна підписка:\n%s\n\nБудь ласка, введіть назву вулиці, щоб додати ще одну адресу:"
+	messageCurrent             = "Ваша поточна підписка:\n%s"
+	messageAddressLines        = "Вулиця: %s\nБудинок: %s"
+	messageWholeStreetLines    = "Вулиця: %s\nБудинки: усі"
+	messageAddressCityLine     = "Місто: %s\n"
+	messageAddressNumber       = "Адреса %d:\n"
+	messagePromptBuilding      = "Ви обрали вулицю: %s\nБудь ласка, введіть номер будинку або оберіть «Уся вулиця»:"
+	messageSaved               = "Ви підписалися на сповіщення про відключення електроенергії для вулиці %s, будинок %s."
+	messageSavedWithCity       = "Ви підписалися на сповіщення про відключення електроенергії для міста %s, вулиці %s, будинок %s."
+	messageSavedStreet         = "Ви підписалися на сповіщення про відключення електроенергії для всієї вулиці %s."
+	messagePromptCity          = "Будь ласка, оберіть місто:"
+	messagePromptCityUpdate    = "Ваша поточна підписка:\n%s\n\nБудь ласка, оберіть місто, щоб додати ще одну адресу:"
+	messageCityNotFound        = "Місто не знайдено. Будь ласка, оберіть місто зі списку:"
+	messagePromptStreetInCity  = "Ви обрали місто: %s\nБудь ласка, введіть назву вулиці:"
+	messagePromptKinds         = "Оберіть типи відключень, про які бажаєте отримувати сповіщення, і натисніть «Зберегти».\nВідключення з невизначеним типом надсилаються завжди."
+	messageKindsEmpty          = "Оберіть хоча б один тип відключень."
+	messageKindsSaved          = "Налаштування збережено. Ви отримуватимете сповіщення про: %s."
+	messageCurrentKinds        = "\nТипи відключень: %s"
+	messageAddressExists       = "Адреса %s вже є у вашій підписці."
+	messagePromptRemoveAddress = "Оберіть адресу, яку бажаєте видалити:"
+	messageAddressNotFound     = "Адресу не знайдено. Будь ласка, оберіть адресу зі списку:"
+	messageAddressRemoved      = "Адресу %s видалено з підписки."
+	messagePromptQuietHours    = "Тихі години: %s.\n\nВведіть проміжок тихих годин у форматі ГГ-ГГ (наприклад, 23-7) або оберіть «Вимкнути»:"
+	messageQuietHoursOff       = "вимкнено"
+	messagePromptQuietMode     = "Тихі години %s. Що робити зі сповіщеннями в цей час?"
+	messageQuietHoursSaved     = "Тихі години збережено: %s, %s."
+	messageQuietHoursDisabled  = "Тихі години вимкнено."
+	messageCurrentQuietHours   = "\nТихі години: %s, %s"
+	messageCurrentOutages      = "\n\nЗа цією адресою вже є відключення:"
+	messageCurrentOutage       = "%s – %s\n%sКоментар: %s"
+	messageCurrentOutageKind   = "Тип: %s\n"
+	messageStatusNone          = "Наразі немає відомих відключень за вашими адресами."
+	messageStatusAddress       = "Ваша адреса: %s"
+	messageStatusActive        = "\n\nЗараз триває: "
+	messageStatusUpcoming      = "\n\nЗаплановано: "
+	messageStatusNoOutages     = "\nВідомих відключень немає."
+
+	buttonSaveKinds   = "Зберегти"
+	buttonWholeStreet = "Уся вулиця"
+	buttonQuietOff    = "Вимкнути"
+	buttonQuietSilent = "Надсилати без звуку"
+	buttonQuietDefer  = "Відкласти до кінця тихих годин"
+	exampleQuietHours = "23-7"
+	kindCheckedMark   = "✅"
+	kindUncheckedMark = "⬜"
 )
 
+const outageTimeLayout = "2006-01-02 15:04"
+
 func ignoredResponse() Response {
 	return Response{}
 }
@@ -34,19 +81,60 @@ func promptStreetResponse(current *users.User) Response {
 		return textResponse(messagePromptStreet)
 	}
 
-	return textResponse(fmt.Sprintf(
-		messagePromptStreetUpdate,
-		current.Address.StreetName,
-		current.Address.Building,
-	))
+	return textResponse(fmt.Sprintf(messagePromptStreetUpdate, formatAddresses(current.Addresses)))
+}
+
+func promptCityResponse(current *users.User, cities []users.City) Response {
+	if current == nil {
+		return cityOptionsResponse(messagePromptCity, cities)
+	}
+	return cityOptionsResponse(fmt.Sprintf(messagePromptCityUpdate, formatAddresses(current.Addresses)), cities)
+}
+
+func cityOptionsResponse(text string, cities []users.City) Response {
+	names := make([]string, len(cities))
+	for i, city := range cities {
+		names[i] = city.Name
+	}
+	return Response{Text: text, Options: names}
+}
+
+func promptStreetInCityResponse(cityName string) Response {
+	return textResponse(fmt.Sprintf(messagePromptStreetInCity, cityName))
+}
+
+func formatAddress(addr users.Address) string {
+	text := fmt.Sprintf(messageAddressLines, addr.StreetName, addr.Building)
+	if addr.WholeStreet() {
+		text = fmt.Sprintf(messageWholeStreetLines, addr.StreetName)
+	}
+	if addr.City != "" {
+		text = fmt.Sprintf(messageAddressCityLine, addr.City) + text
+	}
+	return text
+}
+
+// formatAddresses lists the saved addresses; a single address is shown without numbering.
+func formatAddresses(saved []users.SavedAddress) string {
+	if len(saved) == 1 {
+		return formatAddress(saved[0].Address)
+	}
+	entries := make([]string, len(saved))
+	for i, s := range saved {
+		entries[i] = fmt.Sprintf(messageAddressNumber, i+1) + formatAddress(s.Address)
+	}
+	return strings.Join(entries, "\n\n")
 }
 
 func currentSubscriptionResponse(user *users.User) Response {
-	return textResponse(fmt.Sprintf(
-		messageCurrent,
-		user.Address.StreetName,
-		user.Address.Building,
-	))
+	text := fmt.Sprintf(messageCurrent, formatAddresses(user.Addresses))
+	if len(user.Kinds) > 0 {
+		text += fmt.Sprintf(messageCurrentKinds, formatKinds(user.Kinds))
+	}
+	if q := user.QuietHours; q != nil {
+		text += fmt.Sprintf(messageCurrentQuietHours, q.Label(), quietModeDescription(q.Mode))
+	}
+	return textResponse(text)
 }
 
 func streetOptionsResponse(options []users.Street) Response {
@@ -54,19 +142,166 @@ func streetOptionsResponse(options []users.Street) Response {
 	for i, opt := range options {
 		names[i] = opt.Name
 	}
-	return Response{Text: messageStreetOptions, StreetOptions: names}
+	return Response{Text: messageStreetOptions, Options: names}
 }
 
 func promptBuildingResponse(streetName string) Response {
-	return textResponse(fmt.Sprintf(messagePromptBuilding, streetName))
+	return Response{
+		Text:    fmt.Sprintf(messagePromptBuilding, streetName),
+		Options: []string{buttonWholeStreet},
+	}
+}
+
+func savedSubscriptionResponse(addr users.Address, current []*outage.Outage) Response {
+	var text string
+	switch {
+	case addr.WholeStreet():
+		street := addr.StreetName
+		if addr.City != "" {
+			street = addr.City + ", " + street
+		}
+		text = fmt.Sprintf(messageSavedStreet, street)
+	case addr.City != "":
+		text = fmt.Sprintf(
+			messageSavedWithCity,
+			addr.City,
+			addr.StreetName,
+			addr.Building,
+		)
+	default:
+		text = fmt.Sprintf(
+			messageSaved,
+			addr.StreetName,
+			addr.Building,
+		)
+	}
+	return textResponse(text + formatCurrentOutages(current))
+}
+
+// formatCurrentOutages lists outages already affecting a newly saved address.
+func formatCurrentOutages(current []*outage.Outage) string {
+	if len(current) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(messageCurrentOutages)
+	for _, o := range current {
+		b.WriteString("\n\n" + formatOutage(o))
+	}
+	return b.String()
+}
+
+func formatOutage(o *outage.Outage) string {
+	kind := ""
+	if o.Kind != outage.KindUnknown {
+		kind = fmt.Sprintf(messageCurrentOutageKind, o.Kind.Label())
+	}
+	return fmt.Sprintf(messageCurrentOutage,
+		o.Period.StartDate.Format(outageTimeLayout),
+		o.Period.EndDate.Format(outageTimeLayout),
+		kind,
+		o.Description.Value,
+	)
 }
 
-func savedSubscriptionResponse(user *users.User) Response {
-	return textResponse(fmt.Sprintf(
-		messageSaved,
-		user.Address.StreetName,
-		user.Address.Building,
-	))
+// statusResponse lists the active and upcoming outages of every saved address.
+// affecting holds the outages of each address, in address order.
+func statusResponse(user *users.User, affecting [][]*outage.Outage, now time.Time) Response {
+	if !slices.ContainsFunc(affecting, func(found []*outage.Outage) bool { return len(found) > 0 }) {
+		return textResponse(messageStatusNone)
+	}
+	entries := make([]string, len(user.Addresses))
+	for i, saved := range user.Addresses {
+		var b strings.Builder
+		fmt.Fprintf(&b, messageStatusAddress, saved.Address.Label())
+		if len(affecting[i]) == 0 {
+			b.WriteString(messageStatusNoOutages)
+		}
+		for _, o := range affecting[i] {
+			if o.Period.StartDate.After(now) {
+				b.WriteString(messageStatusUpcoming)
+			} else {
+				b.WriteString(messageStatusActive)
+			}
+			b.WriteString(formatOutage(o))
+		}
+		entries[i] = b.String()
+	}
+	return textResponse(strings.Join(entries, "\n\n"))
+}
+
+func addressExistsResponse(addr users.Address) Response {
+	return textResponse(fmt.Sprintf(messageAddressExists, addr.Label()))
+}
+
+func addressOptionsResponse(text string, user *users.User) Response {
+	options := make([]string, len(user.Addresses))
+	for i, saved := range user.Addresses {
+		options[i] = saved.Address.Label()
+	}
+	return Response{Text: text, Options: options}
+}
+
+func addressRemovedResponse(addr users.Address) Response {
+	return textResponse(fmt.Sprintf(messageAddressRemoved, addr.Label()))
+}
+
+func promptKindsResponse(selected []outage.Kind) Response {
+	return kindsOptionsResponse(messagePromptKinds, selected)
+}
+
+func kindsOptionsResponse(text string, selected []outage.Kind) Response {
+	options := make([]string, 0, len(outage.SelectableKinds)+1)
+	for _, k := range outage.SelectableKinds {
+		options = append(options, kindOption(k, selected))
+	}
+	options = append(options, buttonSaveKinds)
+	return Response{Text: text, Options: options}
+}
+
+func savedKindsResponse(selected []outage.Kind) Response {
+	return textResponse(fmt.Sprintf(messageKindsSaved, formatKinds(normalizeKinds(selected))))
+}
+
+func formatKinds(kinds []outage.Kind) string {
+	if len(kinds) == 0 {
+		kinds = outage.SelectableKinds
+	}
+	labels := make([]string, len(kinds))
+	for i, k := range kinds {
+		labels[i] = k.Label()
+	}
+	return strings.Join(labels, ", ")
+}
+
+func promptQuietHoursResponse(current *users.QuietHours) Response {
+	status := messageQuietHoursOff
+	if current != nil {
+		status = fmt.Sprintf("%s, %s", current.Label(), quietModeDescription(current.Mode))
+	}
+	return quietHoursOptionsResponse(fmt.Sprintf(messagePromptQuietHours, status))
+}
+
+func quietHoursOptionsResponse(text string) Response {
+	return Response{Text: text, Options: []string{exampleQuietHours, buttonQuietOff}}
+}
+
+func promptQuietModeResponse(label string) Response {
+	return Response{
+		Text:    fmt.Sprintf(messagePromptQuietMode, label),
+		Options: []string{buttonQuietSilent, buttonQuietDefer},
+	}
+}
+
+func savedQuietHoursResponse(q users.QuietHours) Response {
+	return textResponse(fmt.Sprintf(messageQuietHoursSaved, q.Label(), quietModeDescription(q.Mode)))
+}
+
+func quietModeDescription(mode users.QuietMode) string {
+	if mode == users.QuietDefer {
+		return "сповіщення надійдуть після їх завершення"
+	}
+	return "сповіщення надходять без звуку"
 }
 
 func invalidInputResponse(err error) Response {
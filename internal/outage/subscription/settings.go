@@ -0,0 +1,83 @@
+package subscription
+
+import (
+	"strings"
+
+	"github.com/sl4wa/outages-bot/internal/outage/users"
+)
+
+func (w *Workflow) handleSettings(chatID int64) Response {
+	user, err := w.userRepo.Find(chatID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if user == nil {
+		delete(w.pending, chatID)
+		return textResponse(messageNoSubscription)
+	}
+
+	w.pending[chatID] = State{Step: StepQuietHours, StartedAt: w.now()}
+	return promptQuietHoursResponse(user.QuietHours)
+}
+
+func (w *Workflow) handleQuietHours(chatID int64, text string, state State) Response {
+	if strings.TrimSpace(text) == buttonQuietOff {
+		return w.saveQuietHours(chatID, nil)
+	}
+
+	start, end, err := users.ParseQuietHoursRange(text)
+	if err != nil {
+		return quietHoursOptionsResponse(err.Error())
+	}
+
+	w.pending[chatID] = State{
+		Step:       StepQuietMode,
+		QuietStart: start,
+		QuietEnd:   end,
+		StartedAt:  state.StartedAt,
+	}
+	q, _ := users.NewQuietHours(start, end, users.QuietSilent)
+	return promptQuietModeResponse(q.Label())
+}
+
+func (w *Workflow) handleQuietMode(chatID int64, text string, state State) Response {
+	var mode users.QuietMode
+	switch strings.TrimSpace(text) {
+	case buttonQuietSilent:
+		mode = users.QuietSilent
+	case buttonQuietDefer:
+		mode = users.QuietDefer
+	default:
+		q, _ := users.NewQuietHours(state.QuietStart, state.QuietEnd, users.QuietSilent)
+		return promptQuietModeResponse(q.Label())
+	}
+
+	q, err := users.NewQuietHours(state.QuietStart, state.QuietEnd, mode)
+	if err != nil {
+		return invalidInputResponse(err)
+	}
+	return w.saveQuietHours(chatID, &q)
+}
+
+func (w *Workflow) saveQuietHours(chatID int64, q *users.QuietHours) Response {
+	user, err := w.userRepo.Find(chatID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if user == nil {
+		delete(w.pending, chatID)
+		return textResponse(messageNoSubscription)
+	}
+
+	updated := *user
+	updated.QuietHours = q
+	if err := w.userRepo.Save(&updated); err != nil {
+		return errorResponse(err)
+	}
+
+	delete(w.pending, chatID)
+	if q == nil {
+		return textResponse(messageQuietHoursDisabled)
+	}
+	return savedQuietHoursResponse(*q)
+}
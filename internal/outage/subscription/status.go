@@ -0,0 +1,29 @@
+package subscription
+
+import (
+	"fmt"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+)
+
+func (w *Workflow) handleStatus(chatID int64) Response {
+	user, err := w.userRepo.Find(chatID)
+	if err != nil {
+		return errorResponse(err)
+	}
+	if user == nil {
+		return textResponse(messageNoSubscription)
+	}
+
+	var all []*outage.Outage
+	if w.outages != nil {
+		if all, err = w.outages.Load(); err != nil {
+			return errorResponse(fmt.Errorf("failed to load current outages: %w", err))
+		}
+	}
+	affecting := make([][]*outage.Outage, len(user.Addresses))
+	for i := range user.Addresses {
+		affecting[i] = user.AffectingOutages(i, all)
+	}
+	return statusResponse(user, affecting, w.now())
+}
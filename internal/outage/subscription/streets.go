@@ -11,7 +11,8 @@ type streetSearchResult struct {
 	options []users.Street
 }
 
-func (w *Workflow) searchStreet(query string) (streetSearchResult, error) {
+// searchStreet looks up streets by name. A non-zero cityID limits the search to that city.
+func (w *Workflow) searchStreet(query string, cityID int) (streetSearchResult, error) {
 	query = strings.TrimSpace(query)
 	if query == "" {
 		return streetSearchResult{}, ErrEmptyStreetQuery
@@ -20,6 +21,9 @@ func (w *Workflow) searchStreet(query string) (streetSearchResult, error) {
 	q := strings.ToLower(query)
 	var matches []users.Street
 	for _, street := range w.streetRepo.GetAllStreets() {
+		if cityID != 0 && street.CityID != cityID {
+			continue
+		}
 		if street.NameEquals(q) {
 			match := street
 			return streetSearchResult{street: &match}, nil
@@ -39,3 +43,25 @@ func (w *Workflow) searchStreet(query string) (streetSearchResult, error) {
 		return streetSearchResult{options: matches}, nil
 	}
 }
+
+// searchCity finds the configured city matching the query exactly or as the single partial match.
+func (w *Workflow) searchCity(query string) (users.City, bool) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return users.City{}, false
+	}
+
+	var matches []users.City
+	for _, city := range w.cities {
+		if city.NameEquals(q) {
+			return city, true
+		}
+		if city.NameContains(q) {
+			matches = append(matches, city)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], true
+	}
+	return users.City{}, false
+}
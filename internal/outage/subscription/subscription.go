@@ -1,8 +1,11 @@
 package subscription
 
 import (
-	"github.com/sl4wa/outages-bot/internal/outage/users"
+	"slices"
 	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
 )
 
 const defaultPendingTTL = 30 * time.Minute
@@ -15,6 +18,10 @@ const (
 	CommandStart
 	CommandStop
 	CommandSubscription
+	CommandKinds
+	CommandRemove
+	CommandSettings
+	CommandStatus
 )
 
 // Command is an application-level subscription command.
@@ -23,11 +30,11 @@ type Command struct {
 	Text string
 }
 
-// Response is ready to send by adapters, with optional street options for reply keyboards.
+// Response is ready to send by adapters, with optional options for reply keyboards.
 type Response struct {
-	Text          string
-	StreetOptions []string
-	Err           error
+	Text    string
+	Options []string
+	Err     error
 }
 
 // StepKind identifies a step in the subscription conversation.
@@ -37,13 +44,23 @@ const (
 	_ StepKind = iota
 	StepSearchStreet
 	StepSaveSubscription
+	StepSelectKinds
+	StepSelectCity
+	StepRemoveAddress
+	StepQuietHours
+	StepQuietMode
 )
 
 // State holds the state of a user's subscription conversation.
 type State struct {
 	Step               StepKind
+	SelectedCityID     int
+	SelectedCityName   string
 	SelectedStreetID   int
 	SelectedStreetName string
+	SelectedKinds      []outage.Kind
+	QuietStart         int
+	QuietEnd           int
 	StartedAt          time.Time
 }
 
@@ -63,6 +80,8 @@ type StreetRepository interface {
 type Workflow struct {
 	userRepo   UserRepository
 	streetRepo StreetRepository
+	outages    outage.SnapshotReader
+	cities     []users.City
 	pending    map[int64]State
 	ttl        time.Duration
 	now        func() time.Time
@@ -74,6 +93,14 @@ type WorkflowConfig struct {
 	StreetRepo StreetRepository
 	TTL        time.Duration
 	Now        func() time.Time
+
+	// Outages, when set, is checked right after an address is saved so that the confirmation
+	// lists the outages already affecting it, and answers status requests.
+	Outages outage.SnapshotReader
+
+	// Cities lists the served cities. With more than one city the user picks
+	// a city before searching streets, which are then limited to that city.
+	Cities []users.City
 }
 
 // NewWorkflow creates a new subscription conversation workflow.
@@ -89,6 +116,8 @@ func NewWorkflow(cfg WorkflowConfig) *Workflow {
 	return &Workflow{
 		userRepo:   cfg.UserRepo,
 		streetRepo: cfg.StreetRepo,
+		outages:    cfg.Outages,
+		cities:     cfg.Cities,
 		pending:    make(map[int64]State),
 		ttl:        ttl,
 		now:        now,
@@ -104,6 +133,14 @@ func (w *Workflow) Handle(chatID int64, cmd Command) Response {
 		return w.handleStop(chatID)
 	case CommandSubscription:
 		return w.handleSubscription(chatID)
+	case CommandKinds:
+		return w.handleKinds(chatID)
+	case CommandRemove:
+		return w.handleRemove(chatID)
+	case CommandSettings:
+		return w.handleSettings(chatID)
+	case CommandStatus:
+		return w.handleStatus(chatID)
 	case CommandText:
 		return w.handleText(chatID, cmd.Text)
 	default:
@@ -117,5 +154,6 @@ func (w *Workflow) GetState(chatID int64) *State {
 	if !ok {
 		return nil
 	}
+	state.SelectedKinds = slices.Clone(state.SelectedKinds)
 	return &state
 }
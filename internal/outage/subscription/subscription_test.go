@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
 	"github.com/sl4wa/outages-bot/internal/outage/users"
 
 	"github.com/stretchr/testify/assert"
@@ -81,7 +82,7 @@ func addUser(t *testing.T, repo *testUserRepo, chatID int64, streetID int, stree
 	t.Helper()
 	addr, err := users.NewAddress(streetID, streetName, building)
 	require.NoError(t, err)
-	repo.users[chatID] = &users.User{ID: chatID, Address: addr}
+	repo.users[chatID] = users.NewUser(chatID, addr)
 }
 
 func startSearch(t *testing.T, wf *Workflow, chatID int64) {
@@ -114,7 +115,7 @@ func TestServiceStartStopAndSubscription(t *testing.T) {
 	assert.Equal(t, "Ваша поточна підписка:\nВулиця: Стрийська\nБудинок: 10", response.Text)
 
 	response = wf.Handle(100, Command{Kind: CommandStart})
-	assert.Equal(t, "Ваша поточна підписка:\nВулиця: Стрийська\nБудинок: 10\n\nБудь ласка, введіть нову назву вулиці для оновлення підписки:", response.Text)
+	assert.Equal(t, "Ваша поточна підписка:\nВулиця: Стрийська\nБудинок: 10\n\nБудь ласка, введіть назву вулиці, щоб додати ще одну адресу:", response.Text)
 
 	response = wf.Handle(100, Command{Kind: CommandStop})
 	assert.Equal(t, messageUnsubscribed, response.Text)
@@ -143,11 +144,12 @@ func TestServiceStreetSearch(t *testing.T) {
 			wantStep: StepSearchStreet,
 		},
 		{
-			name:       "single partial match",
-			query:      "науков",
-			wantText:   "Ви обрали вулицю: Наукова\nБудь ласка, введіть номер будинку:",
-			wantStep:   StepSaveSubscription,
-			wantStreet: 2,
+			name:        "single partial match",
+			query:       "науков",
+			wantText:    "Ви обрали вулицю: Наукова\nБудь ласка, введіть номер будинку або оберіть «Уся вулиця»:",
+			wantStep:    StepSaveSubscription,
+			wantStreet:  2,
+			wantOptions: []string{buttonWholeStreet},
 		},
 		{
 			name:        "multiple matches",
@@ -157,11 +159,12 @@ func TestServiceStreetSearch(t *testing.T) {
 			wantOptions: []string{"Стрийська", "Стрілецька"},
 		},
 		{
-			name:       "exact match",
-			query:      "Наукова",
-			wantText:   "Ви обрали вулицю: Наукова\nБудь ласка, введіть номер будинку:",
-			wantStep:   StepSaveSubscription,
-			wantStreet: 2,
+			name:        "exact match",
+			query:       "Наукова",
+			wantText:    "Ви обрали вулицю: Наукова\nБудь ласка, введіть номер будинку або оберіть «Уся вулиця»:",
+			wantStep:    StepSaveSubscription,
+			wantStreet:  2,
+			wantOptions: []string{buttonWholeStreet},
 		},
 	}
 
@@ -173,7 +176,7 @@ func TestServiceStreetSearch(t *testing.T) {
 			response := wf.Handle(100, Command{Kind: CommandText, Text: tt.query})
 
 			assert.Equal(t, tt.wantText, response.Text)
-			assert.Equal(t, tt.wantOptions, response.StreetOptions)
+			assert.Equal(t, tt.wantOptions, response.Options)
 			state := wf.GetState(100)
 			require.NotNil(t, state)
 			assert.Equal(t, tt.wantStep, state.Step)
@@ -206,8 +209,8 @@ func TestServiceSuccessfulSaveClearsState(t *testing.T) {
 
 	assert.Equal(t, "Ви підписалися на сповіщення про відключення електроенергії для вулиці Наукова, будинок 10.", response.Text)
 	require.NotNil(t, repo.users[100])
-	assert.Equal(t, "Наукова", repo.users[100].Address.StreetName)
-	assert.Equal(t, "10", repo.users[100].Address.Building)
+	assert.Equal(t, "Наукова", repo.users[100].Addresses[0].Address.StreetName)
+	assert.Equal(t, "10", repo.users[100].Addresses[0].Address.Building)
 	assert.Nil(t, wf.GetState(100))
 }
 
@@ -255,7 +258,7 @@ func TestServiceUnknownTextWithoutPendingStateIsIgnored(t *testing.T) {
 	response := wf.Handle(100, Command{Kind: CommandText, Text: "Наукова"})
 
 	assert.Empty(t, response.Text)
-	assert.Empty(t, response.StreetOptions)
+	assert.Empty(t, response.Options)
 	assert.NoError(t, response.Err)
 }
 
@@ -312,7 +315,7 @@ func TestServiceActiveStateNotExpired(t *testing.T) {
 	wf.now = func() time.Time { return base.Add(29 * time.Minute) }
 	response := wf.Handle(100, Command{Kind: CommandText, Text: "Наукова"})
 
-	assert.Equal(t, "Ви обрали вулицю: Наукова\nБудь ласка, введіть номер будинку:", response.Text)
+	assert.Equal(t, "Ви обрали вулицю: Наукова\nБудь ласка, введіть номер будинку або оберіть «Уся вулиця»:", response.Text)
 	require.NotNil(t, wf.GetState(100))
 	assert.Equal(t, StepSaveSubscription, wf.GetState(100).Step)
 }
@@ -360,3 +363,491 @@ func TestNewWorkflow_HonorsConfigNow(t *testing.T) {
 	require.NotNil(t, state)
 	assert.Equal(t, fixed, state.StartedAt)
 }
+
+func TestServiceKinds_NoSubscription(t *testing.T) {
+	wf, _ := newTestWorkflow(t, nil)
+
+	response := wf.Handle(100, Command{Kind: CommandKinds})
+
+	assert.Equal(t, messageNoSubscription, response.Text)
+	assert.Nil(t, wf.GetState(100))
+}
+
+func TestServiceKinds_ToggleAndSave(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+
+	response := wf.Handle(100, Command{Kind: CommandKinds})
+	assert.Equal(t, messagePromptKinds, response.Text)
+	assert.Equal(t, []string{
+		"✅ Аварійне відключення (ГАВ)",
+		"✅ Погодинне відключення (ГПВ)",
+		"Зберегти",
+	}, response.Options)
+	require.NotNil(t, wf.GetState(100))
+	assert.Equal(t, StepSelectKinds, wf.GetState(100).Step)
+
+	response = wf.Handle(100, Command{Kind: CommandText, Text: "✅ Погодинне відключення (ГПВ)"})
+	assert.Equal(t, "⬜ Погодинне відключення (ГПВ)", response.Options[1])
+
+	response = wf.Handle(100, Command{Kind: CommandText, Text: "Зберегти"})
+	assert.Equal(t, "Налаштування збережено. Ви отримуватимете сповіщення про: Аварійне відключення (ГАВ).", response.Text)
+	assert.Equal(t, []outage.Kind{outage.KindEmergency}, repo.users[100].Kinds)
+	assert.Equal(t, "Стрийська", repo.users[100].Addresses[0].Address.StreetName)
+	assert.Nil(t, wf.GetState(100))
+
+	response = wf.Handle(100, Command{Kind: CommandSubscription})
+	assert.Equal(t, "Ваша поточна підписка:\nВулиця: Стрийська\nБудинок: 10\nТипи відключень: Аварійне відключення (ГАВ)", response.Text)
+}
+
+func TestServiceKinds_EmptySelectionRejected(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+	repo.users[100].Kinds = []outage.Kind{outage.KindHourly}
+
+	wf.Handle(100, Command{Kind: CommandKinds})
+	wf.Handle(100, Command{Kind: CommandText, Text: "✅ Погодинне відключення (ГПВ)"})
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "Зберегти"})
+
+	assert.Equal(t, messageKindsEmpty, response.Text)
+	assert.Equal(t, []outage.Kind{outage.KindHourly}, repo.users[100].Kinds)
+	require.NotNil(t, wf.GetState(100))
+}
+
+func TestServiceKinds_FullSelectionStoredAsAll(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+	repo.users[100].Kinds = []outage.Kind{outage.KindHourly}
+
+	wf.Handle(100, Command{Kind: CommandKinds})
+	wf.Handle(100, Command{Kind: CommandText, Text: "⬜ Аварійне відключення (ГАВ)"})
+	wf.Handle(100, Command{Kind: CommandText, Text: "Зберегти"})
+
+	assert.Nil(t, repo.users[100].Kinds)
+}
+
+func TestServiceAddAddressPreservesKinds(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+	repo.users[100].Kinds = []outage.Kind{outage.KindEmergency}
+
+	wf.Handle(100, Command{Kind: CommandStart})
+	wf.Handle(100, Command{Kind: CommandText, Text: "Наукова"})
+	wf.Handle(100, Command{Kind: CommandText, Text: "5"})
+
+	require.Len(t, repo.users[100].Addresses, 2)
+	assert.Equal(t, "Стрийська", repo.users[100].Addresses[0].Address.StreetName)
+	assert.Equal(t, "Наукова", repo.users[100].Addresses[1].Address.StreetName)
+	assert.Equal(t, []outage.Kind{outage.KindEmergency}, repo.users[100].Kinds)
+}
+
+func newMultiCityWorkflow(t *testing.T) (*Workflow, *testUserRepo) {
+	t.Helper()
+	repo := newTestUserRepo()
+	wf := NewWorkflow(WorkflowConfig{
+		UserRepo: repo,
+		StreetRepo: &testStreetRepo{streets: []users.Street{
+			{ID: 1, Name: "Стрийська", CityID: 693},
+			{ID: 2, Name: "Наукова", CityID: 693},
+			{ID: 50, Name: "Галицька", CityID: 700},
+			{ID: 51, Name: "Наукова", CityID: 700},
+		}},
+		Cities: []users.City{{ID: 693, Name: "Львів"}, {ID: 700, Name: "Винники"}},
+	})
+	return wf, repo
+}
+
+func TestServiceMultiCity_AsksForCityFirst(t *testing.T) {
+	wf, repo := newMultiCityWorkflow(t)
+
+	response := wf.Handle(100, Command{Kind: CommandStart})
+	assert.Equal(t, messagePromptCity, response.Text)
+	assert.Equal(t, []string{"Львів", "Винники"}, response.Options)
+	assert.Equal(t, StepSelectCity, wf.GetState(100).Step)
+
+	response = wf.Handle(100, Command{Kind: CommandText, Text: "винн"})
+	assert.Equal(t, "Ви обрали місто: Винники\nБудь ласка, введіть назву вулиці:", response.Text)
+	state := wf.GetState(100)
+	require.NotNil(t, state)
+	assert.Equal(t, StepSearchStreet, state.Step)
+	assert.Equal(t, 700, state.SelectedCityID)
+
+	response = wf.Handle(100, Command{Kind: CommandText, Text: "Наукова"})
+	assert.Equal(t, "Ви обрали вулицю: Наукова\nБудь ласка, введіть номер будинку або оберіть «Уся вулиця»:", response.Text)
+	assert.Equal(t, 51, wf.GetState(100).SelectedStreetID, "street search is limited to the chosen city")
+
+	response = wf.Handle(100, Command{Kind: CommandText, Text: "5"})
+	assert.Equal(t, "Ви підписалися на сповіщення про відключення електроенергії для міста Винники, вулиці Наукова, будинок 5.", response.Text)
+	require.NotNil(t, repo.users[100])
+	assert.Equal(t, "Винники", repo.users[100].Addresses[0].Address.City)
+	assert.Equal(t, 51, repo.users[100].Addresses[0].Address.StreetID)
+
+	response = wf.Handle(100, Command{Kind: CommandSubscription})
+	assert.Equal(t, "Ваша поточна підписка:\nМісто: Винники\nВулиця: Наукова\nБудинок: 5", response.Text)
+}
+
+func TestServiceMultiCity_StreetFromOtherCityNotFound(t *testing.T) {
+	wf, _ := newMultiCityWorkflow(t)
+	wf.Handle(100, Command{Kind: CommandStart})
+	wf.Handle(100, Command{Kind: CommandText, Text: "Львів"})
+
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "Галицька"})
+
+	assert.Equal(t, messageStreetNotFound, response.Text)
+}
+
+func TestServiceMultiCity_UnknownCityRepeatsOptions(t *testing.T) {
+	wf, _ := newMultiCityWorkflow(t)
+	wf.Handle(100, Command{Kind: CommandStart})
+
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "Київ"})
+
+	assert.Equal(t, messageCityNotFound, response.Text)
+	assert.Equal(t, []string{"Львів", "Винники"}, response.Options)
+	assert.Equal(t, StepSelectCity, wf.GetState(100).Step)
+}
+
+func TestServiceSingleCity_SkipsCityStepAndStoresCity(t *testing.T) {
+	repo := newTestUserRepo()
+	streets := testStreets()
+	for i := range streets {
+		streets[i].CityID = 693
+	}
+	wf := NewWorkflow(WorkflowConfig{
+		UserRepo:   repo,
+		StreetRepo: &testStreetRepo{streets: streets},
+		Cities:     []users.City{{ID: 693, Name: "Львів"}},
+	})
+
+	startSearch(t, wf, 100)
+	selectStreet(t, wf, 100, "Наукова")
+	wf.Handle(100, Command{Kind: CommandText, Text: "10"})
+
+	require.NotNil(t, repo.users[100])
+	assert.Equal(t, "Львів", repo.users[100].Addresses[0].Address.City)
+}
+
+func TestServiceAddSameAddressTwice_NotDuplicated(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 2, "Наукова", "10")
+
+	wf.Handle(100, Command{Kind: CommandStart})
+	wf.Handle(100, Command{Kind: CommandText, Text: "Наукова"})
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "10"})
+
+	assert.Equal(t, "Адреса Наукова, 10 вже є у вашій підписці.", response.Text)
+	assert.Len(t, repo.users[100].Addresses, 1)
+	assert.Nil(t, wf.GetState(100))
+}
+
+func TestServiceSubscriptionListsAllAddresses(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+	office, _ := users.NewAddress(2, "Наукова", "5")
+	repo.users[100] = repo.users[100].WithAddress(office)
+
+	response := wf.Handle(100, Command{Kind: CommandSubscription})
+	assert.Equal(t, "Ваша поточна підписка:\nАдреса 1:\nВулиця: Стрийська\nБудинок: 10\n\nАдреса 2:\nВулиця: Наукова\nБудинок: 5", response.Text)
+}
+
+func TestServiceRemove_NoSubscription(t *testing.T) {
+	wf, _ := newTestWorkflow(t, nil)
+	response := wf.Handle(100, Command{Kind: CommandRemove})
+	assert.Equal(t, messageNoSubscription, response.Text)
+	assert.Nil(t, wf.GetState(100))
+}
+
+func TestServiceRemove_OneOfSeveralAddresses(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+	office, _ := users.NewAddress(2, "Наукова", "5")
+	repo.users[100] = repo.users[100].WithAddress(office)
+
+	response := wf.Handle(100, Command{Kind: CommandRemove})
+	assert.Equal(t, messagePromptRemoveAddress, response.Text)
+	assert.Equal(t, []string{"Стрийська, 10", "Наукова, 5"}, response.Options)
+
+	response = wf.Handle(100, Command{Kind: CommandText, Text: "Стрийська, 10"})
+	assert.Equal(t, "Адресу Стрийська, 10 видалено з підписки.", response.Text)
+	require.Len(t, repo.users[100].Addresses, 1)
+	assert.Equal(t, office, repo.users[100].Addresses[0].Address)
+	assert.Nil(t, wf.GetState(100))
+}
+
+func TestServiceRemove_LastAddressUnsubscribes(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+
+	wf.Handle(100, Command{Kind: CommandRemove})
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "Стрийська, 10"})
+
+	assert.Equal(t, messageUnsubscribed, response.Text)
+	assert.Nil(t, repo.users[100])
+}
+
+func TestServiceRemove_UnknownAddressRepeatsOptions(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+
+	wf.Handle(100, Command{Kind: CommandRemove})
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "Наукова, 5"})
+
+	assert.Equal(t, messageAddressNotFound, response.Text)
+	assert.Equal(t, []string{"Стрийська, 10"}, response.Options)
+	require.NotNil(t, wf.GetState(100))
+	assert.Equal(t, StepRemoveAddress, wf.GetState(100).Step)
+}
+
+func TestServiceWholeStreetSubscription(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	startSearch(t, wf, 100)
+	selectStreet(t, wf, 100, "Наукова")
+
+	response := wf.Handle(100, Command{Kind: CommandText, Text: buttonWholeStreet})
+
+	assert.Equal(t, "Ви підписалися на сповіщення про відключення електроенергії для всієї вулиці Наукова.", response.Text)
+	require.NotNil(t, repo.users[100])
+	assert.True(t, repo.users[100].Addresses[0].Address.WholeStreet())
+	assert.Nil(t, wf.GetState(100))
+
+	response = wf.Handle(100, Command{Kind: CommandSubscription})
+	assert.Equal(t, "Ваша поточна підписка:\nВулиця: Наукова\nБудинки: усі", response.Text)
+}
+
+func TestServiceSettings_NoSubscription(t *testing.T) {
+	wf, _ := newTestWorkflow(t, nil)
+
+	response := wf.Handle(100, Command{Kind: CommandSettings})
+	assert.Equal(t, messageNoSubscription, response.Text)
+	assert.Nil(t, wf.GetState(100))
+}
+
+func TestServiceSettings_SaveQuietHours(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+
+	response := wf.Handle(100, Command{Kind: CommandSettings})
+	assert.Contains(t, response.Text, "Тихі години: вимкнено.")
+	assert.Equal(t, []string{"23-7", "Вимкнути"}, response.Options)
+	require.NotNil(t, wf.GetState(100))
+	assert.Equal(t, StepQuietHours, wf.GetState(100).Step)
+
+	response = wf.Handle(100, Command{Kind: CommandText, Text: "22-8"})
+	assert.Equal(t, "Тихі години 22:00–08:00. Що робити зі сповіщеннями в цей час?", response.Text)
+	assert.Equal(t, []string{"Надсилати без звуку", "Відкласти до кінця тихих годин"}, response.Options)
+	assert.Equal(t, StepQuietMode, wf.GetState(100).Step)
+
+	response = wf.Handle(100, Command{Kind: CommandText, Text: "Відкласти до кінця тихих годин"})
+	assert.Equal(t, "Тихі години збережено: 22:00–08:00, сповіщення надійдуть після їх завершення.", response.Text)
+	require.NotNil(t, repo.users[100].QuietHours)
+	assert.Equal(t, users.QuietHours{Start: 22, End: 8, Mode: users.QuietDefer}, *repo.users[100].QuietHours)
+	assert.Equal(t, "Стрийська", repo.users[100].Addresses[0].Address.StreetName)
+	assert.Nil(t, wf.GetState(100))
+
+	response = wf.Handle(100, Command{Kind: CommandSubscription})
+	assert.Equal(t, "Ваша поточна підписка:\nВулиця: Стрийська\nБудинок: 10\nТихі години: 22:00–08:00, сповіщення надійдуть після їх завершення", response.Text)
+}
+
+func TestServiceSettings_InvalidRangeKeepsState(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+
+	wf.Handle(100, Command{Kind: CommandSettings})
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "25-7"})
+
+	assert.Equal(t, users.ErrInvalidQuietHours.Error(), response.Text)
+	assert.Equal(t, []string{"23-7", "Вимкнути"}, response.Options)
+	assert.Equal(t, StepQuietHours, wf.GetState(100).Step)
+	assert.Nil(t, repo.users[100].QuietHours)
+}
+
+func TestServiceSettings_UnknownModeRepeatsOptions(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+
+	wf.Handle(100, Command{Kind: CommandSettings})
+	wf.Handle(100, Command{Kind: CommandText, Text: "23-7"})
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "щось"})
+
+	assert.Equal(t, []string{"Надсилати без звуку", "Відкласти до кінця тихих годин"}, response.Options)
+	assert.Equal(t, StepQuietMode, wf.GetState(100).Step)
+	assert.Nil(t, repo.users[100].QuietHours)
+}
+
+func TestServiceSettings_Disable(t *testing.T) {
+	wf, repo := newTestWorkflow(t, nil)
+	addUser(t, repo, 100, 1, "Стрийська", "10")
+	repo.users[100].QuietHours = &users.QuietHours{Start: 23, End: 7, Mode: users.QuietSilent}
+
+	response := wf.Handle(100, Command{Kind: CommandSettings})
+	assert.Contains(t, response.Text, "Тихі години: 23:00–07:00, сповіщення надходять без звуку.")
+
+	response = wf.Handle(100, Command{Kind: CommandText, Text: "Вимкнути"})
+	assert.Equal(t, messageQuietHoursDisabled, response.Text)
+	assert.Nil(t, repo.users[100].QuietHours)
+	assert.Nil(t, wf.GetState(100))
+}
+
+type testOutageSource struct {
+	outages []*outage.Outage
+	err     error
+}
+
+func (s *testOutageSource) Load() ([]*outage.Outage, error) {
+	return s.outages, s.err
+}
+
+func testCurrentOutage(t *testing.T, streetID int, buildings []string, start, end time.Time) *outage.Outage {
+	t.Helper()
+	addr, err := outage.NewAddress(streetID, "Наукова", buildings, "Львів")
+	require.NoError(t, err)
+	period, err := outage.NewPeriod(start, end)
+	require.NoError(t, err)
+	return &outage.Outage{ID: 1, Period: period, Address: addr, Description: outage.NewDescription("Аварійні роботи"), Kind: outage.KindEmergency}
+}
+
+func newOutagesWorkflow(t *testing.T, source *testOutageSource, now time.Time) (*Workflow, *testUserRepo) {
+	t.Helper()
+	repo := newTestUserRepo()
+	wf := NewWorkflow(WorkflowConfig{
+		UserRepo:   repo,
+		StreetRepo: &testStreetRepo{streets: testStreets()},
+		Now:        func() time.Time { return now },
+		Outages:    source,
+	})
+	return wf, repo
+}
+
+func TestServiceSave_ListsCurrentOutages(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	affecting := testCurrentOutage(t, 2, []string{"8", "10"}, now.Add(-2*time.Hour), now.Add(2*time.Hour))
+	other := testCurrentOutage(t, 2, []string{"12"}, now.Add(-2*time.Hour), now.Add(2*time.Hour))
+	wf, repo := newOutagesWorkflow(t, &testOutageSource{outages: []*outage.Outage{affecting, other}}, now)
+	startSearch(t, wf, 100)
+	selectStreet(t, wf, 100, "Наукова")
+
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "10"})
+
+	assert.Equal(t, "Ви підписалися на сповіщення про відключення електроенергії для вулиці Наукова, будинок 10."+
+		"\n\nЗа цією адресою вже є відключення:"+
+		"\n\n2024-01-01 10:00 – 2024-01-01 14:00\nТип: "+outage.KindEmergency.Label()+"\nКоментар: Аварійні роботи", response.Text)
+	assert.NoError(t, response.Err)
+	require.NotNil(t, repo.users[100])
+	assert.NotNil(t, repo.users[100].NotifiedAbout(0, affecting), "recorded so the notifier does not announce it again")
+	assert.Empty(t, repo.users[100].FindOutagesForNotification(0, []*outage.Outage{affecting}))
+}
+
+func TestServiceSave_OverdueOutageNotAlertedAgain(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	overdue := testCurrentOutage(t, 2, []string{"10"}, now.Add(-3*time.Hour), now.Add(-time.Hour))
+	wf, repo := newOutagesWorkflow(t, &testOutageSource{outages: []*outage.Outage{overdue}}, now)
+	startSearch(t, wf, 100)
+	selectStreet(t, wf, 100, "Наукова")
+
+	wf.Handle(100, Command{Kind: CommandText, Text: "10"})
+
+	require.NotNil(t, repo.users[100])
+	assert.False(t, repo.users[100].OverdueAlertDue(0, overdue, now))
+}
+
+func TestServiceSave_AddedAddressListsItsOutages(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	current := testCurrentOutage(t, 2, []string{"10"}, now.Add(-time.Hour), now.Add(time.Hour))
+	wf, repo := newOutagesWorkflow(t, &testOutageSource{outages: []*outage.Outage{current}}, now)
+	addUser(t, repo, 100, 1, "Стрийська", "5")
+	wf.Handle(100, Command{Kind: CommandStart})
+	selectStreet(t, wf, 100, "Наукова")
+
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "10"})
+
+	assert.Contains(t, response.Text, "За цією адресою вже є відключення:")
+	require.Len(t, repo.users[100].Addresses, 2)
+	assert.Empty(t, repo.users[100].Addresses[0].Notified)
+	assert.Len(t, repo.users[100].Addresses[1].Notified, 1)
+}
+
+func TestServiceSave_NoCurrentOutages(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	wf, repo := newOutagesWorkflow(t, &testOutageSource{}, now)
+	startSearch(t, wf, 100)
+	selectStreet(t, wf, 100, "Наукова")
+
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "10"})
+
+	assert.Equal(t, "Ви підписалися на сповіщення про відключення електроенергії для вулиці Наукова, будинок 10.", response.Text)
+	assert.Empty(t, repo.users[100].Addresses[0].Notified)
+}
+
+func TestServiceSave_OutageLookupErrorStillSaves(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	wf, repo := newOutagesWorkflow(t, &testOutageSource{err: errors.New("read error")}, now)
+	startSearch(t, wf, 100)
+	selectStreet(t, wf, 100, "Наукова")
+
+	response := wf.Handle(100, Command{Kind: CommandText, Text: "10"})
+
+	assert.Equal(t, "Ви підписалися на сповіщення про відключення електроенергії для вулиці Наукова, будинок 10.", response.Text)
+	assert.ErrorContains(t, response.Err, "read error")
+	require.NotNil(t, repo.users[100])
+	assert.Nil(t, wf.GetState(100))
+}
+
+func TestServiceStatus_ListsActiveAndUpcomingOutages(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	active := testCurrentOutage(t, 2, []string{"10"}, now.Add(-2*time.Hour), now.Add(2*time.Hour))
+	upcoming := testCurrentOutage(t, 2, []string{"10"}, now.Add(22*time.Hour), now.Add(26*time.Hour))
+	upcoming.Kind = outage.KindUnknown
+	upcoming.Description = outage.NewDescription("Планові роботи")
+	wf, repo := newOutagesWorkflow(t, &testOutageSource{outages: []*outage.Outage{active, upcoming}}, now)
+	addUser(t, repo, 100, 2, "Наукова", "10")
+	repo.users[100] = repo.users[100].WithAddress(users.Address{StreetID: 1, StreetName: "Стрийська", Building: "5"})
+
+	response := wf.Handle(100, Command{Kind: CommandStatus})
+
+	assert.Equal(t, "Ваша адреса: Наукова, 10"+
+		"\n\nЗараз триває: 2024-01-01 10:00 – 2024-01-01 14:00\nТип: "+outage.KindEmergency.Label()+"\nКоментар: Аварійні роботи"+
+		"\n\nЗаплановано: 2024-01-02 10:00 – 2024-01-02 14:00\nКоментар: Планові роботи"+
+		"\n\nВаша адреса: Стрийська, 5\nВідомих відключень немає.", response.Text)
+	assert.NoError(t, response.Err)
+}
+
+func TestServiceStatus_NoKnownOutages(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	elsewhere := testCurrentOutage(t, 2, []string{"12"}, now.Add(-time.Hour), now.Add(time.Hour))
+	wf, repo := newOutagesWorkflow(t, &testOutageSource{outages: []*outage.Outage{elsewhere}}, now)
+	addUser(t, repo, 100, 2, "Наукова", "10")
+
+	response := wf.Handle(100, Command{Kind: CommandStatus})
+
+	assert.Equal(t, messageStatusNone, response.Text)
+}
+
+func TestServiceStatus_IgnoresUnwantedKinds(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	active := testCurrentOutage(t, 2, []string{"10"}, now.Add(-time.Hour), now.Add(time.Hour))
+	wf, repo := newOutagesWorkflow(t, &testOutageSource{outages: []*outage.Outage{active}}, now)
+	addUser(t, repo, 100, 2, "Наукова", "10")
+	repo.users[100].Kinds = []outage.Kind{outage.KindHourly}
+
+	response := wf.Handle(100, Command{Kind: CommandStatus})
+
+	assert.Equal(t, messageStatusNone, response.Text)
+}
+
+func TestServiceStatus_NoSubscription(t *testing.T) {
+	wf, _ := newOutagesWorkflow(t, &testOutageSource{}, time.Now())
+
+	response := wf.Handle(100, Command{Kind: CommandStatus})
+
+	assert.Equal(t, messageNoSubscription, response.Text)
+}
+
+func TestServiceStatus_LoadError(t *testing.T) {
+	wf, repo := newOutagesWorkflow(t, &testOutageSource{err: errors.New("read error")}, time.Now())
+	addUser(t, repo, 100, 2, "Наукова", "10")
+
+	response := wf.Handle(100, Command{Kind: CommandStatus})
+
+	assert.Equal(t, messageGenericError, response.Text)
+	assert.ErrorContains(t, response.Err, "read error")
+}
@@ -67,6 +67,14 @@ func (br *BotRunner) handleMessage(msg *tgbotapi.Message) {
 			cmd = subscription.Command{Kind: subscription.CommandStop}
 		case "subscription":
 			cmd = subscription.Command{Kind: subscription.CommandSubscription}
+		case "types":
+			cmd = subscription.Command{Kind: subscription.CommandKinds}
+		case "remove":
+			cmd = subscription.Command{Kind: subscription.CommandRemove}
+		case "settings":
+			cmd = subscription.Command{Kind: subscription.CommandSettings}
+		case "status":
+			cmd = subscription.Command{Kind: subscription.CommandStatus}
 		}
 	}
 
@@ -85,7 +93,7 @@ func (br *BotRunner) sendMessage(chatID int64, text string, markup interface{})
 }
 
 func (br *BotRunner) sendResponse(chatID int64, response subscription.Response) {
-	if response.Text == "" && len(response.StreetOptions) == 0 {
+	if response.Text == "" && len(response.Options) == 0 {
 		return
 	}
 	if response.Err != nil {
@@ -93,13 +101,13 @@ func (br *BotRunner) sendResponse(chatID int64, response subscription.Response)
 	}
 
 	var markup interface{}
-	if len(response.StreetOptions) > 0 {
-		markup = streetOptionsKeyboard(response.StreetOptions)
+	if len(response.Options) > 0 {
+		markup = optionsKeyboard(response.Options)
 	}
 	br.sendMessage(chatID, response.Text, markup)
 }
 
-func streetOptionsKeyboard(names []string) interface{} {
+func optionsKeyboard(names []string) interface{} {
 	rows := make([][]tgbotapi.KeyboardButton, len(names))
 	for i, name := range names {
 		rows[i] = tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(name))
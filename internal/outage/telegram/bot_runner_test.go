@@ -195,8 +195,8 @@ func TestBot_SaveSubscription_ValidInput_CompletesFlow(t *testing.T) {
 	user, err := userRepo.Find(100)
 	require.NoError(t, err)
 	require.NotNil(t, user)
-	assert.Equal(t, "Наукова", user.Address.StreetName)
-	assert.Equal(t, "10", user.Address.Building)
+	assert.Equal(t, "Наукова", user.Addresses[0].Address.StreetName)
+	assert.Equal(t, "10", user.Addresses[0].Address.Building)
 }
 
 func TestBot_SaveSubscription_InvalidInput_StaysInSave(t *testing.T) {
@@ -213,7 +213,7 @@ func TestBot_SaveSubscription_InvalidInput_StaysInSave(t *testing.T) {
 func TestBot_StopWithSubscription_RemovesUser(t *testing.T) {
 	br, userRepo, msgs := setupBot(t)
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	userRepo.users[100] = &users.User{ID: 100, Address: addr}
+	userRepo.users[100] = users.NewUser(100, addr)
 
 	br.HandleMessage(makeCmd(100, "stop"))
 
@@ -299,7 +299,7 @@ func TestBot_StopDuringConversation_ClearsState(t *testing.T) {
 func TestBot_SubscriptionShowsExistingUser(t *testing.T) {
 	br, userRepo, msgs := setupBot(t)
 	addr, _ := users.NewAddress(1, "Стрийська", "10")
-	userRepo.users[100] = &users.User{ID: 100, Address: addr}
+	userRepo.users[100] = users.NewUser(100, addr)
 
 	br.HandleMessage(makeCmd(100, "subscription"))
 
@@ -313,3 +313,74 @@ func TestBot_SubscriptionShowsExistingUser(t *testing.T) {
 	}
 	assert.True(t, found)
 }
+
+func TestBot_StatusCommand(t *testing.T) {
+	br, userRepo, msgs := setupBot(t)
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	userRepo.users[100] = users.NewUser(100, addr)
+
+	br.HandleMessage(makeCmd(100, "status"))
+
+	require.NotEmpty(t, *msgs)
+	last := (*msgs)[len(*msgs)-1]
+	assert.Equal(t, int64(100), last.ChatID)
+	assert.Equal(t, "Наразі немає відомих відключень за вашими адресами.", last.Text)
+}
+
+func TestBot_TypesCommand_ShowsKindOptions(t *testing.T) {
+	br, userRepo, msgs := setupBot(t)
+	addr, _ := users.NewAddress(1, "Стрийська", "10")
+	userRepo.users[100] = users.NewUser(100, addr)
+
+	br.HandleMessage(makeCmd(100, "types"))
+
+	state := br.GetState(100)
+	require.NotNil(t, state)
+	assert.Equal(t, subscription.StepSelectKinds, state.Step)
+
+	require.NotEmpty(t, *msgs)
+	last := (*msgs)[len(*msgs)-1]
+	var keyboard tgbotapi.ReplyKeyboardMarkup
+	require.NoError(t, json.Unmarshal([]byte(last.ReplyMarkup), &keyboard))
+	require.Len(t, keyboard.Keyboard, 3)
+	assert.Equal(t, "Зберегти", keyboard.Keyboard[2][0].Text)
+}
+
+func TestBot_RemoveCommand_ShowsAddressOptions(t *testing.T) {
+	br, userRepo, msgs := setupBot(t)
+	home, _ := users.NewAddress(1, "Стрийська", "10")
+	office, _ := users.NewAddress(2, "Наукова", "5")
+	userRepo.users[100] = users.NewUser(100, home).WithAddress(office)
+
+	br.HandleMessage(makeCmd(100, "remove"))
+
+	state := br.GetState(100)
+	require.NotNil(t, state)
+	assert.Equal(t, subscription.StepRemoveAddress, state.Step)
+
+	require.NotEmpty(t, *msgs)
+	last := (*msgs)[len(*msgs)-1]
+	var keyboard tgbotapi.ReplyKeyboardMarkup
+	require.NoError(t, json.Unmarshal([]byte(last.ReplyMarkup), &keyboard))
+	require.Len(t, keyboard.Keyboard, 2)
+	assert.Equal(t, "Наукова, 5", keyboard.Keyboard[1][0].Text)
+}
+
+func TestBot_SettingsCommand_PromptsForQuietHours(t *testing.T) {
+	br, userRepo, msgs := setupBot(t)
+	home, _ := users.NewAddress(1, "Стрийська", "10")
+	userRepo.users[100] = users.NewUser(100, home)
+
+	br.HandleMessage(makeCmd(100, "settings"))
+
+	state := br.GetState(100)
+	require.NotNil(t, state)
+	assert.Equal(t, subscription.StepQuietHours, state.Step)
+
+	require.NotEmpty(t, *msgs)
+	last := (*msgs)[len(*msgs)-1]
+	var keyboard tgbotapi.ReplyKeyboardMarkup
+	require.NoError(t, json.Unmarshal([]byte(last.ReplyMarkup), &keyboard))
+	require.Len(t, keyboard.Keyboard, 2)
+	assert.Equal(t, "Вимкнути", keyboard.Keyboard[1][0].Text)
+}
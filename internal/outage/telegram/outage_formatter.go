@@ -3,17 +3,131 @@ package telegram
 import (
 	"fmt"
 	"github.com/sl4wa/outages-bot/internal/outage/notifier"
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
 	"strings"
 )
 
+const notificationTimeLayout = "2006-01-02 15:04"
+
 func formatNotification(c notifier.Content) string {
+	return savedAddressLine(c.SavedAddress) + formatBody(c)
+}
+
+func formatBody(c notifier.Content) string {
+	switch c.Event {
+	case notifier.EventRestored:
+		return formatRestored(c)
+	case notifier.EventUpdate:
+		return formatUpdate(c)
+	case notifier.EventReminder:
+		return formatReminder(c)
+	case notifier.EventOverdue:
+		return formatOverdue(c)
+	case notifier.EventCatchUp:
+		return formatCatchUp(c)
+	default:
+		return formatOutage(c)
+	}
+}
+
+// formatCatchUp lists the notifications held back during quiet hours, oldest first.
+func formatCatchUp(c notifier.Content) string {
+	parts := make([]string, len(c.Items))
+	for i, item := range c.Items {
+		parts[i] = formatBody(item)
+	}
+	return "Поки тривали тихі години:\n\n" + strings.Join(parts, "\n\n")
+}
+
+// savedAddressLine names the subscriber's address the notification is about,
+// so users watching several addresses can tell them apart.
+func savedAddressLine(addr users.Address) string {
+	if addr.StreetName == "" {
+		return ""
+	}
+	return fmt.Sprintf("Ваша адреса: %s\n\n", addr.Label())
+}
+
+func formatOutage(c notifier.Content) string {
 	return fmt.Sprintf(
-		"Поточні відключення:\nМісто: %s\nВулиця: %s\n<b>%s – %s</b>\nКоментар: %s\nБудинки: %s",
+		"Поточні відключення:\nМісто: %s\nВулиця: %s\n<b>%s – %s</b>\n%sКоментар: %s\nБудинки: %s",
 		c.City,
 		c.StreetName,
-		c.Start.Format("2006-01-02 15:04"),
-		c.End.Format("2006-01-02 15:04"),
+		c.Start.Format(notificationTimeLayout),
+		c.End.Format(notificationTimeLayout),
+		kindLine(c.Kind),
 		c.Comment,
 		strings.Join(c.Buildings, ", "),
 	)
 }
+
+func formatRestored(c notifier.Content) string {
+	return fmt.Sprintf(
+		"Світло повернули!\nМісто: %s\nВулиця: %s\nВідключення було: <b>%s – %s</b>\nКоментар: %s",
+		c.City,
+		c.StreetName,
+		c.Start.Format(notificationTimeLayout),
+		c.End.Format(notificationTimeLayout),
+		c.Comment,
+	)
+}
+
+func formatUpdate(c notifier.Content) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Оновлення відключення:\nМісто: %s\nВулиця: %s\n<b>%s – %s</b>\n",
+		c.City,
+		c.StreetName,
+		c.Start.Format(notificationTimeLayout),
+		c.End.Format(notificationTimeLayout),
+	)
+	if !c.End.Equal(c.PreviousEnd) {
+		fmt.Fprintf(&b, "Відновлення перенесено: %s → <b>%s</b>\n",
+			c.PreviousEnd.Format(notificationTimeLayout),
+			c.End.Format(notificationTimeLayout),
+		)
+	}
+	b.WriteString(kindLine(c.Kind))
+	if c.Comment != c.PreviousComment {
+		fmt.Fprintf(&b, "Коментар: %s (було: %s)\n", c.Comment, c.PreviousComment)
+	} else {
+		fmt.Fprintf(&b, "Коментар: %s\n", c.Comment)
+	}
+	fmt.Fprintf(&b, "Будинки: %s", strings.Join(c.Buildings, ", "))
+	return b.String()
+}
+
+func formatReminder(c notifier.Content) string {
+	return fmt.Sprintf(
+		"Нагадування: відключення почнеться о <b>%s</b>\nМісто: %s\nВулиця: %s\n<b>%s – %s</b>\n%sКоментар: %s\nБудинки: %s",
+		c.Start.Format("15:04"),
+		c.City,
+		c.StreetName,
+		c.Start.Format(notificationTimeLayout),
+		c.End.Format(notificationTimeLayout),
+		kindLine(c.Kind),
+		c.Comment,
+		strings.Join(c.Buildings, ", "),
+	)
+}
+
+func formatOverdue(c notifier.Content) string {
+	return fmt.Sprintf(
+		"Відновлення затримується: світло планували повернути о <b>%s</b>\nМісто: %s\nВулиця: %s\n<b>%s – %s</b>\n%sКоментар: %s\nБудинки: %s",
+		c.End.Format("15:04"),
+		c.City,
+		c.StreetName,
+		c.Start.Format(notificationTimeLayout),
+		c.End.Format(notificationTimeLayout),
+		kindLine(c.Kind),
+		c.Comment,
+		strings.Join(c.Buildings, ", "),
+	)
+}
+
+func kindLine(kind outage.Kind) string {
+	if kind == outage.KindUnknown {
+		return ""
+	}
+	return fmt.Sprintf("Тип: %s\n", kind.Label())
+}
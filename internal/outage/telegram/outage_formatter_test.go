@@ -2,6 +2,8 @@ package telegram
 
 import (
 	"github.com/sl4wa/outages-bot/internal/outage/notifier"
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+	"github.com/sl4wa/outages-bot/internal/outage/users"
 	"testing"
 	"time"
 
@@ -70,3 +72,124 @@ func TestFormatNotification_BoldTagsPreserved(t *testing.T) {
 	assert.Contains(t, result, "<b>")
 	assert.Contains(t, result, "</b>")
 }
+
+func TestFormatNotification_WithKind(t *testing.T) {
+	c := makeContent(
+		"Львів", "Стрийська", []string{"10"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+		"Застосування ГАВ",
+	)
+	c.Kind = outage.KindEmergency
+	expected := "Поточні відключення:\nМісто: Львів\nВулиця: Стрийська\n<b>2024-01-15 08:00 – 2024-01-15 16:00</b>\nТип: Аварійне відключення (ГАВ)\nКоментар: Застосування ГАВ\nБудинки: 10"
+	assert.Equal(t, expected, formatNotification(c))
+}
+
+func TestFormatNotification_Restored(t *testing.T) {
+	c := makeContent(
+		"Львів", "Стрийська", []string{"10", "12"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+		"Планове відключення",
+	)
+	c.Event = notifier.EventRestored
+	expected := "Світло повернули!\nМісто: Львів\nВулиця: Стрийська\nВідключення було: <b>2024-01-15 08:00 – 2024-01-15 16:00</b>\nКоментар: Планове відключення"
+	assert.Equal(t, expected, formatNotification(c))
+}
+
+func TestFormatNotification_UpdateEndExtended(t *testing.T) {
+	c := makeContent(
+		"Львів", "Стрийська", []string{"10"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC),
+		"Планове відключення",
+	)
+	c.Event = notifier.EventUpdate
+	c.PreviousEnd = time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC)
+	c.PreviousComment = "Планове відключення"
+	expected := "Оновлення відключення:\nМісто: Львів\nВулиця: Стрийська\n<b>2024-01-15 08:00 – 2024-01-15 18:00</b>\nВідновлення перенесено: 2024-01-15 16:00 → <b>2024-01-15 18:00</b>\nКоментар: Планове відключення\nБудинки: 10"
+	assert.Equal(t, expected, formatNotification(c))
+}
+
+func TestFormatNotification_UpdateCommentChanged(t *testing.T) {
+	c := makeContent(
+		"Львів", "Стрийська", []string{"10"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+		"Застосування ГАВ",
+	)
+	c.Event = notifier.EventUpdate
+	c.PreviousEnd = c.End
+	c.PreviousComment = "Виясняється"
+	expected := "Оновлення відключення:\nМісто: Львів\nВулиця: Стрийська\n<b>2024-01-15 08:00 – 2024-01-15 16:00</b>\nКоментар: Застосування ГАВ (було: Виясняється)\nБудинки: 10"
+	assert.Equal(t, expected, formatNotification(c))
+}
+
+func TestFormatNotification_WithSavedAddress(t *testing.T) {
+	c := makeContent(
+		"Львів", "Стрийська", []string{"10", "12"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+		"Планове відключення",
+	)
+	c.SavedAddress = users.Address{StreetID: 1, StreetName: "Стрийська", Building: "12", City: "Львів"}
+	expected := "Ваша адреса: Львів, Стрийська, 12\n\nПоточні відключення:\nМісто: Львів\nВулиця: Стрийська\n<b>2024-01-15 08:00 – 2024-01-15 16:00</b>\nКоментар: Планове відключення\nБудинки: 10, 12"
+	assert.Equal(t, expected, formatNotification(c))
+}
+
+func TestFormatNotification_CatchUp(t *testing.T) {
+	announced := makeContent(
+		"Львів", "Стрийська", []string{"10"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+		"Планове відключення",
+	)
+	restored := announced
+	restored.Event = notifier.EventRestored
+	c := notifier.Content{
+		Event:        notifier.EventCatchUp,
+		SavedAddress: users.Address{StreetID: 1, StreetName: "Стрийська", Building: "10", City: "Львів"},
+		Items:        []notifier.Content{announced, restored},
+	}
+	expected := "Ваша адреса: Львів, Стрийська, 10\n\nПоки тривали тихі години:\n\n" +
+		"Поточні відключення:\nМісто: Львів\nВулиця: Стрийська\n<b>2024-01-15 08:00 – 2024-01-15 16:00</b>\nКоментар: Планове відключення\nБудинки: 10\n\n" +
+		"Світло повернули!\nМісто: Львів\nВулиця: Стрийська\nВідключення було: <b>2024-01-15 08:00 – 2024-01-15 16:00</b>\nКоментар: Планове відключення"
+	assert.Equal(t, expected, formatNotification(c))
+}
+
+func TestFormatNotification_WholeStreetListsBuildings(t *testing.T) {
+	c := makeContent(
+		"Львів", "Стрийська", []string{"10", "12", "14"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+		"Планове відключення",
+	)
+	c.SavedAddress = users.Address{StreetID: 1, StreetName: "Стрийська"}
+	result := formatNotification(c)
+	assert.Contains(t, result, "Ваша адреса: Стрийська (уся вулиця)\n")
+	assert.Contains(t, result, "Будинки: 10, 12, 14")
+}
+
+func TestFormatNotification_Reminder(t *testing.T) {
+	c := makeContent(
+		"Львів", "Стрийська", []string{"10", "12"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+		"Планове відключення",
+	)
+	c.Event = notifier.EventReminder
+	expected := "Нагадування: відключення почнеться о <b>08:00</b>\nМісто: Львів\nВулиця: Стрийська\n<b>2024-01-15 08:00 – 2024-01-15 16:00</b>\nКоментар: Планове відключення\nБудинки: 10, 12"
+	assert.Equal(t, expected, formatNotification(c))
+}
+
+func TestFormatNotification_Overdue(t *testing.T) {
+	c := makeContent(
+		"Львів", "Стрийська", []string{"10", "12"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+		"Аварійне відключення",
+	)
+	c.Event = notifier.EventOverdue
+	expected := "Відновлення затримується: світло планували повернути о <b>16:00</b>\nМісто: Львів\nВулиця: Стрийська\n<b>2024-01-15 08:00 – 2024-01-15 16:00</b>\nКоментар: Аварійне відключення\nБудинки: 10, 12"
+	assert.Equal(t, expected, formatNotification(c))
+}
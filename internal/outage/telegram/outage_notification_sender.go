@@ -5,10 +5,19 @@ import (
 	"fmt"
 	"github.com/sl4wa/outages-bot/internal/outage/notifier"
 	sharedtelegram "github.com/sl4wa/outages-bot/internal/shared/telegram"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// DeliveryLimits keeps notifications within the Bot API limits of about
+// 30 messages per second overall and one message per second per chat.
+var DeliveryLimits = notifier.DeliveryLimits{
+	Workers:         8,
+	Interval:        time.Second / 30,
+	PerChatInterval: time.Second,
+}
+
 // NotificationSender sends notifications via the Telegram Bot API.
 type NotificationSender struct {
 	bot *tgbotapi.BotAPI
@@ -22,11 +31,18 @@ func NewNotificationSender(bot *tgbotapi.BotAPI) *NotificationSender {
 // Send formats the notification content and sends it to the user via Telegram.
 func (s *NotificationSender) Send(userID int64, content notifier.Content) error {
 	text := formatNotification(content)
-	err := sharedtelegram.SendHTML(s.bot, userID, text)
+	send := sharedtelegram.SendHTML
+	if content.Silent {
+		send = sharedtelegram.SendSilentHTML
+	}
+	err := send(s.bot, userID, text)
 	if err != nil {
 		if errors.Is(err, sharedtelegram.ErrRecipientUnavailable) {
 			return notifier.ErrRecipientUnavailable
 		}
+		if after, ok := sharedtelegram.RetryAfter(err); ok {
+			return &notifier.RetryAfterError{After: after, Err: err}
+		}
 		return fmt.Errorf("telegram send: %w", err)
 	}
 	return nil
@@ -125,6 +125,28 @@ func TestSender_TooManyRequests429(t *testing.T) {
 	assert.False(t, errors.Is(err, notifier.ErrRecipientUnavailable))
 }
 
+func TestSender_TooManyRequests429_WithRetryAfter(t *testing.T) {
+	_, api := makeTelegramServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(429)
+		resp := tgbotapi.APIResponse{
+			Ok:          false,
+			ErrorCode:   429,
+			Description: "Too Many Requests: retry after 7",
+			Parameters:  &tgbotapi.ResponseParameters{RetryAfter: 7},
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	sender := NewNotificationSender(api)
+	err := sender.Send(100, testContent())
+	require.Error(t, err)
+
+	var throttled *notifier.RetryAfterError
+	require.True(t, errors.As(err, &throttled))
+	assert.Equal(t, 7*time.Second, throttled.After)
+	assert.False(t, errors.Is(err, notifier.ErrRecipientUnavailable))
+}
+
 func TestSender_NetworkError_Code0(t *testing.T) {
 	// Create a server that's immediately closed to simulate network error
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -188,3 +210,21 @@ func TestSender_MessageText(t *testing.T) {
 	expected := formatNotification(content)
 	assert.Equal(t, expected, capturedText)
 }
+
+func TestSender_SilentContent_DisablesNotification(t *testing.T) {
+	var disable []string
+	_, api := makeTelegramServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		disable = append(disable, r.Form.Get("disable_notification"))
+		resp := tgbotapi.APIResponse{Ok: true, Result: json.RawMessage(`{"message_id":1,"chat":{"id":100},"text":"test"}`)}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	sender := NewNotificationSender(api)
+	content := testContent()
+	require.NoError(t, sender.Send(100, content))
+	content.Silent = true
+	require.NoError(t, sender.Send(100, content))
+
+	assert.Equal(t, []string{"", "true"}, disable)
+}
@@ -0,0 +1,35 @@
+package telegram
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/sl4wa/outages-bot/internal/outage/notifier"
+)
+
+// PreviewSender writes the text of each notification to w instead of sending it.
+type PreviewSender struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewPreviewSender creates a PreviewSender writing to w.
+func NewPreviewSender(w io.Writer) *PreviewSender {
+	return &PreviewSender{w: w}
+}
+
+// Send prints the message chatID would get, as formatted for Telegram.
+func (s *PreviewSender) Send(chatID int64, content notifier.Content) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	header := fmt.Sprintf("--- chat %d", chatID)
+	if content.Silent {
+		header += " (silent)"
+	}
+	if _, err := fmt.Fprintf(s.w, "%s ---\n%s\n\n", header, formatNotification(content)); err != nil {
+		return fmt.Errorf("failed to print notification: %w", err)
+	}
+	return nil
+}
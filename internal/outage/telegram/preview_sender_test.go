@@ -0,0 +1,25 @@
+package telegram
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewSender_PrintsFormattedMessage(t *testing.T) {
+	var buf bytes.Buffer
+	content := makeContent(
+		"Львів", "Стрийська", []string{"10"},
+		time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+		"Планове відключення",
+	)
+	content.Silent = true
+
+	require.NoError(t, NewPreviewSender(&buf).Send(100, content))
+
+	assert.Equal(t, "--- chat 100 (silent) ---\n"+formatNotification(content)+"\n\n", buf.String())
+}
@@ -0,0 +1,19 @@
+package users
+
+import "strings"
+
+// City represents a city served by the bot, with its own street catalog.
+type City struct {
+	ID   int
+	Name string
+}
+
+// NameEquals checks if the city name equals the query (case-insensitive).
+func (c City) NameEquals(query string) bool {
+	return strings.ToLower(c.Name) == query
+}
+
+// NameContains checks if the city name contains the query (case-insensitive).
+func (c City) NameContains(query string) bool {
+	return strings.Contains(strings.ToLower(c.Name), query)
+}
@@ -7,4 +7,5 @@ var (
 	ErrEmptyStreetName       = errors.New("назва вулиці не може бути порожньою")
 	ErrEmptyBuilding         = errors.New("номер будинку не може бути порожнім")
 	ErrInvalidBuildingFormat = errors.New("невірний формат номера будинку, приклад: 13 або 13-А")
+	ErrInvalidQuietHours     = errors.New("невірний формат тихих годин, приклад: 23-7")
 )
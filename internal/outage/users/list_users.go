@@ -9,22 +9,22 @@ type UserLister interface {
 	FindAll() []*User
 }
 
-// ListUsers returns all users sorted by outage start date (descending), users without outage at the end.
+// ListUsers returns all users sorted by their latest outage start date (descending), users without outage at the end.
 func ListUsers(userRepo UserLister) []*User {
 	users := userRepo.FindAll()
 
 	sort.Slice(users, func(i, j int) bool {
-		a, b := users[i], users[j]
-		if a.OutageInfo == nil && b.OutageInfo == nil {
+		a, b := users[i].LatestOutageInfo(), users[j].LatestOutageInfo()
+		if a == nil && b == nil {
 			return false
 		}
-		if a.OutageInfo == nil {
+		if a == nil {
 			return false
 		}
-		if b.OutageInfo == nil {
+		if b == nil {
 			return true
 		}
-		return b.OutageInfo.Period.StartDate.Before(a.OutageInfo.Period.StartDate)
+		return b.Period.StartDate.Before(a.Period.StartDate)
 	})
 
 	return users
@@ -22,13 +22,12 @@ func makeAddr(t *testing.T) Address {
 	return addr
 }
 
-func makeOutageInfo(t *testing.T, start time.Time) *OutageInfo {
+func makeOutageInfo(t *testing.T, start time.Time) OutageInfo {
 	t.Helper()
 	period, err := outage.NewPeriod(start, start.Add(8*time.Hour))
 	require.NoError(t, err)
 	desc := outage.NewDescription("test")
-	info := NewOutageInfo(period, desc)
-	return &info
+	return NewOutageInfo(period, desc)
 }
 
 func TestListUsers_Empty(t *testing.T) {
@@ -44,8 +43,8 @@ func TestListUsers_SortedByOutageStartDescending(t *testing.T) {
 	addr := makeAddr(t)
 
 	repo := &mockListUserRepo{users: []*User{
-		{ID: 1, Address: addr, OutageInfo: makeOutageInfo(t, early)},
-		{ID: 2, Address: addr, OutageInfo: makeOutageInfo(t, late)},
+		{ID: 1, Addresses: []SavedAddress{{Address: addr, Notified: []OutageInfo{makeOutageInfo(t, early)}}}},
+		{ID: 2, Addresses: []SavedAddress{{Address: addr, Notified: []OutageInfo{makeOutageInfo(t, late)}}}},
 	}}
 
 	users := ListUsers(repo)
@@ -59,8 +58,8 @@ func TestListUsers_WithoutOutageSortedToEnd(t *testing.T) {
 	addr := makeAddr(t)
 
 	repo := &mockListUserRepo{users: []*User{
-		{ID: 1, Address: addr}, // no outage
-		{ID: 2, Address: addr, OutageInfo: makeOutageInfo(t, ts)}, // has outage
+		{ID: 1, Addresses: []SavedAddress{{Address: addr}}},                                                // no outage
+		{ID: 2, Addresses: []SavedAddress{{Address: addr, Notified: []OutageInfo{makeOutageInfo(t, ts)}}}}, // has outage
 	}}
 
 	users := ListUsers(repo)
@@ -73,8 +72,8 @@ func TestListUsers_AllWithoutOutage(t *testing.T) {
 	addr := makeAddr(t)
 
 	repo := &mockListUserRepo{users: []*User{
-		{ID: 1, Address: addr},
-		{ID: 2, Address: addr},
+		{ID: 1, Addresses: []SavedAddress{{Address: addr}}},
+		{ID: 2, Addresses: []SavedAddress{{Address: addr}}},
 	}}
 
 	users := ListUsers(repo)
@@ -1,11 +1,24 @@
 package users
 
-import "github.com/sl4wa/outages-bot/internal/outage/outage"
+import (
+	"slices"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+)
 
 // OutageInfo is a composite value object containing a period and description.
 type OutageInfo struct {
 	Period      outage.Period
 	Description outage.Description
+	// OutageID is the API ID of the outage the info was taken from, or 0 when unknown.
+	OutageID int
+	// Reminded is set once the pre-start reminder for the outage was sent.
+	Reminded bool
+	// OverdueAlerted is set once the user was told that restoration is overdue for the current planned end.
+	OverdueAlerted bool
+	// Buildings lists the buildings the user was told about. It is kept for whole-street
+	// addresses only, whose outages may grow as the feed lists further buildings.
+	Buildings []string
 }
 
 // NewOutageInfo creates a new OutageInfo.
@@ -13,7 +26,30 @@ func NewOutageInfo(period outage.Period, description outage.Description) OutageI
 	return OutageInfo{Period: period, Description: description}
 }
 
-// Equals checks if two OutageInfo values are equal.
+// Equals checks if two OutageInfo values are equal. The outage ID and alert states are not compared.
 func (i OutageInfo) Equals(other OutageInfo) bool {
 	return i.Period.Equals(other.Period) && i.Description.Equals(other.Description)
 }
+
+// SameOutage reports whether o is a possibly revised version of the outage the info was taken from.
+// IDs are compared when both are known; otherwise an outage starting at the same time is taken to be the same one.
+func (i OutageInfo) SameOutage(o *outage.Outage) bool {
+	if i.OutageID != 0 && o.ID != 0 {
+		return i.OutageID == o.ID
+	}
+	return i.Period.StartDate.Unix() == o.Period.StartDate.Unix()
+}
+
+// Covers reports whether the user was told about every building of o. Infos that keep
+// no buildings cover any outage.
+func (i OutageInfo) Covers(o *outage.Outage) bool {
+	if i.Buildings == nil {
+		return true
+	}
+	for _, b := range o.Address.Buildings {
+		if !slices.Contains(i.Buildings, b) {
+			return false
+		}
+	}
+	return true
+}
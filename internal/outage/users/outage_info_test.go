@@ -68,3 +68,19 @@ func TestOutageInfo_BothDifferent(t *testing.T) {
 	i2 := NewOutageInfo(p2, outage.NewDescription("test2"))
 	assert.False(t, i1.Equals(i2))
 }
+
+func TestOutageInfo_SameOutage(t *testing.T) {
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	p, _ := outage.NewPeriod(start, start.Add(8*time.Hour))
+	extended, _ := outage.NewPeriod(start, start.Add(10*time.Hour))
+	later, _ := outage.NewPeriod(start.Add(24*time.Hour), start.Add(32*time.Hour))
+
+	info := NewOutageInfo(p, outage.NewDescription("test"))
+	info.OutageID = 5
+	assert.True(t, info.SameOutage(&outage.Outage{ID: 5, Period: extended}))
+	assert.False(t, info.SameOutage(&outage.Outage{ID: 6, Period: p}))
+
+	legacy := NewOutageInfo(p, outage.NewDescription("test"))
+	assert.True(t, legacy.SameOutage(&outage.Outage{ID: 5, Period: extended}), "without an ID the start time decides")
+	assert.False(t, legacy.SameOutage(&outage.Outage{ID: 5, Period: later}))
+}
@@ -0,0 +1,91 @@
+package users
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuietMode selects what happens to notifications during quiet hours.
+type QuietMode int
+
+const (
+	// QuietSilent delivers notifications right away without sound.
+	QuietSilent QuietMode = iota
+	// QuietDefer holds notifications and delivers one catch-up when the window ends.
+	QuietDefer
+)
+
+var quietModeCodes = map[QuietMode]string{
+	QuietSilent: "silent",
+	QuietDefer:  "defer",
+}
+
+// ParseQuietMode parses a mode code as produced by String.
+func ParseQuietMode(code string) (QuietMode, bool) {
+	for m, c := range quietModeCodes {
+		if c == code {
+			return m, true
+		}
+	}
+	return QuietSilent, false
+}
+
+// String returns the stable code used in persisted files.
+func (m QuietMode) String() string {
+	if c, ok := quietModeCodes[m]; ok {
+		return c
+	}
+	return quietModeCodes[QuietSilent]
+}
+
+// QuietHours is a daily window, in whole hours of local time, during which
+// notifications are sent silently or deferred. The window may wrap past midnight.
+type QuietHours struct {
+	Start int // first quiet hour, 0-23
+	End   int // first hour after the window, 0-23
+	Mode  QuietMode
+}
+
+// NewQuietHours creates a new QuietHours with validation.
+func NewQuietHours(start, end int, mode QuietMode) (QuietHours, error) {
+	if start < 0 || start > 23 || end < 0 || end > 23 || start == end {
+		return QuietHours{}, ErrInvalidQuietHours
+	}
+	return QuietHours{Start: start, End: end, Mode: mode}, nil
+}
+
+// ParseQuietHoursRange parses a "start-end" hour range such as "23-7".
+func ParseQuietHoursRange(text string) (start, end int, err error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(text), "-")
+	if !ok {
+		return 0, 0, ErrInvalidQuietHours
+	}
+	start, err = strconv.Atoi(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, ErrInvalidQuietHours
+	}
+	end, err = strconv.Atoi(strings.TrimSpace(to))
+	if err != nil {
+		return 0, 0, ErrInvalidQuietHours
+	}
+	if _, err := NewQuietHours(start, end, QuietSilent); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// Contains reports whether t, in its own location, falls inside the window.
+func (q QuietHours) Contains(t time.Time) bool {
+	h := t.Hour()
+	if q.Start < q.End {
+		return h >= q.Start && h < q.End
+	}
+	return h >= q.Start || h < q.End
+}
+
+// Label returns the window as "HH:00–HH:00".
+func (q QuietHours) Label() string {
+	return fmt.Sprintf("%02d:00–%02d:00", q.Start, q.End)
+}
@@ -0,0 +1,66 @@
+package users
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func atHour(h int) time.Time {
+	return time.Date(2024, 1, 1, h, 30, 0, 0, time.UTC)
+}
+
+func TestNewQuietHours_Validation(t *testing.T) {
+	_, err := NewQuietHours(23, 7, QuietDefer)
+	require.NoError(t, err)
+
+	for _, tt := range [][2]int{{-1, 7}, {23, 24}, {8, 8}} {
+		_, err := NewQuietHours(tt[0], tt[1], QuietSilent)
+		assert.ErrorIs(t, err, ErrInvalidQuietHours, "%v", tt)
+	}
+}
+
+func TestQuietHours_Contains_WrapsMidnight(t *testing.T) {
+	q, _ := NewQuietHours(23, 7, QuietSilent)
+	assert.True(t, q.Contains(atHour(23)))
+	assert.True(t, q.Contains(atHour(3)))
+	assert.False(t, q.Contains(atHour(7)))
+	assert.False(t, q.Contains(atHour(12)))
+}
+
+func TestQuietHours_Contains_SameDay(t *testing.T) {
+	q, _ := NewQuietHours(13, 15, QuietSilent)
+	assert.False(t, q.Contains(atHour(12)))
+	assert.True(t, q.Contains(atHour(13)))
+	assert.True(t, q.Contains(atHour(14)))
+	assert.False(t, q.Contains(atHour(15)))
+}
+
+func TestParseQuietHoursRange(t *testing.T) {
+	start, end, err := ParseQuietHoursRange(" 23 - 7 ")
+	require.NoError(t, err)
+	assert.Equal(t, 23, start)
+	assert.Equal(t, 7, end)
+
+	for _, text := range []string{"", "23", "a-7", "23-b", "25-7", "7-7"} {
+		_, _, err := ParseQuietHoursRange(text)
+		assert.ErrorIs(t, err, ErrInvalidQuietHours, "%q", text)
+	}
+}
+
+func TestQuietMode_StringParseRoundTrip(t *testing.T) {
+	for _, m := range []QuietMode{QuietSilent, QuietDefer} {
+		parsed, ok := ParseQuietMode(m.String())
+		assert.True(t, ok)
+		assert.Equal(t, m, parsed)
+	}
+	_, ok := ParseQuietMode("loud")
+	assert.False(t, ok)
+}
+
+func TestQuietHours_Label(t *testing.T) {
+	q, _ := NewQuietHours(23, 7, QuietSilent)
+	assert.Equal(t, "23:00–07:00", q.Label())
+}
@@ -4,8 +4,9 @@ import "strings"
 
 // Street represents a street entity.
 type Street struct {
-	ID   int
-	Name string
+	ID     int
+	Name   string
+	CityID int
 }
 
 // NameContains checks if the street name contains the query (case-insensitive).
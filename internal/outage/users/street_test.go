@@ -32,3 +32,11 @@ func TestStreet_NameEqualsCaseInsensitive(t *testing.T) {
 	// "Наукова" lowered is "наукова", so comparing with lowercase query works
 	assert.False(t, s.NameEquals("НАУКОВА")) // name.ToLower is "наукова", query stays uppercase
 }
+
+func TestCity_NameMatching(t *testing.T) {
+	c := City{ID: 693, Name: "Львів"}
+	assert.True(t, c.NameEquals("львів"))
+	assert.False(t, c.NameEquals("льв"))
+	assert.True(t, c.NameContains("льв"))
+	assert.False(t, c.NameContains("винники"))
+}
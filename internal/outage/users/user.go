@@ -1,49 +1,307 @@
 package users
 
-import "github.com/sl4wa/outages-bot/internal/outage/outage"
+import (
+	"slices"
+	"time"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
+)
 
 // User represents a subscribed user.
 type User struct {
-	ID         int64
-	Address    Address
-	OutageInfo *OutageInfo
+	ID int64
+	// Addresses lists the watched addresses in the order they were added.
+	Addresses []SavedAddress
+	// Kinds limits notifications to the listed outage kinds. Empty means all kinds.
+	Kinds []outage.Kind
+	// QuietHours, when set, silences or defers notifications during a daily window.
+	QuietHours *QuietHours
+}
+
+// SavedAddress is a watched address together with the outages the user was notified about for it.
+type SavedAddress struct {
+	Address Address
+	// Notified lists the outages affecting the address that the user was notified about
+	// and that were still listed at the last check.
+	Notified []OutageInfo
+	// CatchUp is set when notifications for the address were held back during quiet hours.
+	CatchUp bool
+}
+
+// NewUser creates a user watching a single address.
+func NewUser(id int64, addr Address) *User {
+	return &User{ID: id, Addresses: []SavedAddress{{Address: addr}}}
+}
+
+// HasAddress reports whether the user already watches an address on the same street and building.
+func (u *User) HasAddress(addr Address) bool {
+	return slices.ContainsFunc(u.Addresses, func(a SavedAddress) bool {
+		return a.Address.StreetID == addr.StreetID && a.Address.Building == addr.Building
+	})
+}
+
+// WithAddress returns a new User with addr appended to the watched addresses.
+func (u *User) WithAddress(addr Address) *User {
+	updated := u.clone()
+	updated.Addresses = append(updated.Addresses, SavedAddress{Address: addr})
+	return updated
+}
+
+// WithoutAddress returns a new User without the address at index i.
+func (u *User) WithoutAddress(i int) *User {
+	updated := u.clone()
+	updated.Addresses = slices.Delete(updated.Addresses, i, i+1)
+	return updated
 }
 
-// WithNotifiedOutage returns a new User with the outage info set from the given outage.
-func (u *User) WithNotifiedOutage(current *outage.Outage) *User {
+// WithNotifiedOutage returns a new User recording that the user was notified about current for
+// the address at index i. An earlier version of the same outage is replaced; its reminder state
+// is kept unless the start time moved, its overdue alert state unless the end time moved.
+func (u *User) WithNotifiedOutage(i int, current *outage.Outage) *User {
 	info := NewOutageInfo(current.Period, current.Description)
-	return &User{
-		ID:         u.ID,
-		Address:    u.Address,
-		OutageInfo: &info,
+	info.OutageID = current.ID
+	if u.Addresses[i].Address.WholeStreet() {
+		info.Buildings = slices.Clone(current.Address.Buildings)
+	}
+	updated := u.clone()
+	notified := slices.Clone(updated.Addresses[i].Notified)
+	if j := notifiedIndex(notified, current); j >= 0 {
+		if notified[j].Period.StartDate.Equal(current.Period.StartDate) {
+			info.Reminded = notified[j].Reminded
+		}
+		if notified[j].Period.EndDate.Equal(current.Period.EndDate) {
+			info.OverdueAlerted = notified[j].OverdueAlerted
+		}
+		notified[j] = info
+	} else {
+		notified = append(notified, info)
 	}
+	updated.Addresses[i].Notified = notified
+	return updated
 }
 
-// FindOutageForNotification finds the first matching outage for a user that they haven't been notified about.
-func (u *User) FindOutageForNotification(allOutages []*outage.Outage) *outage.Outage {
-	for _, current := range allOutages {
-		if current.Address.StreetID != u.Address.StreetID {
-			continue
+// WithoutNotifiedOutage returns a new User that no longer tracks the given notified outage for the address at index i.
+func (u *User) WithoutNotifiedOutage(i int, info OutageInfo) *User {
+	updated := u.clone()
+	updated.Addresses[i].Notified = slices.DeleteFunc(slices.Clone(updated.Addresses[i].Notified), func(n OutageInfo) bool {
+		return n.OutageID == info.OutageID && n.Equals(info)
+	})
+	return updated
+}
+
+// WithCatchUp returns a new User with the catch-up flag of the address at index i set to pending.
+func (u *User) WithCatchUp(i int, pending bool) *User {
+	updated := u.clone()
+	updated.Addresses[i].CatchUp = pending
+	return updated
+}
+
+// WithReminded returns a new User with the notified outage o of the address at index i marked as reminded.
+func (u *User) WithReminded(i int, o *outage.Outage) *User {
+	updated := u.clone()
+	notified := slices.Clone(updated.Addresses[i].Notified)
+	if j := notifiedIndex(notified, o); j >= 0 {
+		notified[j].Reminded = true
+	}
+	updated.Addresses[i].Notified = notified
+	return updated
+}
+
+// ReminderDue reports whether o, which the user was notified about for the address at index i,
+// starts within the given lead time after now and no reminder has been sent for it yet.
+func (u *User) ReminderDue(i int, o *outage.Outage, now time.Time, before time.Duration) bool {
+	info := u.NotifiedAbout(i, o)
+	return info != nil && !info.Reminded && startsWithin(o.Period.StartDate, now, before)
+}
+
+// HasReminderDue reports whether any outage in current that the user was notified about and
+// wants to hear of is due a pre-start reminder.
+func (u *User) HasReminderDue(current []*outage.Outage, now time.Time, before time.Duration) bool {
+	return u.hasDue(current, func(i int, o *outage.Outage) bool { return u.ReminderDue(i, o, now, before) })
+}
+
+// WithOverdueAlerted returns a new User with the notified outage o of the address at index i
+// marked as alerted about its overdue restoration.
+func (u *User) WithOverdueAlerted(i int, o *outage.Outage) *User {
+	updated := u.clone()
+	notified := slices.Clone(updated.Addresses[i].Notified)
+	if j := notifiedIndex(notified, o); j >= 0 {
+		notified[j].OverdueAlerted = true
+	}
+	updated.Addresses[i].Notified = notified
+	return updated
+}
+
+// OverdueAlertDue reports whether o, which the user was notified about for the address at index i,
+// is still listed past its planned end and the user was not told about the delay yet.
+func (u *User) OverdueAlertDue(i int, o *outage.Outage, now time.Time) bool {
+	info := u.NotifiedAbout(i, o)
+	return info != nil && !info.OverdueAlerted && o.Period.Overdue(now)
+}
+
+// HasOverdueAlertDue reports whether any outage in current that the user was notified about and
+// wants to hear of passed its planned end without an overdue alert.
+func (u *User) HasOverdueAlertDue(current []*outage.Outage, now time.Time) bool {
+	return u.hasDue(current, func(i int, o *outage.Outage) bool { return u.OverdueAlertDue(i, o, now) })
+}
+
+// hasDue reports whether due holds for any outage in current of a kind the user wants at
+// any of the saved addresses. Notified outages that are gone or filtered out never count,
+// as no reminder or alert would be sent for them.
+func (u *User) hasDue(current []*outage.Outage, due func(i int, o *outage.Outage) bool) bool {
+	for i := range u.Addresses {
+		for _, o := range current {
+			if u.WantsKind(o.Kind) && due(i, o) {
+				return true
+			}
 		}
-		matchedBuilding := false
-		for _, building := range current.Address.Buildings {
-			if building == u.Address.Building {
-				matchedBuilding = true
-				break
+	}
+	return false
+}
+
+// HasCatchUp reports whether any address has a notification held back during quiet hours.
+func (u *User) HasCatchUp() bool {
+	return slices.ContainsFunc(u.Addresses, func(a SavedAddress) bool { return a.CatchUp })
+}
+
+// InQuietHours reports whether now falls inside the user's quiet hours.
+func (u *User) InQuietHours(now time.Time) bool {
+	return u.QuietHours != nil && u.QuietHours.Contains(now)
+}
+
+// DefersNotifications reports whether notifications to the user are held back at now.
+func (u *User) DefersNotifications(now time.Time) bool {
+	return u.InQuietHours(now) && u.QuietHours.Mode == QuietDefer
+}
+
+// LatestOutageInfo returns the notified outage with the latest start across all addresses, or nil.
+func (u *User) LatestOutageInfo() *OutageInfo {
+	var latest *OutageInfo
+	for _, a := range u.Addresses {
+		for j := range a.Notified {
+			if latest == nil || a.Notified[j].Period.StartDate.After(latest.Period.StartDate) {
+				latest = &a.Notified[j]
 			}
 		}
-		if !matchedBuilding {
+	}
+	return latest
+}
+
+// WantsKind reports whether the user is notified about outages of the given kind.
+// Outages of a kind the user cannot select, including unknown ones, are always delivered
+// since they cannot be filtered reliably.
+func (u *User) WantsKind(kind outage.Kind) bool {
+	if len(u.Kinds) == 0 || !slices.Contains(outage.SelectableKinds, kind) {
+		return true
+	}
+	return slices.Contains(u.Kinds, kind)
+}
+
+// FindOutagesForNotification returns every outage matching the address at index i that the user
+// hasn't been notified about in its current form, in feed order, as Unnotified does for the
+// outages found by AffectingOutages.
+func (u *User) FindOutagesForNotification(i int, allOutages []*outage.Outage) []*outage.Outage {
+	return u.Unnotified(i, u.AffectingOutages(i, allOutages))
+}
+
+// Unnotified returns the outages, as found by AffectingOutages for the address at index i, that
+// the user hasn't been notified about in their current form. An outage of a whole-street address
+// that lists buildings the user was not told about counts as changed.
+func (u *User) Unnotified(i int, affecting []*outage.Outage) []*outage.Outage {
+	notified := u.Addresses[i].Notified
+	return slices.DeleteFunc(slices.Clone(affecting), func(o *outage.Outage) bool {
+		info := NewOutageInfo(o.Period, o.Description)
+		return slices.ContainsFunc(notified, func(n OutageInfo) bool { return n.Equals(info) && n.Covers(o) })
+	})
+}
+
+// AffectingOutages returns every outage of a kind the user wants that affects the address at index i,
+// in feed order. Outages repeating the period and comment of one listed earlier, as the feed does
+// when it splits an outage's buildings across rows, are left out; for a whole-street address their
+// buildings are added to the outage listed first, which is then returned as a copy.
+func (u *User) AffectingOutages(i int, allOutages []*outage.Outage) []*outage.Outage {
+	var found []*outage.Outage
+	for _, current := range allOutages {
+		if !u.Addresses[i].Address.AffectedBy(current) || !u.WantsKind(current.Kind) {
 			continue
 		}
+		info := NewOutageInfo(current.Period, current.Description)
+		j := slices.IndexFunc(found, func(o *outage.Outage) bool {
+			return info.Equals(NewOutageInfo(o.Period, o.Description))
+		})
+		switch {
+		case j < 0:
+			found = append(found, current)
+		case u.Addresses[i].Address.WholeStreet():
+			found[j] = withBuildings(found[j], current.Address.Buildings)
+		}
+	}
+	return found
+}
 
-		outageInfo := NewOutageInfo(current.Period, current.Description)
+// NotifiedAbout returns what the user was told about o, or a previous version of it,
+// for the address at index i, or nil when they were not notified about it.
+func (u *User) NotifiedAbout(i int, o *outage.Outage) *OutageInfo {
+	saved := u.Addresses[i]
+	if !saved.Address.AffectedBy(o) {
+		return nil
+	}
+	if j := notifiedIndex(saved.Notified, o); j >= 0 {
+		info := saved.Notified[j]
+		return &info
+	}
+	return nil
+}
 
-		if u.OutageInfo != nil && u.OutageInfo.Equals(outageInfo) {
-			return nil
+// GoneOutages returns the notified outages of the address at index i for which neither
+// a version of the outage nor an identical one still affects the address.
+func (u *User) GoneOutages(i int, allOutages []*outage.Outage) []OutageInfo {
+	saved := u.Addresses[i]
+	var gone []OutageInfo
+	for _, info := range saved.Notified {
+		if !slices.ContainsFunc(allOutages, func(o *outage.Outage) bool {
+			return saved.Address.AffectedBy(o) && (info.SameOutage(o) || info.Equals(NewOutageInfo(o.Period, o.Description)))
+		}) {
+			gone = append(gone, info)
 		}
+	}
+	return gone
+}
+
+// Affected reports whether any of the outages affects the address at index i, whatever its kind.
+func (u *User) Affected(i int, allOutages []*outage.Outage) bool {
+	return slices.ContainsFunc(allOutages, u.Addresses[i].Address.AffectedBy)
+}
 
-		return current
+func (u *User) clone() *User {
+	return &User{
+		ID:         u.ID,
+		Addresses:  slices.Clone(u.Addresses),
+		Kinds:      u.Kinds,
+		QuietHours: u.QuietHours,
 	}
+}
 
-	return nil
+// withBuildings returns o with the buildings it does not list yet appended, as a copy when there are any.
+func withBuildings(o *outage.Outage, buildings []string) *outage.Outage {
+	merged := o.Address.Buildings
+	for _, b := range buildings {
+		if !slices.Contains(merged, b) {
+			merged = append(slices.Clip(merged), b)
+		}
+	}
+	if len(merged) == len(o.Address.Buildings) {
+		return o
+	}
+	copied := *o
+	copied.Address.Buildings = merged
+	return &copied
+}
+
+func notifiedIndex(notified []OutageInfo, o *outage.Outage) int {
+	return slices.IndexFunc(notified, func(info OutageInfo) bool { return info.SameOutage(o) })
+}
+
+func startsWithin(start, now time.Time, before time.Duration) bool {
+	return before > 0 && now.Before(start) && start.Sub(now) <= before
 }
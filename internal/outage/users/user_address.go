@@ -2,7 +2,10 @@ package users
 
 import (
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/sl4wa/outages-bot/internal/outage/outage"
 )
 
 var buildingPattern = regexp.MustCompile(`^[0-9]+(-[A-ZА-ЯІЇЄҐ])?$`)
@@ -11,7 +14,27 @@ var buildingPattern = regexp.MustCompile(`^[0-9]+(-[A-ZА-ЯІЇЄҐ])?$`)
 type Address struct {
 	StreetID   int
 	StreetName string
-	Building   string
+	// Building is empty for a whole-street address.
+	Building string
+	// City is the display name of the city; empty for subscriptions made before
+	// multiple cities were supported.
+	City string
+}
+
+// NewStreetAddress creates an Address covering every building on the street.
+func NewStreetAddress(streetID int, streetName string) (Address, error) {
+	if streetID <= 0 {
+		return Address{}, ErrInvalidStreetID
+	}
+
+	if strings.TrimSpace(streetName) == "" {
+		return Address{}, ErrEmptyStreetName
+	}
+
+	return Address{
+		StreetID:   streetID,
+		StreetName: streetName,
+	}, nil
 }
 
 // NewAddress creates a new Address with validation.
@@ -38,3 +61,33 @@ func NewAddress(streetID int, streetName, building string) (Address, error) {
 		Building:   building,
 	}, nil
 }
+
+// WholeStreet reports whether the address covers every building on the street.
+func (a Address) WholeStreet() bool {
+	return a.Building == ""
+}
+
+// AffectedBy reports whether the outage covers the address: the same street and, unless
+// the whole street is watched, the same building.
+func (a Address) AffectedBy(o *outage.Outage) bool {
+	if o.Address.StreetID != a.StreetID {
+		return false
+	}
+	return a.WholeStreet() || slices.Contains(o.Address.Buildings, a.Building)
+}
+
+// Label returns a one-line form of the address, prefixed with the city when known.
+func (a Address) Label() string {
+	if a.WholeStreet() {
+		label := a.StreetName + " (уся вулиця)"
+		if a.City != "" {
+			label = a.City + ", " + label
+		}
+		return label
+	}
+	parts := []string{a.StreetName, a.Building}
+	if a.City != "" {
+		parts = append([]string{a.City}, parts...)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -108,3 +108,28 @@ func TestAddress_ValidComplete(t *testing.T) {
 	assert.Equal(t, "Стрийська", addr.StreetName)
 	assert.Equal(t, "10-А", addr.Building)
 }
+
+func TestAddress_Label(t *testing.T) {
+	addr, err := NewAddress(1, "Стрийська", "10")
+	require.NoError(t, err)
+	assert.Equal(t, "Стрийська, 10", addr.Label())
+
+	addr.City = "Винники"
+	assert.Equal(t, "Винники, Стрийська, 10", addr.Label())
+}
+
+func TestNewStreetAddress(t *testing.T) {
+	addr, err := NewStreetAddress(1, "Стрийська")
+	require.NoError(t, err)
+	assert.True(t, addr.WholeStreet())
+	assert.Empty(t, addr.Building)
+	assert.Equal(t, "Стрийська (уся вулиця)", addr.Label())
+
+	addr.City = "Львів"
+	assert.Equal(t, "Львів, Стрийська (уся вулиця)", addr.Label())
+
+	_, err = NewStreetAddress(0, "Стрийська")
+	assert.ErrorIs(t, err, ErrInvalidStreetID)
+	_, err = NewStreetAddress(1, " ")
+	assert.ErrorIs(t, err, ErrEmptyStreetName)
+}
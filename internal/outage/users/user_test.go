@@ -13,7 +13,7 @@ func newTestUser(t *testing.T) *User {
 	t.Helper()
 	addr, err := NewAddress(1, "Стрийська", "10")
 	require.NoError(t, err)
-	return &User{ID: 12345, Address: addr, OutageInfo: nil}
+	return NewUser(12345, addr)
 }
 
 func makeOutage(t *testing.T, id int, streetID int, buildings []string, desc string) *outage.Outage {
@@ -36,135 +36,411 @@ func makeOutage(t *testing.T, id int, streetID int, buildings []string, desc str
 func TestUser_Create(t *testing.T) {
 	user := newTestUser(t)
 	assert.Equal(t, int64(12345), user.ID)
-	assert.Equal(t, "Стрийська", user.Address.StreetName)
-	assert.Nil(t, user.OutageInfo)
+	assert.Equal(t, "Стрийська", user.Addresses[0].Address.StreetName)
+	assert.Empty(t, user.Addresses[0].Notified)
 }
 
 func TestUser_WithNotifiedOutage(t *testing.T) {
 	user := newTestUser(t)
 	outage := makeOutage(t, 1, 1, []string{"10", "12"}, "Планове відключення")
-	updated := user.WithNotifiedOutage(outage)
-	assert.NotNil(t, updated.OutageInfo)
-	assert.Equal(t, outage.Period, updated.OutageInfo.Period)
-	assert.Equal(t, outage.Description, updated.OutageInfo.Description)
+	updated := user.WithNotifiedOutage(0, outage)
+	assert.Len(t, updated.Addresses[0].Notified, 1)
+	assert.Equal(t, outage.Period, updated.Addresses[0].Notified[0].Period)
+	assert.Equal(t, outage.Description, updated.Addresses[0].Notified[0].Description)
+	assert.Equal(t, 1, updated.Addresses[0].Notified[0].OutageID)
 	// Original user unchanged
-	assert.Nil(t, user.OutageInfo)
+	assert.Empty(t, user.Addresses[0].Notified)
 }
 
 func TestUser_WithNotifiedOutage_PreservesID(t *testing.T) {
 	user := newTestUser(t)
 	outage := makeOutage(t, 1, 1, []string{"10", "12"}, "Планове відключення")
-	updated := user.WithNotifiedOutage(outage)
+	updated := user.WithNotifiedOutage(0, outage)
 	assert.Equal(t, user.ID, updated.ID)
-	assert.Equal(t, user.Address, updated.Address)
+	assert.Equal(t, user.Addresses[0].Address, updated.Addresses[0].Address)
 }
 
 func TestUser_WithNotifiedOutage_PreservesAddress(t *testing.T) {
 	user := newTestUser(t)
 	outage := makeOutage(t, 1, 1, []string{"10", "12"}, "Планове відключення")
-	updated := user.WithNotifiedOutage(outage)
-	assert.Equal(t, user.Address.StreetID, updated.Address.StreetID)
-	assert.Equal(t, user.Address.Building, updated.Address.Building)
+	updated := user.WithNotifiedOutage(0, outage)
+	assert.Equal(t, user.Addresses[0].Address.StreetID, updated.Addresses[0].Address.StreetID)
+	assert.Equal(t, user.Addresses[0].Address.Building, updated.Addresses[0].Address.Building)
 }
 
-func TestUser_FindOutageForNotification_FindsMatchingOutage(t *testing.T) {
+func TestUser_FindOutagesForNotification_FindsMatchingOutage(t *testing.T) {
 	addr, _ := NewAddress(1, "Street", "10")
-	user := &User{ID: 1, Address: addr}
+	user := NewUser(1, addr)
 	outages := []*outage.Outage{makeOutage(t, 1, 1, []string{"10", "12"}, "test")}
 
-	result := user.FindOutageForNotification(outages)
-	assert.NotNil(t, result)
-	assert.Equal(t, 1, result.ID)
+	result := user.FindOutagesForNotification(0, outages)
+	require.Len(t, result, 1)
+	assert.Equal(t, 1, result[0].ID)
 }
 
-func TestUser_FindOutageForNotification_NoMatchReturnsNil(t *testing.T) {
+func TestUser_AffectingOutages_IncludesNotifiedAndSkipsRepeats(t *testing.T) {
+	addr, _ := NewAddress(1, "Street", "10")
+	notified := makeOutage(t, 1, 1, []string{"10"}, "test")
+	repeat := makeOutage(t, 2, 1, []string{"10", "12"}, "test")
+	other := makeOutage(t, 3, 1, []string{"12"}, "test")
+	user := NewUser(1, addr).WithNotifiedOutage(0, notified)
+
+	result := user.AffectingOutages(0, []*outage.Outage{notified, repeat, other})
+	assert.Equal(t, []*outage.Outage{notified}, result)
+}
+
+func TestUser_FindOutagesForNotification_NoMatchReturnsEmpty(t *testing.T) {
 	addr, _ := NewAddress(1, "Street", "14")
-	user := &User{ID: 1, Address: addr}
+	user := NewUser(1, addr)
 	outages := []*outage.Outage{makeOutage(t, 1, 1, []string{"10", "12"}, "test")}
 
-	result := user.FindOutageForNotification(outages)
-	assert.Nil(t, result)
+	assert.Empty(t, user.FindOutagesForNotification(0, outages))
 }
 
-func TestUser_FindOutageForNotification_AlreadyNotifiedReturnsNil(t *testing.T) {
+func TestUser_FindOutagesForNotification_AlreadyNotifiedSkipped(t *testing.T) {
 	addr, _ := NewAddress(1, "Street", "10")
 	current := makeOutage(t, 1, 1, []string{"10"}, "test")
 	info := NewOutageInfo(current.Period, current.Description)
-	user := &User{ID: 1, Address: addr, OutageInfo: &info}
-
-	result := user.FindOutageForNotification([]*outage.Outage{current})
-	assert.Nil(t, result)
-}
-
-func TestUser_FindOutageForNotification_MultipleMatching_ReturnsFirst(t *testing.T) {
-	addr, _ := NewAddress(1, "Street", "10")
-	user := &User{ID: 1, Address: addr}
-	o1 := makeOutage(t, 1, 1, []string{"10"}, "first")
-	o2 := makeOutage(t, 2, 1, []string{"10"}, "second")
+	user := &User{ID: 1, Addresses: []SavedAddress{{Address: addr, Notified: []OutageInfo{info}}}}
 
-	result := user.FindOutageForNotification([]*outage.Outage{o1, o2})
-	assert.NotNil(t, result)
-	assert.Equal(t, 1, result.ID)
+	assert.Empty(t, user.FindOutagesForNotification(0, []*outage.Outage{current}))
 }
 
-func TestUser_FindOutageForNotification_SameOutagesReversed_ReturnsDifferentFirst(t *testing.T) {
+func TestUser_FindOutagesForNotification_MultipleMatching_ReturnsAllInFeedOrder(t *testing.T) {
 	addr, _ := NewAddress(1, "Street", "10")
-	user := &User{ID: 1, Address: addr}
+	user := NewUser(1, addr)
 	o1 := makeOutage(t, 1, 1, []string{"10"}, "first")
 	o2 := makeOutage(t, 2, 1, []string{"10"}, "second")
 
-	result := user.FindOutageForNotification([]*outage.Outage{o2, o1})
-	assert.NotNil(t, result)
-	assert.Equal(t, 2, result.ID)
+	assert.Equal(t, []*outage.Outage{o1, o2}, user.FindOutagesForNotification(0, []*outage.Outage{o1, o2}))
+	assert.Equal(t, []*outage.Outage{o2, o1}, user.FindOutagesForNotification(0, []*outage.Outage{o2, o1}))
 }
 
-func TestUser_FindOutageForNotification_AlreadyNotifiedFirstMatch_SkipsSecondMatch(t *testing.T) {
-	addr, _ := NewAddress(1, "Street", "10")
+func TestUser_FindOutagesForNotification_OneNotified_ReturnsOnlyTheOther(t *testing.T) {
 	o1 := makeOutage(t, 1, 1, []string{"10"}, "first")
 	o2 := makeOutage(t, 2, 1, []string{"10"}, "second")
+	user := newTestUser(t).WithNotifiedOutage(0, o1)
 
-	// User was notified about outage 1
-	info := NewOutageInfo(o1.Period, o1.Description)
-	user := &User{ID: 1, Address: addr, OutageInfo: &info}
+	assert.Equal(t, []*outage.Outage{o2}, user.FindOutagesForNotification(0, []*outage.Outage{o1, o2}))
 
-	// Even though o2 also matches, finding o1 first (already notified) returns nil
-	result := user.FindOutageForNotification([]*outage.Outage{o1, o2})
-	assert.Nil(t, result)
+	// Once both are notified, neither fires again whatever their order.
+	user = user.WithNotifiedOutage(0, o2)
+	assert.Empty(t, user.FindOutagesForNotification(0, []*outage.Outage{o2, o1}))
+	assert.Empty(t, user.FindOutagesForNotification(0, []*outage.Outage{o1, o2}))
 }
 
-func TestUser_FindOutageForNotification_FirstOutageGone_SecondMatchFires(t *testing.T) {
-	addr, _ := NewAddress(1, "Street", "10")
-	o1 := makeOutage(t, 1, 1, []string{"10"}, "first")
-	o2 := makeOutage(t, 2, 1, []string{"10"}, "second")
+func TestUser_FindOutagesForNotification_RevisedOutageFires(t *testing.T) {
+	o := makeOutage(t, 1, 1, []string{"10"}, "test")
+	user := newTestUser(t).WithNotifiedOutage(0, o)
+	revised := makeOutage(t, 1, 1, []string{"10"}, "revised")
 
-	// User was notified about outage 1
-	info := NewOutageInfo(o1.Period, o1.Description)
-	user := &User{ID: 1, Address: addr, OutageInfo: &info}
-
-	// Outage 1 disappeared from the list — only o2 remains.
-	// Since o2 has a different description, it's not "already notified" → fires.
-	result := user.FindOutageForNotification([]*outage.Outage{o2})
-	assert.NotNil(t, result)
-	assert.Equal(t, 2, result.ID)
+	assert.Equal(t, []*outage.Outage{revised}, user.FindOutagesForNotification(0, []*outage.Outage{revised}))
+	require.NotNil(t, user.NotifiedAbout(0, revised))
+	assert.Equal(t, "test", user.NotifiedAbout(0, revised).Description.Value)
 }
 
-func TestUser_FindOutageForNotification_EmptyOutageList(t *testing.T) {
+func TestUser_FindOutagesForNotification_EmptyOutageList(t *testing.T) {
 	addr, _ := NewAddress(1, "Street", "10")
-	user := &User{ID: 1, Address: addr}
+	user := NewUser(1, addr)
 
-	result := user.FindOutageForNotification([]*outage.Outage{})
-	assert.Nil(t, result)
+	assert.Empty(t, user.FindOutagesForNotification(0, []*outage.Outage{}))
 }
 
-func TestUser_FindOutageForNotification_DifferentStreet_ThenAlreadyNotified(t *testing.T) {
+func TestUser_FindOutagesForNotification_DifferentStreet_ThenAlreadyNotified(t *testing.T) {
 	addr, _ := NewAddress(1, "Street", "10")
 	// Non-matching outage (different street), followed by matching but already notified
 	nonMatching := makeOutage(t, 1, 2, []string{"10"}, "other street")
 	matching := makeOutage(t, 2, 1, []string{"10"}, "notified")
 
 	info := NewOutageInfo(matching.Period, matching.Description)
-	user := &User{ID: 1, Address: addr, OutageInfo: &info}
+	user := &User{ID: 1, Addresses: []SavedAddress{{Address: addr, Notified: []OutageInfo{info}}}}
+
+	assert.Empty(t, user.FindOutagesForNotification(0, []*outage.Outage{nonMatching, matching}))
+}
+
+func TestUser_WithNotifiedOutage_PreservesKinds(t *testing.T) {
+	user := newTestUser(t)
+	user.Kinds = []outage.Kind{outage.KindEmergency}
+	updated := user.WithNotifiedOutage(0, makeOutage(t, 1, 1, []string{"10"}, "test"))
+	assert.Equal(t, []outage.Kind{outage.KindEmergency}, updated.Kinds)
+}
+
+func TestUser_WantsKind(t *testing.T) {
+	user := newTestUser(t)
+	assert.True(t, user.WantsKind(outage.KindHourly), "empty filter accepts every kind")
+
+	user.Kinds = []outage.Kind{outage.KindEmergency}
+	assert.True(t, user.WantsKind(outage.KindEmergency))
+	assert.False(t, user.WantsKind(outage.KindHourly))
+	assert.True(t, user.WantsKind(outage.KindUnknown), "unknown kind is never filtered out")
+	assert.True(t, user.WantsKind(outage.KindPlanned), "planned work cannot be selected yet, so it is never filtered out")
+}
+
+func TestUser_FindOutagesForNotification_SkipsUnwantedKind(t *testing.T) {
+	addr, _ := NewAddress(1, "Street", "10")
+	user := &User{ID: 1, Addresses: []SavedAddress{{Address: addr}}, Kinds: []outage.Kind{outage.KindEmergency}}
+	hourly := makeOutage(t, 1, 1, []string{"10"}, "hourly")
+	hourly.Kind = outage.KindHourly
+	emergency := makeOutage(t, 2, 1, []string{"10"}, "emergency")
+	emergency.Kind = outage.KindEmergency
+
+	assert.Equal(t, []*outage.Outage{emergency}, user.FindOutagesForNotification(0, []*outage.Outage{hourly, emergency}))
+}
+
+func TestUser_WithNotifiedOutage_ReplacesSameOutage(t *testing.T) {
+	user := newTestUser(t).
+		WithNotifiedOutage(0, makeOutage(t, 1, 1, []string{"10"}, "first")).
+		WithNotifiedOutage(0, makeOutage(t, 2, 1, []string{"10"}, "second"))
+	require.Len(t, user.Addresses[0].Notified, 2)
+
+	revised := user.WithNotifiedOutage(0, makeOutage(t, 1, 1, []string{"10"}, "revised"))
+	require.Len(t, revised.Addresses[0].Notified, 2)
+	assert.Equal(t, "revised", revised.Addresses[0].Notified[0].Description.Value)
+	assert.Equal(t, "first", user.Addresses[0].Notified[0].Description.Value, "original user unchanged")
+}
+
+func TestUser_WithoutNotifiedOutage(t *testing.T) {
+	user := newTestUser(t)
+	user.Kinds = []outage.Kind{outage.KindHourly}
+	notified := user.
+		WithNotifiedOutage(0, makeOutage(t, 1, 1, []string{"10"}, "first")).
+		WithNotifiedOutage(0, makeOutage(t, 2, 1, []string{"10"}, "second"))
+
+	cleared := notified.WithoutNotifiedOutage(0, notified.Addresses[0].Notified[0])
+	require.Len(t, cleared.Addresses[0].Notified, 1)
+	assert.Equal(t, 2, cleared.Addresses[0].Notified[0].OutageID)
+	assert.Equal(t, user.ID, cleared.ID)
+	assert.Equal(t, user.Addresses[0].Address, cleared.Addresses[0].Address)
+	assert.Equal(t, user.Kinds, cleared.Kinds)
+	assert.Len(t, notified.Addresses[0].Notified, 2)
+}
+
+func TestUser_GoneOutages(t *testing.T) {
+	o1 := makeOutage(t, 7, 1, []string{"10"}, "test")
+	o2 := makeOutage(t, 8, 1, []string{"10"}, "other")
+	user := newTestUser(t)
+	assert.Empty(t, user.GoneOutages(0, nil), "nothing notified")
+
+	user = user.WithNotifiedOutage(0, o1).WithNotifiedOutage(0, o2)
+	assert.Empty(t, user.GoneOutages(0, []*outage.Outage{o1, o2}))
+
+	gone := user.GoneOutages(0, []*outage.Outage{o2})
+	require.Len(t, gone, 1)
+	assert.Equal(t, 7, gone[0].OutageID)
+
+	otherBuilding := makeOutage(t, 8, 1, []string{"12"}, "other")
+	assert.Len(t, user.GoneOutages(0, []*outage.Outage{o1, otherBuilding}), 1, "no longer affecting the building")
+
+	relisted := makeOutage(t, 9, 1, []string{"10", "12"}, "other")
+	assert.Empty(t, user.GoneOutages(0, []*outage.Outage{o1, relisted}), "identical outage relisted under another ID")
+}
+
+func TestUser_GoneOutages_LegacyInfoWithoutID_MatchesByStart(t *testing.T) {
+	o := makeOutage(t, 7, 1, []string{"10"}, "test")
+	user := newTestUser(t)
+	user.Addresses[0].Notified = []OutageInfo{NewOutageInfo(o.Period, o.Description)}
+
+	assert.Empty(t, user.GoneOutages(0, []*outage.Outage{o}))
+	assert.Len(t, user.GoneOutages(0, nil), 1)
+}
+
+func TestUser_WithAddress_AppendsWithoutMutating(t *testing.T) {
+	user := newTestUser(t)
+	office, err := NewAddress(2, "Наукова", "5")
+	require.NoError(t, err)
+
+	updated := user.WithAddress(office)
+	require.Len(t, updated.Addresses, 2)
+	assert.Equal(t, office, updated.Addresses[1].Address)
+	assert.Empty(t, updated.Addresses[1].Notified)
+	assert.Len(t, user.Addresses, 1)
+}
+
+func TestUser_WithoutAddress(t *testing.T) {
+	office, _ := NewAddress(2, "Наукова", "5")
+	user := newTestUser(t).WithAddress(office)
+
+	updated := user.WithoutAddress(0)
+	require.Len(t, updated.Addresses, 1)
+	assert.Equal(t, office, updated.Addresses[0].Address)
+	assert.Len(t, user.Addresses, 2)
+}
+
+func TestUser_HasAddress(t *testing.T) {
+	user := newTestUser(t)
+	same, _ := NewAddress(1, "Стрийська", "10")
+	other, _ := NewAddress(1, "Стрийська", "12")
+	assert.True(t, user.HasAddress(same))
+	assert.False(t, user.HasAddress(other))
+}
+
+func TestUser_WithNotifiedOutage_OnlyTouchesGivenAddress(t *testing.T) {
+	office, _ := NewAddress(1, "Стрийська", "12")
+	user := newTestUser(t).WithAddress(office)
+
+	updated := user.WithNotifiedOutage(1, makeOutage(t, 3, 1, []string{"12"}, "test"))
+	assert.Empty(t, updated.Addresses[0].Notified)
+	require.Len(t, updated.Addresses[1].Notified, 1)
+	assert.Empty(t, user.Addresses[1].Notified, "original user unchanged")
+}
+
+func TestUser_FindOutagesForNotification_PerAddress(t *testing.T) {
+	office, _ := NewAddress(2, "Наукова", "5")
+	user := newTestUser(t).WithAddress(office)
+	atOffice := makeOutage(t, 1, 2, []string{"5"}, "test")
+
+	assert.Empty(t, user.FindOutagesForNotification(0, []*outage.Outage{atOffice}))
+	assert.Equal(t, []*outage.Outage{atOffice}, user.FindOutagesForNotification(1, []*outage.Outage{atOffice}))
+}
+
+func TestUser_LatestOutageInfo(t *testing.T) {
+	office, _ := NewAddress(2, "Наукова", "5")
+	user := newTestUser(t).WithAddress(office)
+	assert.Nil(t, user.LatestOutageInfo())
+
+	early := makeOutage(t, 1, 1, []string{"10"}, "early")
+	late := makeOutage(t, 2, 2, []string{"5"}, "late")
+	late.Period.StartDate = late.Period.StartDate.Add(time.Hour)
+	user = user.WithNotifiedOutage(0, early).WithNotifiedOutage(1, late)
+
+	require.NotNil(t, user.LatestOutageInfo())
+	assert.Equal(t, "late", user.LatestOutageInfo().Description.Value)
+}
+
+func TestUser_FindOutagesForNotification_WholeStreetMatchesAnyBuilding(t *testing.T) {
+	addr, err := NewStreetAddress(1, "Street")
+	require.NoError(t, err)
+	user := NewUser(1, addr)
+	otherStreet := makeOutage(t, 1, 2, []string{"10"}, "other")
+	anyBuilding := makeOutage(t, 2, 1, []string{"7", "9"}, "street")
+
+	assert.Equal(t, []*outage.Outage{anyBuilding}, user.FindOutagesForNotification(0, []*outage.Outage{otherStreet, anyBuilding}))
+}
+
+func TestUser_AffectingOutages_WholeStreetMergesSplitRows(t *testing.T) {
+	addr, err := NewStreetAddress(1, "Street")
+	require.NoError(t, err)
+	user := NewUser(1, addr)
+	first := makeOutage(t, 1, 1, []string{"31", "48"}, "street")
+	second := makeOutage(t, 2, 1, []string{"108"}, "street")
+	third := makeOutage(t, 3, 1, []string{"48", "12"}, "street")
+
+	found := user.AffectingOutages(0, []*outage.Outage{first, second, third})
+	require.Len(t, found, 1)
+	assert.Equal(t, 1, found[0].ID)
+	assert.Equal(t, []string{"31", "48", "108", "12"}, found[0].Address.Buildings)
+	assert.Equal(t, []string{"31", "48"}, first.Address.Buildings, "feed row left untouched")
+
+	building := newTestUser(t)
+	buildingRow := makeOutage(t, 4, 1, []string{"10", "12"}, "street")
+	assert.Equal(t, []*outage.Outage{buildingRow}, building.AffectingOutages(0, []*outage.Outage{buildingRow, makeOutage(t, 5, 1, []string{"10"}, "street")}),
+		"single-building addresses keep the first row")
+}
+
+func TestUser_FindOutagesForNotification_WholeStreetGrownBuildingsAreAChange(t *testing.T) {
+	addr, err := NewStreetAddress(1, "Street")
+	require.NoError(t, err)
+	first := makeOutage(t, 1, 1, []string{"31", "48"}, "street")
+	user := NewUser(1, addr).WithNotifiedOutage(0, first)
+	assert.Equal(t, []string{"31", "48"}, user.Addresses[0].Notified[0].Buildings)
+
+	assert.Empty(t, user.FindOutagesForNotification(0, []*outage.Outage{first}))
+	assert.Empty(t, user.FindOutagesForNotification(0, []*outage.Outage{makeOutage(t, 2, 1, []string{"48"}, "street")}), "fewer buildings are no news")
+
+	grown := user.FindOutagesForNotification(0, []*outage.Outage{first, makeOutage(t, 2, 1, []string{"12"}, "street")})
+	require.Len(t, grown, 1)
+	assert.Equal(t, []string{"31", "48", "12"}, grown[0].Address.Buildings)
+	assert.NotNil(t, user.NotifiedAbout(0, grown[0]), "reported as a revision of the notified outage")
+}
+
+func TestUser_InQuietHours(t *testing.T) {
+	user := newTestUser(t)
+	assert.False(t, user.InQuietHours(atHour(3)), "no quiet hours configured")
+
+	q, _ := NewQuietHours(23, 7, QuietDefer)
+	user.QuietHours = &q
+	assert.True(t, user.InQuietHours(atHour(3)))
+	assert.False(t, user.InQuietHours(atHour(9)))
+}
+
+func TestUser_CatchUp(t *testing.T) {
+	user := newTestUser(t).WithCatchUp(0, true)
+	assert.True(t, user.HasCatchUp())
+	assert.False(t, user.WithCatchUp(0, false).HasCatchUp())
+	assert.False(t, newTestUser(t).HasCatchUp())
+}
+
+func TestUser_ReminderDue(t *testing.T) {
+	o := makeOutage(t, 1, 1, []string{"10"}, "test") // starts at 08:00
+	user := newTestUser(t)
+	before := time.Date(2024, 1, 1, 7, 40, 0, 0, time.UTC)
+	assert.False(t, user.ReminderDue(0, o, before, 30*time.Minute), "nothing notified")
+
+	user = user.WithNotifiedOutage(0, o)
+	assert.True(t, user.ReminderDue(0, o, before, 30*time.Minute))
+	assert.True(t, user.HasReminderDue([]*outage.Outage{o}, before, 30*time.Minute))
+	assert.False(t, user.ReminderDue(0, o, before, 0), "reminders disabled")
+	assert.False(t, user.ReminderDue(0, o, before.Add(-time.Hour), 30*time.Minute), "too early")
+	assert.False(t, user.ReminderDue(0, o, before.Add(time.Hour), 30*time.Minute), "already started")
+
+	reminded := user.WithReminded(0, o)
+	assert.False(t, reminded.ReminderDue(0, o, before, 30*time.Minute))
+	assert.False(t, reminded.HasReminderDue([]*outage.Outage{o}, before, 30*time.Minute))
+	assert.False(t, user.Addresses[0].Notified[0].Reminded, "original left untouched")
+}
+
+func TestUser_WithNotifiedOutage_KeepsReminderForSameOutage(t *testing.T) {
+	o := makeOutage(t, 1, 1, []string{"10"}, "test")
+	user := newTestUser(t).WithNotifiedOutage(0, o).WithReminded(0, o)
+
+	revised := makeOutage(t, 1, 1, []string{"10"}, "revised")
+	assert.True(t, user.WithNotifiedOutage(0, revised).Addresses[0].Notified[0].Reminded)
+
+	other := makeOutage(t, 2, 1, []string{"10"}, "other")
+	updated := user.WithNotifiedOutage(0, other)
+	require.Len(t, updated.Addresses[0].Notified, 2)
+	assert.False(t, updated.Addresses[0].Notified[1].Reminded)
+}
+
+func TestUser_OverdueAlertDue(t *testing.T) {
+	o := makeOutage(t, 1, 1, []string{"10"}, "test") // planned to end at 16:00
+	user := newTestUser(t)
+	late := time.Date(2024, 1, 1, 16, 30, 0, 0, time.UTC)
+	assert.False(t, user.OverdueAlertDue(0, o, late), "nothing notified")
+
+	user = user.WithNotifiedOutage(0, o)
+	assert.True(t, user.OverdueAlertDue(0, o, late))
+	assert.True(t, user.HasOverdueAlertDue([]*outage.Outage{o}, late))
+	assert.False(t, user.OverdueAlertDue(0, o, late.Add(-time.Hour)), "not yet due to end")
+	assert.False(t, user.HasOverdueAlertDue([]*outage.Outage{o}, late.Add(-time.Hour)))
+
+	alerted := user.WithOverdueAlerted(0, o)
+	assert.False(t, alerted.OverdueAlertDue(0, o, late))
+	assert.False(t, alerted.HasOverdueAlertDue([]*outage.Outage{o}, late))
+	assert.False(t, user.Addresses[0].Notified[0].OverdueAlerted, "original left untouched")
+}
+
+func TestUser_HasOverdueAlertDue_IgnoresGoneAndUnwantedOutages(t *testing.T) {
+	o := makeOutage(t, 1, 1, []string{"10"}, "test")
+	o.Kind = outage.KindHourly
+	late := time.Date(2024, 1, 1, 16, 30, 0, 0, time.UTC)
+	user := newTestUser(t).WithNotifiedOutage(0, o)
+
+	assert.False(t, user.HasOverdueAlertDue(nil, late), "outage no longer listed")
+
+	user.Kinds = []outage.Kind{outage.KindEmergency}
+	assert.False(t, user.HasOverdueAlertDue([]*outage.Outage{o}, late), "kind filtered out")
+	assert.False(t, user.HasReminderDue([]*outage.Outage{o}, time.Date(2024, 1, 1, 7, 40, 0, 0, time.UTC), 30*time.Minute), "kind filtered out")
+}
+
+func TestUser_WithNotifiedOutage_ResetsOverdueAlertWhenEndMoves(t *testing.T) {
+	o := makeOutage(t, 1, 1, []string{"10"}, "test")
+	user := newTestUser(t).WithNotifiedOutage(0, o).WithOverdueAlerted(0, o)
+
+	revised := makeOutage(t, 1, 1, []string{"10"}, "revised")
+	assert.True(t, user.WithNotifiedOutage(0, revised).Addresses[0].Notified[0].OverdueAlerted, "same planned end")
 
-	result := user.FindOutageForNotification([]*outage.Outage{nonMatching, matching})
-	assert.Nil(t, result)
+	extended := makeOutage(t, 1, 1, []string{"10"}, "test")
+	extended.Period.EndDate = extended.Period.EndDate.Add(2 * time.Hour)
+	assert.False(t, user.WithNotifiedOutage(0, extended).Addresses[0].Notified[0].OverdueAlerted)
 }
@@ -3,6 +3,7 @@ package loe
 import (
 	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
@@ -12,8 +13,14 @@ import (
 const DefaultCacheFileName = "schedule.http-cache"
 
 type HTTPCache struct {
-	Path    string
-	Client  *http.Client
+	Path   string
+	Client *http.Client
+	// Policy controls retries of failed requests and the circuit breaker in front of the API.
+	Policy httpcache.Policy
+	// MaxStale, when positive, lets a cached response up to this old stand in for an unreachable API.
+	MaxStale time.Duration
+	// Archive, when set, keeps every changed response, including those rejected later.
+	Archive *httpcache.Archive
 	pending *httpcache.FetchResult
 }
 
@@ -21,19 +28,47 @@ func NewHTTPCache(path string) *HTTPCache {
 	return &HTTPCache{
 		Path:   path,
 		Client: &http.Client{Timeout: 30 * time.Second},
+		Policy: httpcache.DefaultPolicy(),
 	}
 }
 
 func (c *HTTPCache) Fetch(ctx context.Context, url string) (string, error) {
 	c.pending = nil
-	result, err := httpcache.Get(ctx, c.Client, url, c.Path)
+	policy := c.Policy
+	policy.StaleIfError = c.MaxStale > 0
+	var before httpcache.BreakerState
+	if policy.Breaker != nil {
+		before = policy.Breaker.State()
+	}
+	result, err := httpcache.Get(ctx, c.Client, url, c.Path, policy)
+	if policy.Breaker != nil {
+		if after := policy.Breaker.State(); after != before {
+			log.Printf("LOE API circuit breaker %s -> %s", before, after)
+		}
+	}
 	if err != nil {
 		return "", fmt.Errorf("LOE API request failed: %w", err)
 	}
 	switch {
+	case result.Stale:
+		age := result.Age.Round(time.Minute)
+		if result.Age > c.MaxStale {
+			return "", fmt.Errorf("LOE API request failed, cached response is %s old: %w", age, result.Err)
+		}
+		log.Printf("WARNING: LOE API request failed: %v; using cached response from %s ago", result.Err, age)
+		return string(result.Body), nil
 	case result.StatusCode == http.StatusNotModified:
+		if !result.Fresh {
+			// Saving the revalidated entry also moves older cache files to the current format.
+			c.pending = &result
+		}
 		return string(result.Body), nil
 	case result.StatusCode >= 200 && result.StatusCode <= 299:
+		if c.Archive != nil {
+			if err := c.Archive.Store(result.ETag, result.Body); err != nil {
+				log.Printf("WARNING: %v", err)
+			}
+		}
 		c.pending = &result
 		return string(result.Body), nil
 	default:
@@ -46,7 +81,7 @@ func (c *HTTPCache) Commit() error {
 		c.pending = nil
 		return nil
 	}
-	err := httpcache.Save(c.Path, c.pending.ETag, c.pending.Body)
+	err := httpcache.SaveEntry(c.Path, c.pending.Entry())
 	c.pending = nil
 	return err
 }
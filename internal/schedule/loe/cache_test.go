@@ -5,8 +5,10 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/sl4wa/outages-bot/internal/shared/httpcache"
 
@@ -71,6 +73,7 @@ func TestHTTPCacheFetcherError(t *testing.T) {
 	cache.Client = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
 		return nil, errors.New("network failure")
 	})}
+	cache.Policy = httpcache.Policy{}
 
 	_, err := cache.Fetch(context.Background(), "http://example.test")
 
@@ -78,8 +81,88 @@ func TestHTTPCacheFetcherError(t *testing.T) {
 	assert.Contains(t, err.Error(), "network failure")
 }
 
+func TestHTTPCacheServesStaleResponseWhenAPIIsDown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultCacheFileName)
+	require.NoError(t, httpcache.Save(path, `"etag1"`, []byte("cached body")))
+	cache := NewHTTPCache(path)
+	cache.Client = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("network failure")
+	})}
+	cache.Policy = httpcache.Policy{}
+	cache.MaxStale = time.Hour
+
+	body, err := cache.Fetch(context.Background(), "http://example.test")
+
+	require.NoError(t, err)
+	assert.Equal(t, "cached body", body)
+	require.NoError(t, cache.Commit())
+	assert.Equal(t, []byte("cached body"), httpcache.Load(path).Body)
+}
+
+func TestHTTPCacheRejectsTooOldStaleResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultCacheFileName)
+	entry := &httpcache.Entry{ETag: `"etag1"`, Body: []byte("cached body"), FetchedAt: time.Now().Add(-3 * time.Hour)}
+	require.NoError(t, httpcache.SaveEntry(path, entry))
+	cache := NewHTTPCache(path)
+	cache.Client = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("network failure")
+	})}
+	cache.Policy = httpcache.Policy{}
+	cache.MaxStale = time.Hour
+
+	_, err := cache.Fetch(context.Background(), "http://example.test")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "3h0m0s old")
+	assert.Contains(t, err.Error(), "network failure")
+}
+
+func TestHTTPCache304CommitMigratesLegacyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultCacheFileName)
+	require.NoError(t, os.WriteFile(path, []byte("\"etag1\"\ncached body"), 0o644))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+	cache := NewHTTPCache(path)
+	cache.Client = server.Client()
+
+	body, err := cache.Fetch(context.Background(), server.URL)
+
+	require.NoError(t, err)
+	assert.Equal(t, "cached body", body)
+	require.NoError(t, cache.Commit())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"version":2`)
+	assert.Equal(t, `"etag1"`, httpcache.Load(path).ETag)
+}
+
 type roundTripFunc func(*http.Request) (*http.Response, error)
 
 func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
 	return f(r)
 }
+
+func TestHTTPCacheArchivesFreshResponses(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer server.Close()
+	cache := NewHTTPCache(filepath.Join(dir, DefaultCacheFileName))
+	cache.Client = server.Client()
+	cache.Archive = httpcache.NewArchive(filepath.Join(dir, httpcache.ArchiveDirName), "schedule", httpcache.Retention{})
+
+	_, err := cache.Fetch(context.Background(), server.URL)
+	require.NoError(t, err)
+
+	archived, err := httpcache.ListArchive(filepath.Join(dir, httpcache.ArchiveDirName))
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	assert.Equal(t, "schedule", archived[0].Source)
+	entry, err := httpcache.LoadArchived(filepath.Join(dir, httpcache.ArchiveDirName), archived[0].File)
+	require.NoError(t, err)
+	assert.Equal(t, `"abc"`, entry.ETag)
+}
@@ -0,0 +1,165 @@
+package httpcache
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArchiveDirName is the directory under DATA_DIR holding archived responses.
+const ArchiveDirName = "archive"
+
+const (
+	archiveExt        = ".http-cache"
+	archiveTimeFormat = "20060102T150405.000Z"
+)
+
+// Retention limits how many archived responses of one source are kept.
+// Zero values keep everything.
+type Retention struct {
+	MaxAge   time.Duration
+	MaxCount int
+}
+
+// Archive keeps every distinct response body of one source as its own file, named after
+// the source and the time it was received, in the same format as the cache file.
+type Archive struct {
+	dir       string
+	source    string
+	retention Retention
+	now       func() time.Time
+}
+
+// ArchivedResponse describes one archived response as told by its file, without reading it.
+type ArchivedResponse struct {
+	File       string
+	Source     string
+	ReceivedAt time.Time
+	// Size is the size of the file, header included, in bytes.
+	Size int64
+}
+
+// NewArchive creates an Archive storing responses of source in dir.
+func NewArchive(dir, source string, retention Retention) *Archive {
+	return &Archive{dir: dir, source: source, retention: retention, now: time.Now}
+}
+
+// Sub returns an archive of a part of the source, such as one page of a paginated
+// response, kept next to it under the source name suffixed with name. Sub of nil is nil.
+func (a *Archive) Sub(name string) *Archive {
+	if a == nil {
+		return nil
+	}
+	return &Archive{dir: a.dir, source: a.source + "-" + name, retention: a.retention, now: a.now}
+}
+
+// Store archives body unless it repeats the latest archived response of the source,
+// by ETag or, without one, by content. Responses past the retention limits are removed.
+// Only the latest archived response is read.
+func (a *Archive) Store(etag string, body []byte) error {
+	existing, err := a.list()
+	if err != nil {
+		return err
+	}
+	if n := len(existing); n > 0 {
+		latest := Load(filepath.Join(a.dir, existing[n-1].File))
+		if latest != nil && (etag != "" && latest.ETag == etag || etag == "" && bytes.Equal(latest.Body, body)) {
+			return nil
+		}
+	}
+
+	receivedAt := a.now()
+	file := a.source + "-" + receivedAt.UTC().Format(archiveTimeFormat) + archiveExt
+	entry := &Entry{ETag: etag, Body: body, StatusCode: http.StatusOK, FetchedAt: receivedAt}
+	if err := SaveEntry(filepath.Join(a.dir, file), entry); err != nil {
+		return fmt.Errorf("failed to archive response: %w", err)
+	}
+	return a.prune(append(existing, ArchivedResponse{File: file, Source: a.source, ReceivedAt: receivedAt}))
+}
+
+// prune removes the responses of the source, listed oldest first in existing, that are
+// older than MaxAge or beyond the MaxCount most recent ones.
+func (a *Archive) prune(existing []ArchivedResponse) error {
+	cutoff := time.Time{}
+	if a.retention.MaxAge > 0 {
+		cutoff = a.now().Add(-a.retention.MaxAge)
+	}
+	for i, r := range existing {
+		tooMany := a.retention.MaxCount > 0 && i < len(existing)-a.retention.MaxCount
+		if tooMany || r.ReceivedAt.Before(cutoff) {
+			if err := os.Remove(filepath.Join(a.dir, r.File)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove archived response: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Archive) list() ([]ArchivedResponse, error) {
+	all, err := ListArchive(a.dir)
+	if err != nil {
+		return nil, err
+	}
+	var own []ArchivedResponse
+	for _, r := range all {
+		if r.Source == a.source {
+			own = append(own, r)
+		}
+	}
+	return own, nil
+}
+
+// ListArchive returns the responses archived in dir, oldest first, from their file names
+// and sizes alone. Files not named like archived responses are ignored.
+func ListArchive(dir string) ([]ArchivedResponse, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive directory: %w", err)
+	}
+
+	var archived []ArchivedResponse
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != archiveExt {
+			continue
+		}
+		stem := strings.TrimSuffix(name, archiveExt)
+		i := strings.LastIndex(stem, "-")
+		if i < 0 {
+			continue
+		}
+		receivedAt, err := time.Parse(archiveTimeFormat, stem[i+1:])
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		archived = append(archived, ArchivedResponse{File: name, Source: stem[:i], ReceivedAt: receivedAt, Size: info.Size()})
+	}
+	sort.SliceStable(archived, func(i, j int) bool {
+		return archived[i].ReceivedAt.Before(archived[j].ReceivedAt)
+	})
+	return archived, nil
+}
+
+// LoadArchived reads the archived response stored in file under dir.
+func LoadArchived(dir, file string) (*Entry, error) {
+	if file != filepath.Base(file) || filepath.Ext(file) != archiveExt {
+		return nil, fmt.Errorf("invalid archived response name %q", file)
+	}
+	entry := Load(filepath.Join(dir, file))
+	if entry == nil {
+		return nil, fmt.Errorf("archived response %s not found", file)
+	}
+	return entry, nil
+}
@@ -0,0 +1,140 @@
+package httpcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestArchive(t *testing.T, source string, retention Retention, now *time.Time) (*Archive, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), ArchiveDirName)
+	archive := NewArchive(dir, source, retention)
+	archive.now = func() time.Time { return *now }
+	return archive, dir
+}
+
+// archivedETags reads the ETags of the archived responses.
+func archivedETags(t *testing.T, dir string, archived []ArchivedResponse) []string {
+	t.Helper()
+	etags := make([]string, len(archived))
+	for i, r := range archived {
+		entry, err := LoadArchived(dir, r.File)
+		require.NoError(t, err)
+		etags[i] = entry.ETag
+	}
+	return etags
+}
+
+func TestArchive_StoresChangedResponses(t *testing.T) {
+	now := time.Date(2024, 11, 28, 9, 0, 0, 0, time.UTC)
+	archive, dir := newTestArchive(t, "outages", Retention{}, &now)
+
+	require.NoError(t, archive.Store(`"v1"`, []byte("first")))
+	now = now.Add(time.Minute)
+	require.NoError(t, archive.Store(`"v1"`, []byte("first")))
+	now = now.Add(time.Minute)
+	require.NoError(t, archive.Store(`"v2"`, []byte("second")))
+
+	archived, err := ListArchive(dir)
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	info, err := os.Stat(filepath.Join(dir, "outages-20241128T090000.000Z.http-cache"))
+	require.NoError(t, err)
+	assert.Equal(t, ArchivedResponse{
+		File:       "outages-20241128T090000.000Z.http-cache",
+		Source:     "outages",
+		ReceivedAt: time.Date(2024, 11, 28, 9, 0, 0, 0, time.UTC),
+		Size:       info.Size(),
+	}, archived[0])
+	assert.Equal(t, []string{`"v1"`, `"v2"`}, archivedETags(t, dir, archived))
+
+	entry, err := LoadArchived(dir, archived[1].File)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), entry.Body)
+}
+
+func TestArchive_WithoutETagComparesBodies(t *testing.T) {
+	now := time.Date(2024, 11, 28, 9, 0, 0, 0, time.UTC)
+	archive, dir := newTestArchive(t, "schedule", Retention{}, &now)
+
+	require.NoError(t, archive.Store("", []byte("same")))
+	now = now.Add(time.Minute)
+	require.NoError(t, archive.Store("", []byte("same")))
+	now = now.Add(time.Minute)
+	require.NoError(t, archive.Store("", []byte("changed")))
+
+	archived, err := ListArchive(dir)
+	require.NoError(t, err)
+	assert.Len(t, archived, 2)
+}
+
+func TestArchive_Retention(t *testing.T) {
+	now := time.Date(2024, 11, 28, 9, 0, 0, 0, time.UTC)
+	archive, dir := newTestArchive(t, "outages", Retention{MaxAge: 3 * time.Hour, MaxCount: 2}, &now)
+	other := NewArchive(dir, "outages-693", Retention{})
+	other.now = func() time.Time { return time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC) }
+	require.NoError(t, other.Store(`"x"`, []byte("other source")))
+
+	for i, body := range []string{"a", "b", "c"} {
+		require.NoError(t, archive.Store(body, []byte(body)), i)
+		now = now.Add(time.Hour)
+	}
+	archived, err := ListArchive(dir)
+	require.NoError(t, err)
+	require.Len(t, archived, 3, "the two newest of the source, and the other source untouched")
+	assert.Equal(t, "outages-693", archived[0].Source)
+	assert.Equal(t, []string{"b", "c"}, archivedETags(t, dir, archived[1:]))
+
+	now = now.Add(3 * time.Hour)
+	require.NoError(t, archive.Store("d", []byte("d")))
+	archived, err = ListArchive(dir)
+	require.NoError(t, err)
+	require.Len(t, archived, 2, "responses older than the max age are removed")
+	assert.Equal(t, []string{"d"}, archivedETags(t, dir, archived[1:]))
+}
+
+func TestArchive_Store_ReadsOnlyLatestResponse(t *testing.T) {
+	now := time.Date(2024, 11, 28, 9, 0, 0, 0, time.UTC)
+	archive, dir := newTestArchive(t, "outages", Retention{}, &now)
+	require.NoError(t, archive.Store(`"v1"`, []byte("first")))
+	now = now.Add(time.Minute)
+	require.NoError(t, archive.Store(`"v2"`, []byte("second")))
+
+	// An unreadable older response is neither read nor reported.
+	archived, err := ListArchive(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, archived[0].File), []byte("corrupt"), 0o644))
+	now = now.Add(time.Minute)
+	require.NoError(t, archive.Store(`"v2"`, []byte("second")))
+
+	archived, err = ListArchive(dir)
+	require.NoError(t, err)
+	assert.Len(t, archived, 2, "unchanged response not archived again")
+	assert.Equal(t, int64(len("corrupt")), archived[0].Size)
+}
+
+func TestListArchive_MissingDirAndForeignFiles(t *testing.T) {
+	archived, err := ListArchive(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, archived)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "outages-yesterday.http-cache"), []byte("x\ny"), 0o644))
+	archived, err = ListArchive(dir)
+	require.NoError(t, err)
+	assert.Empty(t, archived)
+}
+
+func TestLoadArchived_RejectsPathsAndMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	_, err := LoadArchived(dir, "../outages.http-cache")
+	assert.ErrorContains(t, err, "invalid archived response name")
+	_, err = LoadArchived(dir, "outages-20241128T090000.000Z.http-cache")
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,118 @@
+package httpcache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by Get while the breaker keeps requests from a failing upstream.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails requests without contacting the upstream.
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial request through to probe the upstream.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker stops requests to an upstream after a number of consecutive failed fetches and,
+// once a cooldown has passed, lets a single trial request through to probe it again.
+// It is safe for concurrent use.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+}
+
+// NewBreaker creates a closed Breaker opening after threshold consecutive failures for cooldown.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: max(threshold, 1), cooldown: cooldown, now: time.Now}
+}
+
+// DefaultBreaker returns a Breaker that stops contacting the upstream for five minutes after
+// five failed fetches in a row.
+func DefaultBreaker() *Breaker {
+	return NewBreaker(5, 5*time.Minute)
+}
+
+// State reports the current state. An open breaker whose cooldown has passed is half-open.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a request may be made, and returns ErrCircuitOpen otherwise.
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		retryAt := b.openedAt.Add(b.cooldown)
+		if b.now().Before(retryAt) {
+			return fmt.Errorf("%w until %s", ErrCircuitOpen, retryAt.Format(time.RFC3339))
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+	case BreakerHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%w: trial request in progress", ErrCircuitOpen)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record takes the outcome of an allowed request into account.
+func (b *Breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if ok {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release gives up an allowed request without taking its outcome into account.
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
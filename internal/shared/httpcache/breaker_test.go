@@ -0,0 +1,87 @@
+package httpcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBreakerOpensAfterFailuresAndProbesAfterCooldown(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	status := http.StatusBadGateway
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	policy := Policy{Attempts: 1, Breaker: breaker}
+	get := func() error {
+		_, err := Get(context.Background(), server.Client(), server.URL, "", policy)
+		return err
+	}
+
+	require.NoError(t, get())
+	assert.Equal(t, BreakerClosed, breaker.State())
+	require.NoError(t, get())
+	assert.Equal(t, BreakerOpen, breaker.State())
+
+	err := get()
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, calls)
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, BreakerHalfOpen, breaker.State())
+	require.NoError(t, get())
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, BreakerOpen, breaker.State(), "a failed trial reopens the breaker")
+
+	now = now.Add(time.Minute)
+	status = http.StatusOK
+	require.NoError(t, get())
+	assert.Equal(t, BreakerClosed, breaker.State())
+	require.NoError(t, get())
+	assert.Equal(t, 5, calls)
+}
+
+func TestBreakerAllowsASingleTrialWhenHalfOpen(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+	require.NoError(t, breaker.allow())
+	breaker.record(false)
+	now = now.Add(time.Minute)
+
+	require.NoError(t, breaker.allow())
+	assert.ErrorIs(t, breaker.allow(), ErrCircuitOpen)
+	breaker.record(true)
+	assert.NoError(t, breaker.allow())
+}
+
+func TestBreakerStateString(t *testing.T) {
+	assert.Equal(t, "closed", BreakerClosed.String())
+	assert.Equal(t, "open", BreakerOpen.String())
+	assert.Equal(t, "half-open", BreakerHalfOpen.String())
+}
+
+func TestGetStaleIfErrorServesCachedBodyWhileBreakerIsOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	require.NoError(t, Save(path, `"v1"`, []byte("cached")))
+	breaker := NewBreaker(1, time.Hour)
+	require.NoError(t, breaker.allow())
+	breaker.record(false)
+
+	result, err := Get(context.Background(), http.DefaultClient, "http://example.test", path, Policy{Breaker: breaker, StaleIfError: true})
+
+	require.NoError(t, err)
+	assert.True(t, result.Stale)
+	assert.ErrorIs(t, result.Err, ErrCircuitOpen)
+}
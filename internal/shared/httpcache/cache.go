@@ -2,26 +2,89 @@ package httpcache
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// formatVersion is written in the header line of every cache file. Files without a header
+// line are of the original "etag\nbody" format and are still read.
+const formatVersion = 2
+
+// Entry is a cached response body with what is needed to revalidate it.
 type Entry struct {
-	ETag string
-	Body []byte
+	ETag         string
+	LastModified string
+	Body         []byte
+	StatusCode   int
+	// FetchedAt is when the upstream last sent or confirmed the body.
+	FetchedAt time.Time
+	// MaxAge is how long after FetchedAt the body may be used without asking the upstream.
+	MaxAge time.Duration
+}
+
+// fresh reports whether the entry may be used at now without a request.
+func (e *Entry) fresh(now time.Time) bool {
+	return e.MaxAge > 0 && now.Before(e.FetchedAt.Add(e.MaxAge))
 }
 
 type FetchResult struct {
-	StatusCode int
-	Body       []byte
-	ETag       string
-	FromCache  bool
+	StatusCode   int
+	Body         []byte
+	ETag         string
+	LastModified string
+	FromCache    bool
+	// Fresh is set when the cached body was used without a request, as Cache-Control allowed.
+	Fresh bool
+	// FetchedAt and MaxAge describe the body for the cache, as in Entry.
+	FetchedAt time.Time
+	MaxAge    time.Duration
+	// Stale is set when the upstream could not be reached and Body is the cached one, Age old.
+	// Err is the failure the cached body stands in for. Stale results carry no status code.
+	Stale bool
+	Age   time.Duration
+	Err   error
+}
+
+// Entry returns the cache entry storing the result.
+func (r FetchResult) Entry() *Entry {
+	status := r.StatusCode
+	if r.FromCache {
+		status = http.StatusOK
+	}
+	return &Entry{
+		ETag:         r.ETag,
+		LastModified: r.LastModified,
+		Body:         r.Body,
+		StatusCode:   status,
+		FetchedAt:    r.FetchedAt,
+		MaxAge:       r.MaxAge,
+	}
 }
 
+// header is the first line of a cache file, followed by the body.
+type header struct {
+	Version      int       `json:"version"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StatusCode   int       `json:"status,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+	MaxAge       int64     `json:"max_age,omitempty"`
+	SHA256       string    `json:"sha256"`
+}
+
+// Load reads the entry cached at path. A missing, empty, corrupt or unknown-version file
+// reads as nil. Files of the original format are read with their modification time as the
+// fetch time and are rewritten in the current format on the next save.
 func Load(path string) *Entry {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -35,10 +98,53 @@ func Load(path string) *Entry {
 	if len(body) == 0 {
 		return nil
 	}
-	return &Entry{ETag: parts[0], Body: body}
+
+	if !strings.HasPrefix(parts[0], "{") {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil
+		}
+		return &Entry{ETag: parts[0], Body: body, StatusCode: http.StatusOK, FetchedAt: info.ModTime()}
+	}
+
+	var h header
+	if err := json.Unmarshal([]byte(parts[0]), &h); err != nil || h.Version != formatVersion {
+		return nil
+	}
+	if sum := sha256.Sum256(body); h.SHA256 != hex.EncodeToString(sum[:]) {
+		return nil
+	}
+	return &Entry{
+		ETag:         h.ETag,
+		LastModified: h.LastModified,
+		Body:         body,
+		StatusCode:   h.StatusCode,
+		FetchedAt:    h.FetchedAt,
+		MaxAge:       time.Duration(h.MaxAge) * time.Second,
+	}
 }
 
+// Save caches body under etag at path as fetched now.
 func Save(path string, etag string, body []byte) error {
+	return SaveEntry(path, &Entry{ETag: etag, Body: body, StatusCode: http.StatusOK, FetchedAt: time.Now()})
+}
+
+// SaveEntry atomically writes the entry to path.
+func SaveEntry(path string, e *Entry) error {
+	sum := sha256.Sum256(e.Body)
+	line, err := json.Marshal(header{
+		Version:      formatVersion,
+		ETag:         e.ETag,
+		LastModified: e.LastModified,
+		StatusCode:   e.StatusCode,
+		FetchedAt:    e.FetchedAt.UTC(),
+		MaxAge:       int64(e.MaxAge / time.Second),
+		SHA256:       hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		return err
+	}
+
 	if dir := filepath.Dir(path); dir != "." && dir != "" {
 		if err := os.MkdirAll(dir, 0o755); err != nil {
 			return err
@@ -46,7 +152,7 @@ func Save(path string, etag string, body []byte) error {
 	}
 
 	tmpPath := path + ".tmp"
-	if err := os.WriteFile(tmpPath, append([]byte(etag+"\n"), body...), 0o644); err != nil {
+	if err := os.WriteFile(tmpPath, append(append(line, '\n'), e.Body...), 0o644); err != nil {
 		return err
 	}
 	if err := os.Rename(tmpPath, path); err != nil {
@@ -56,41 +162,195 @@ func Save(path string, etag string, body []byte) error {
 	return nil
 }
 
-func Get(ctx context.Context, client *http.Client, url string, cachePath string) (FetchResult, error) {
+// maxAge reads how long a response may be reused without a request from its Cache-Control
+// header. no-cache and no-store, or no max-age, allow no reuse.
+func maxAge(h http.Header) time.Duration {
+	var age time.Duration
+	for _, directive := range strings.Split(h.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-cache", "no-store":
+			return 0
+		case "max-age":
+			if secs, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64); err == nil && secs > 0 {
+				age = time.Duration(secs) * time.Second
+			}
+		}
+	}
+	return age
+}
+
+// Policy controls how Get retries failed requests. The zero value makes a single attempt
+// without a circuit breaker.
+type Policy struct {
+	// Attempts is the maximum number of requests made for one Get.
+	Attempts int
+	// BaseDelay is the wait before the second attempt, doubled for every further one up to MaxDelay.
+	// The actual wait is jittered between half and all of it.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Breaker, when set, is shared by every Get to the same upstream.
+	Breaker *Breaker
+	// StaleIfError serves the cached body, marked stale, when the upstream stays unreachable,
+	// answers with 5xx or 429, or the breaker is open.
+	StaleIfError bool
+}
+
+// DefaultPolicy retries a failed request twice within a few seconds and stops contacting the
+// upstream for five minutes after five failed fetches in a row.
+func DefaultPolicy() Policy {
+	return Policy{
+		Attempts:  3,
+		BaseDelay: time.Second,
+		MaxDelay:  8 * time.Second,
+		Breaker:   DefaultBreaker(),
+	}
+}
+
+// delay returns the jittered wait before retry number attempt, counting from 1.
+func (p Policy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d <= 0) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryable reports whether a response status is worth asking again for.
+func retryable(status int) bool {
+	return status >= 500 || status == http.StatusTooManyRequests
+}
+
+// Get fetches url, sending the ETag cached at cachePath so that an unchanged body is answered
+// with 304 and served from the cache. Network errors, 5xx and 429 responses are retried as
+// the policy allows; the last response or error is returned once the attempts run out, unless
+// the policy allows falling back to a stale cached body.
+func Get(ctx context.Context, client *http.Client, url string, cachePath string, policy Policy) (FetchResult, error) {
 	var cache *Entry
 	if cachePath != "" {
 		cache = Load(cachePath)
 	}
+	if cache != nil && cache.fresh(time.Now()) {
+		return FetchResult{
+			StatusCode:   http.StatusNotModified,
+			Body:         cache.Body,
+			ETag:         cache.ETag,
+			LastModified: cache.LastModified,
+			FromCache:    true,
+			Fresh:        true,
+			FetchedAt:    cache.FetchedAt,
+			MaxAge:       cache.MaxAge,
+		}, nil
+	}
+
+	result, err := fetch(ctx, client, url, cache, policy)
+	if !policy.StaleIfError || cache == nil || ctx.Err() != nil {
+		return result, err
+	}
+	if err == nil && retryable(result.StatusCode) {
+		err = fmt.Errorf("status %d", result.StatusCode)
+	}
+	if err == nil {
+		return result, nil
+	}
+	return FetchResult{
+		Body:         cache.Body,
+		ETag:         cache.ETag,
+		LastModified: cache.LastModified,
+		FromCache:    true,
+		FetchedAt:    cache.FetchedAt,
+		Stale:        true,
+		Age:          max(time.Since(cache.FetchedAt), 0),
+		Err:          err,
+	}, nil
+}
+
+// fetch requests url within the policy's attempts and breaker.
+func fetch(ctx context.Context, client *http.Client, url string, cache *Entry, policy Policy) (FetchResult, error) {
+	if policy.Breaker != nil {
+		if err := policy.Breaker.allow(); err != nil {
+			return FetchResult{}, err
+		}
+	}
 
+	var result FetchResult
+	var err error
+	for attempt := 1; ; attempt++ {
+		var transient bool
+		result, transient, err = get(ctx, client, url, cache)
+		if !transient || attempt >= policy.Attempts || ctx.Err() != nil {
+			break
+		}
+		timer := time.NewTimer(policy.delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	switch {
+	case policy.Breaker == nil:
+	case ctx.Err() != nil:
+		// Interrupted by the caller; that says nothing about the upstream.
+		policy.Breaker.release()
+	default:
+		policy.Breaker.record(err == nil && !retryable(result.StatusCode))
+	}
+	return result, err
+}
+
+// get makes a single request and reports whether its failure, if any, may pass on a retry.
+func get(ctx context.Context, client *http.Client, url string, cache *Entry) (FetchResult, bool, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return FetchResult{}, fmt.Errorf("create request: %w", err)
+		return FetchResult{}, false, fmt.Errorf("create request: %w", err)
 	}
 	if cache != nil && cache.ETag != "" {
 		req.Header.Set("If-None-Match", cache.ETag)
 	}
+	if cache != nil && cache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.LastModified)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return FetchResult{}, fmt.Errorf("fetch: %w", err)
+		return FetchResult{}, true, fmt.Errorf("fetch: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotModified {
 		if cache == nil {
-			return FetchResult{}, fmt.Errorf("got 304 but no cached body")
+			return FetchResult{}, false, fmt.Errorf("got 304 but no cached body")
 		}
-		return FetchResult{StatusCode: resp.StatusCode, Body: cache.Body, ETag: cache.ETag, FromCache: true}, nil
+		return FetchResult{
+			StatusCode:   resp.StatusCode,
+			Body:         cache.Body,
+			ETag:         cache.ETag,
+			LastModified: cache.LastModified,
+			FromCache:    true,
+			FetchedAt:    time.Now(),
+			MaxAge:       maxAge(resp.Header),
+		}, false, nil
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return FetchResult{}, fmt.Errorf("read response body: %w", err)
+		return FetchResult{}, true, fmt.Errorf("read response body: %w", err)
 	}
 
 	return FetchResult{
-		StatusCode: resp.StatusCode,
-		Body:       body,
-		ETag:       resp.Header.Get("ETag"),
-	}, nil
+		StatusCode:   resp.StatusCode,
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+		MaxAge:       maxAge(resp.Header),
+	}, retryable(resp.StatusCode), nil
 }
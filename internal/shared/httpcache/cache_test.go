@@ -5,8 +5,11 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +30,131 @@ func TestLoadMalformedReturnsNil(t *testing.T) {
 	assert.Nil(t, Load(filepath.Join(t.TempDir(), "missing")))
 }
 
+func TestSaveEntryLoadKeepsMetadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	fetchedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	saved := &Entry{
+		ETag:         "\"odd\netag\"",
+		LastModified: "Thu, 01 Jan 2026 11:00:00 GMT",
+		Body:         []byte("line one\nline two"),
+		StatusCode:   http.StatusOK,
+		FetchedAt:    fetchedAt,
+		MaxAge:       90 * time.Second,
+	}
+
+	require.NoError(t, SaveEntry(path, saved))
+
+	assert.Equal(t, saved, Load(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), `{"version":2,`))
+}
+
+func TestLoadLegacyFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outages.http-cache")
+	require.NoError(t, os.WriteFile(path, []byte("\"abc\"\n{\"hydra:member\":[]}"), 0o644))
+	modTime := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	entry := Load(path)
+
+	require.NotNil(t, entry)
+	assert.Equal(t, `"abc"`, entry.ETag)
+	assert.Equal(t, []byte(`{"hydra:member":[]}`), entry.Body)
+	assert.True(t, modTime.Equal(entry.FetchedAt))
+	assert.Zero(t, entry.MaxAge)
+}
+
+func TestLoadRejectsCorruptOrUnknownEntries(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt")
+	require.NoError(t, Save(corrupt, `"abc"`, []byte("body")))
+	data, err := os.ReadFile(corrupt)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(corrupt, append(data, "tampered"...), 0o644))
+	newer := filepath.Join(dir, "newer")
+	require.NoError(t, os.WriteFile(newer, []byte(`{"version":3}`+"\nbody"), 0o644))
+
+	assert.Nil(t, Load(corrupt))
+	assert.Nil(t, Load(newer))
+}
+
+func TestGetSendsIfModifiedSince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	lastModified := "Thu, 01 Jan 2026 11:00:00 GMT"
+	require.NoError(t, SaveEntry(path, &Entry{LastModified: lastModified, Body: []byte("cached"), FetchedAt: time.Now()}))
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("If-Modified-Since")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	result, err := Get(context.Background(), server.Client(), server.URL, path, Policy{})
+
+	require.NoError(t, err)
+	assert.Equal(t, lastModified, got)
+	assert.Equal(t, lastModified, result.LastModified)
+	assert.Equal(t, []byte("cached"), result.Body)
+}
+
+func TestGetSkipsRequestWhileMaxAgeAllows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Header().Set("Last-Modified", "Thu, 01 Jan 2026 11:00:00 GMT")
+		_, _ = w.Write([]byte("fresh"))
+	}))
+	defer server.Close()
+
+	first, err := Get(context.Background(), server.Client(), server.URL, path, Policy{})
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, first.MaxAge)
+	require.NoError(t, SaveEntry(path, first.Entry()))
+
+	second, err := Get(context.Background(), server.Client(), server.URL, path, Policy{})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusNotModified, second.StatusCode)
+	assert.True(t, second.Fresh)
+	assert.True(t, second.FromCache)
+	assert.Equal(t, []byte("fresh"), second.Body)
+}
+
+func TestGetRevalidatesOnceMaxAgeHasPassed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	fetchedAt := time.Now().Add(-10 * time.Minute)
+	require.NoError(t, SaveEntry(path, &Entry{ETag: `"v1"`, Body: []byte("cached"), FetchedAt: fetchedAt, MaxAge: 5 * time.Minute}))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	result, err := Get(context.Background(), server.Client(), server.URL, path, Policy{})
+
+	require.NoError(t, err)
+	assert.False(t, result.Fresh)
+	assert.True(t, result.FetchedAt.After(fetchedAt))
+	assert.Equal(t, time.Minute, result.MaxAge)
+	assert.Equal(t, http.StatusOK, result.Entry().StatusCode)
+}
+
+func TestMaxAge(t *testing.T) {
+	header := func(v string) http.Header { return http.Header{"Cache-Control": []string{v}} }
+
+	assert.Equal(t, 60*time.Second, maxAge(header("max-age=60")))
+	assert.Equal(t, 60*time.Second, maxAge(header("public, Max-Age=\"60\"")))
+	assert.Zero(t, maxAge(header("no-cache, max-age=60")))
+	assert.Zero(t, maxAge(header("no-store")))
+	assert.Zero(t, maxAge(header("max-age=0")))
+	assert.Zero(t, maxAge(header("max-age=soon")))
+	assert.Zero(t, maxAge(http.Header{}))
+}
+
 func TestGet200ReturnsFreshBody(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("ETag", `"v1"`)
@@ -35,7 +163,7 @@ func TestGet200ReturnsFreshBody(t *testing.T) {
 	}))
 	defer server.Close()
 
-	result, err := Get(context.Background(), server.Client(), server.URL, "")
+	result, err := Get(context.Background(), server.Client(), server.URL, "", Policy{})
 
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusOK, result.StatusCode)
@@ -54,7 +182,7 @@ func TestGet304UsesCacheAndSendsETag(t *testing.T) {
 	}))
 	defer server.Close()
 
-	result, err := Get(context.Background(), server.Client(), server.URL, path)
+	result, err := Get(context.Background(), server.Client(), server.URL, path, Policy{})
 
 	require.NoError(t, err)
 	assert.Equal(t, `"v1"`, gotETag)
@@ -68,7 +196,7 @@ func TestGet304WithoutCacheFails(t *testing.T) {
 	}))
 	defer server.Close()
 
-	_, err := Get(context.Background(), server.Client(), server.URL, filepath.Join(t.TempDir(), "missing"))
+	_, err := Get(context.Background(), server.Client(), server.URL, filepath.Join(t.TempDir(), "missing"), Policy{})
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "304")
@@ -79,12 +207,154 @@ func TestGetNetworkError(t *testing.T) {
 		return nil, errors.New("network")
 	})}
 
-	_, err := Get(context.Background(), client, "http://example.test", "")
+	_, err := Get(context.Background(), client, "http://example.test", "", Policy{})
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "network")
 }
 
+func TestGetRetriesServerErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("fresh"))
+	}))
+	defer server.Close()
+
+	result, err := Get(context.Background(), server.Client(), server.URL, "", Policy{Attempts: 3, BaseDelay: time.Millisecond})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, []byte("fresh"), result.Body)
+}
+
+func TestGetRetriesNetworkErrors(t *testing.T) {
+	calls := 0
+	client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return nil, errors.New("network")
+	})}
+
+	_, err := Get(context.Background(), client, "http://example.test", "", Policy{Attempts: 3, BaseDelay: time.Millisecond})
+
+	require.Error(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestGetReturnsLastServerErrorOnceAttemptsRunOut(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	result, err := Get(context.Background(), server.Client(), server.URL, "", Policy{Attempts: 2, BaseDelay: time.Millisecond})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+}
+
+func TestGetDoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	result, err := Get(context.Background(), server.Client(), server.URL, "", Policy{Attempts: 3, BaseDelay: time.Millisecond})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+}
+
+func TestGetStopsRetryingWhenContextIsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		cancel()
+		return nil, errors.New("network")
+	})}
+	breaker := NewBreaker(1, time.Minute)
+
+	_, err := Get(ctx, client, "http://example.test", "", Policy{Attempts: 3, BaseDelay: time.Hour, Breaker: breaker})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, BreakerClosed, breaker.State())
+}
+
+func TestGetStaleIfErrorServesCachedBodyOnNetworkError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	entry := &Entry{ETag: `"v1"`, Body: []byte("cached"), FetchedAt: time.Now().Add(-10 * time.Minute)}
+	require.NoError(t, SaveEntry(path, entry))
+	client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("network")
+	})}
+
+	result, err := Get(context.Background(), client, "http://example.test", path, Policy{StaleIfError: true})
+
+	require.NoError(t, err)
+	assert.True(t, result.Stale)
+	assert.True(t, result.FromCache)
+	assert.Equal(t, []byte("cached"), result.Body)
+	assert.Equal(t, `"v1"`, result.ETag)
+	assert.InDelta(t, 10*time.Minute, result.Age, float64(time.Minute))
+	require.Error(t, result.Err)
+	assert.Contains(t, result.Err.Error(), "network")
+}
+
+func TestGetStaleIfErrorServesCachedBodyOnServerError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	require.NoError(t, Save(path, `"v1"`, []byte("cached")))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	result, err := Get(context.Background(), server.Client(), server.URL, path, Policy{StaleIfError: true})
+
+	require.NoError(t, err)
+	assert.True(t, result.Stale)
+	assert.Zero(t, result.StatusCode)
+	assert.Equal(t, []byte("cached"), result.Body)
+	assert.Contains(t, result.Err.Error(), "502")
+}
+
+func TestGetStaleIfErrorKeepsClientErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache")
+	require.NoError(t, Save(path, `"v1"`, []byte("cached")))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	result, err := Get(context.Background(), server.Client(), server.URL, path, Policy{StaleIfError: true})
+
+	require.NoError(t, err)
+	assert.False(t, result.Stale)
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+}
+
+func TestGetStaleIfErrorWithoutCacheFails(t *testing.T) {
+	client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("network")
+	})}
+
+	_, err := Get(context.Background(), client, "http://example.test", filepath.Join(t.TempDir(), "missing"), Policy{StaleIfError: true})
+
+	require.Error(t, err)
+}
+
 type roundTripFunc func(*http.Request) (*http.Response, error)
 
 func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -11,9 +12,19 @@ import (
 var ErrRecipientUnavailable = errors.New("recipient unavailable")
 
 func SendHTML(bot *tgbotapi.BotAPI, chatID int64, text string) error {
+	return sendHTML(bot, chatID, text, false)
+}
+
+// SendSilentHTML sends like SendHTML but without a notification sound.
+func SendSilentHTML(bot *tgbotapi.BotAPI, chatID int64, text string) error {
+	return sendHTML(bot, chatID, text, true)
+}
+
+func sendHTML(bot *tgbotapi.BotAPI, chatID int64, text string, silent bool) error {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
 	msg.DisableWebPagePreview = true
+	msg.DisableNotification = silent
 
 	_, err := bot.Send(msg)
 	if err != nil {
@@ -35,3 +46,12 @@ func NormalizeError(err error) error {
 	}
 	return fmt.Errorf("telegram send: %w", err)
 }
+
+// RetryAfter reports how long Telegram asked to wait when err is a 429 Too Many Requests response.
+func RetryAfter(err error) (time.Duration, bool) {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != 429 || apiErr.RetryAfter <= 0 {
+		return 0, false
+	}
+	return time.Duration(apiErr.RetryAfter) * time.Second, true
+}
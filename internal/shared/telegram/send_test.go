@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/stretchr/testify/assert"
@@ -47,3 +48,24 @@ func TestNormalizeErrorGenericErrorIsWrapped(t *testing.T) {
 	assert.False(t, errors.Is(err, ErrRecipientUnavailable))
 	assert.True(t, strings.HasPrefix(err.Error(), "telegram send:"), "got: %q", err.Error())
 }
+
+func TestRetryAfterFromTooManyRequests(t *testing.T) {
+	apiErr := &tgbotapi.Error{Code: 429, Message: "Too Many Requests: retry after 5"}
+	apiErr.RetryAfter = 5
+
+	after, ok := RetryAfter(NormalizeError(apiErr))
+
+	require.True(t, ok)
+	assert.Equal(t, 5*time.Second, after)
+}
+
+func TestRetryAfterOtherErrors(t *testing.T) {
+	_, ok := RetryAfter(NormalizeError(&tgbotapi.Error{Code: 429, Message: "Too Many Requests"}))
+	assert.False(t, ok, "no retry_after parameter")
+
+	_, ok = RetryAfter(NormalizeError(&tgbotapi.Error{Code: 400, Message: "bad request"}))
+	assert.False(t, ok)
+
+	_, ok = RetryAfter(errors.New("network down"))
+	assert.False(t, ok)
+}
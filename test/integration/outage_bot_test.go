@@ -91,14 +91,14 @@ func (s *BotSuite) TestSearchSaveVerifyFile() {
 	require.NoError(s.T(), err)
 	require.NotNil(s.T(), user)
 	assert.Equal(s.T(), int64(100), user.ID)
-	assert.Equal(s.T(), "Молдавська", user.Address.StreetName)
-	assert.Equal(s.T(), "25", user.Address.Building)
-	assert.Equal(s.T(), 12444, user.Address.StreetID)
+	assert.Equal(s.T(), "Молдавська", user.Addresses[0].Address.StreetName)
+	assert.Equal(s.T(), "25", user.Addresses[0].Address.Building)
+	assert.Equal(s.T(), 12444, user.Addresses[0].Address.StreetID)
 }
 
 func (s *BotSuite) TestShowSubscription_ExistingUser() {
 	addr, _ := users.NewAddress(12444, "Молдавська", "10")
-	s.userRepo.Save(&users.User{ID: 100, Address: addr})
+	s.userRepo.Save(users.NewUser(100, addr))
 
 	s.runner.HandleMessage(makeIntCmd(100, "start"))
 
@@ -117,7 +117,7 @@ func (s *BotSuite) TestShowSubscription_NewUser() {
 
 func (s *BotSuite) TestRemoveUser_FileDeleted() {
 	addr, _ := users.NewAddress(12444, "Молдавська", "10")
-	s.userRepo.Save(&users.User{ID: 100, Address: addr})
+	s.userRepo.Save(users.NewUser(100, addr))
 
 	s.runner.HandleMessage(makeIntCmd(100, "stop"))
 
@@ -126,6 +126,30 @@ func (s *BotSuite) TestRemoveUser_FileDeleted() {
 	assert.Nil(s.T(), user)
 }
 
+func (s *BotSuite) TestAddAndRemoveSecondAddress() {
+	addr, _ := users.NewAddress(12444, "Молдавська", "10")
+	s.userRepo.Save(users.NewUser(100, addr))
+
+	s.runner.HandleMessage(makeIntCmd(100, "start"))
+	s.runner.HandleMessage(makeIntMsg(100, "Молдавська"))
+	s.runner.HandleMessage(makeIntMsg(100, "25"))
+
+	user, err := s.userRepo.Find(100)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), user)
+	require.Len(s.T(), user.Addresses, 2)
+	assert.Equal(s.T(), "25", user.Addresses[1].Address.Building)
+
+	s.runner.HandleMessage(makeIntCmd(100, "remove"))
+	s.runner.HandleMessage(makeIntMsg(100, "Молдавська, 10"))
+
+	user, err = s.userRepo.Find(100)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), user)
+	require.Len(s.T(), user.Addresses, 1)
+	assert.Equal(s.T(), "25", user.Addresses[0].Address.Building)
+}
+
 func TestBotSuite(t *testing.T) {
 	suite.Run(t, new(BotSuite))
 }
@@ -13,6 +13,8 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -44,9 +46,12 @@ type NotifierSuite struct {
 	server     *httptest.Server
 	userRepo   *persistence.FileUserRepository
 	outageRepo *persistence.FileOutageRepository
+	outbox     *persistence.FileOutbox
 	dataDir    string
 	apiBody    string
 	sender     *mockNotifSender
+	// now is the notifier's clock, which decides when failed messages are retried.
+	now func() time.Time
 }
 
 func (s *NotifierSuite) SetupTest() {
@@ -55,7 +60,10 @@ func (s *NotifierSuite) SetupTest() {
 	s.userRepo, err = persistence.NewFileUserRepository(filepath.Join(s.dataDir, "users"))
 	require.NoError(s.T(), err)
 	s.outageRepo = persistence.NewFileOutageRepository(filepath.Join(s.dataDir, persistence.OutageSnapshotFileName))
+	s.outbox, err = persistence.NewFileOutbox(filepath.Join(s.dataDir, persistence.OutboxDirName))
+	require.NoError(s.T(), err)
 	s.sender = &mockNotifSender{errs: make(map[int64]error)}
+	s.now = time.Now
 	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Write([]byte(s.apiBody))
@@ -110,7 +118,7 @@ func (s *NotifierSuite) filterFixtureByComment(comment string) string {
 func (s *NotifierSuite) saveUser(chatID int64, streetID int, streetName, building string) {
 	addr, err := users.NewAddress(streetID, streetName, building)
 	require.NoError(s.T(), err)
-	user := &users.User{ID: chatID, Address: addr}
+	user := users.NewUser(chatID, addr)
 	require.NoError(s.T(), s.userRepo.Save(user))
 }
 
@@ -118,7 +126,9 @@ func (s *NotifierSuite) runPipeline() {
 	clock := func() time.Time { return time.Date(2024, 11, 28, 9, 0, 0, 0, time.UTC) }
 	apiProvider := loe.NewProvider(s.server.URL, clock, nil)
 	fetchService := outage.NewFetchOutages(apiProvider)
-	notifyUsers := notifier.NewNotifyUsers(fetchService, s.sender, s.userRepo, s.outageRepo, nil)
+	notifyUsers := notifier.NewNotifyUsers(fetchService, s.sender, s.userRepo, s.outageRepo, nil).
+		WithClock(s.now, time.Local).
+		WithOutbox(s.outbox)
 
 	err := notifyUsers.Handle(context.Background())
 	require.NoError(s.T(), err)
@@ -130,12 +140,15 @@ func (s *NotifierSuite) TestMatchingUser_NotificationSent() {
 
 	s.runPipeline()
 
-	assert.Len(s.T(), s.sender.sent, 1)
-	assert.Equal(s.T(), int64(100), s.sender.sent[0].UserID)
+	// Building 45 is covered by an emergency and an hourly outage, each listed under several IDs.
+	assert.Len(s.T(), s.sender.sent, 2)
+	for _, msg := range s.sender.sent {
+		assert.Equal(s.T(), int64(100), msg.UserID)
+	}
 
 	user, err := s.userRepo.Find(100)
 	require.NoError(s.T(), err)
-	require.NotNil(s.T(), user.OutageInfo)
+	require.Len(s.T(), user.Addresses[0].Notified, 2)
 }
 
 func (s *NotifierSuite) TestNonMatchingUser_NoNotification() {
@@ -148,7 +161,7 @@ func (s *NotifierSuite) TestNonMatchingUser_NoNotification() {
 
 	user, err := s.userRepo.Find(100)
 	require.NoError(s.T(), err)
-	assert.Nil(s.T(), user.OutageInfo)
+	assert.Empty(s.T(), user.Addresses[0].Notified)
 }
 
 func (s *NotifierSuite) TestBlockedUser_FileDeleted() {
@@ -173,7 +186,25 @@ func (s *NotifierSuite) TestNonBlockedError_UserNotRemoved() {
 	user, err := s.userRepo.Find(100)
 	require.NoError(s.T(), err)
 	assert.NotNil(s.T(), user)
-	assert.Nil(s.T(), user.OutageInfo)
+	pending, err := s.outbox.Pending()
+	require.NoError(s.T(), err)
+	require.Len(s.T(), pending, 2, "failed messages stay queued")
+	assert.Equal(s.T(), 1, pending[0].Attempts)
+	assert.Equal(s.T(), 0, pending[1].Attempts, "not sent ahead of the failed message")
+
+	// A run before the backoff has elapsed leaves them queued.
+	delete(s.sender.errs, 100)
+	s.sender.sent = nil
+	s.runPipeline()
+	assert.Empty(s.T(), s.sender.sent)
+
+	// A later run retries them although the outage data is unchanged.
+	s.now = func() time.Time { return time.Now().Add(time.Hour) }
+	s.runPipeline()
+	assert.Len(s.T(), s.sender.sent, 2)
+	pending, err = s.outbox.Pending()
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), pending)
 }
 
 func (s *NotifierSuite) TestDedup_SecondRunSendsNothing() {
@@ -181,7 +212,7 @@ func (s *NotifierSuite) TestDedup_SecondRunSendsNothing() {
 	s.saveUser(100, 12445, "Стрийська", "45")
 
 	s.runPipeline()
-	assert.Len(s.T(), s.sender.sent, 1)
+	assert.Len(s.T(), s.sender.sent, 2)
 
 	s.sender.sent = nil
 	s.runPipeline()
@@ -196,28 +227,33 @@ func (s *NotifierSuite) TestMultipleUsers_CorrectSubsetNotified() {
 
 	s.runPipeline()
 
-	assert.Len(s.T(), s.sender.sent, 2)
 	sentIDs := make([]int64, len(s.sender.sent))
 	for i, msg := range s.sender.sent {
 		sentIDs[i] = msg.UserID
 	}
-	assert.Contains(s.T(), sentIDs, int64(100))
-	assert.Contains(s.T(), sentIDs, int64(300))
+	assert.ElementsMatch(s.T(), []int64{100, 100, 300, 300}, sentIDs)
 }
 
-func (s *NotifierSuite) TestFirstMatchingOutageSelected() {
+func (s *NotifierSuite) TestEveryDistinctOutageNotified() {
 	s.loadFixture()
 	s.saveUser(100, 12445, "Стрийська", "45")
 
 	s.runPipeline()
 
-	require.Len(s.T(), s.sender.sent, 1)
+	require.Len(s.T(), s.sender.sent, 2)
+	// Fixture iteration order: the standalone "45" emergency row comes first,
+	// followed by the hourly outage covering the street.
 	content := s.sender.sent[0].Content
-	// The first entry covering building 45 in fixture iteration order is the
-	// standalone "45" row: 2024-11-28T06:33:00+00:00 → 2024-11-28T10:57:00+00:00
 	assert.Equal(s.T(), time.Date(2024, 11, 28, 6, 33, 0, 0, time.UTC).Unix(), content.Start.Unix())
 	assert.Equal(s.T(), time.Date(2024, 11, 28, 10, 57, 0, 0, time.UTC).Unix(), content.End.Unix())
 	assert.Equal(s.T(), "Застосування ГАВ", content.Comment)
+	assert.Equal(s.T(), outage.KindEmergency, content.Kind)
+
+	content = s.sender.sent[1].Content
+	assert.Equal(s.T(), time.Date(2024, 11, 28, 8, 0, 0, 0, time.UTC).Unix(), content.Start.Unix())
+	assert.Equal(s.T(), time.Date(2024, 11, 28, 10, 0, 0, 0, time.UTC).Unix(), content.End.Unix())
+	assert.Equal(s.T(), "Застосування ГПВ", content.Comment)
+	assert.Equal(s.T(), outage.KindHourly, content.Kind)
 }
 
 func (s *NotifierSuite) TestNewOutageAfterPriorNotification() {
@@ -233,7 +269,7 @@ func (s *NotifierSuite) TestNewOutageAfterPriorNotification() {
 	assert.Equal(s.T(), time.Date(2024, 11, 28, 10, 0, 0, 0, time.UTC).Unix(), content.End.Unix())
 	assert.Equal(s.T(), "Застосування ГАВ", content.Comment)
 
-	// Second run with ГПВ entries only
+	// Second run with ГПВ entries only: the building is still out, so no restoration notice precedes the new outage
 	s.sender.sent = nil
 	s.loadFixture()
 	s.apiBody = s.filterFixtureByComment("Застосування ГПВ")
@@ -246,6 +282,77 @@ func (s *NotifierSuite) TestNewOutageAfterPriorNotification() {
 	assert.Equal(s.T(), "Застосування ГПВ", content.Comment)
 }
 
+// withoutFixtureRows returns the fixture without the rows of the given IDs.
+func (s *NotifierSuite) withoutFixtureRows(ids ...int) string {
+	var resp map[string]any
+	require.NoError(s.T(), json.Unmarshal([]byte(s.apiBody), &resp))
+	members := resp["hydra:member"].([]any)
+	kept := members[:0]
+	for _, m := range members {
+		if !slices.Contains(ids, int(m.(map[string]any)["id"].(float64))) {
+			kept = append(kept, m)
+		}
+	}
+	resp["hydra:member"] = kept
+	data, err := json.Marshal(resp)
+	require.NoError(s.T(), err)
+	return string(data)
+}
+
+func (s *NotifierSuite) TestWholeStreet_ListsBuildingsOfEveryRowOfAnOutage() {
+	s.loadFixture()
+	addr, err := users.NewStreetAddress(12445, "Стрийська")
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), s.userRepo.Save(users.NewUser(100, addr)))
+	// The 06:47–10:00 outage is split across rows listing "31, 48, …", "108" and "12";
+	// the last one is not listed yet.
+	full := s.apiBody
+	s.apiBody = s.withoutFixtureRows(127843004)
+
+	s.runPipeline()
+
+	require.Len(s.T(), s.sender.sent, 3, "one message per outage, not per row")
+	content := s.sender.sent[1].Content
+	assert.Equal(s.T(), time.Date(2024, 11, 28, 6, 47, 0, 0, time.UTC).Unix(), content.Start.Unix())
+	assert.Contains(s.T(), content.Buildings, "31")
+	assert.Contains(s.T(), content.Buildings, "108")
+	assert.NotContains(s.T(), content.Buildings, "12")
+
+	// The feed adds building 12 to the outage: the subscriber is told.
+	s.sender.sent = nil
+	s.apiBody = full
+	s.runPipeline()
+
+	require.Len(s.T(), s.sender.sent, 1)
+	content = s.sender.sent[0].Content
+	assert.Equal(s.T(), notifier.EventUpdate, content.Event)
+	assert.Equal(s.T(), time.Date(2024, 11, 28, 6, 47, 0, 0, time.UTC).Unix(), content.Start.Unix())
+	assert.Contains(s.T(), content.Buildings, "12")
+	assert.Contains(s.T(), content.Buildings, "108")
+
+	s.sender.sent = nil
+	s.runPipeline()
+	assert.Empty(s.T(), s.sender.sent)
+}
+
+func (s *NotifierSuite) TestRecordedFeed_KindMatchesComment() {
+	s.loadFixture()
+	rows, _, err := loe.ParseResponse([]byte(s.apiBody), "loe_data.json", time.Now())
+	require.NoError(s.T(), err)
+	require.NotEmpty(s.T(), rows)
+
+	for _, row := range rows {
+		want := outage.KindUnknown
+		switch {
+		case strings.Contains(row.Comment, "ГАВ"):
+			want = outage.KindEmergency
+		case strings.Contains(row.Comment, "ГПВ"):
+			want = outage.KindHourly
+		}
+		assert.Equal(s.T(), want, outage.NewKind(row.TypeOff, row.SubReason), "row %d: %q", row.ID, row.Comment)
+	}
+}
+
 func (s *NotifierSuite) TestOutageData_WrittenAfterFirstRun() {
 	s.loadFixture()
 	s.saveUser(100, 12445, "Стрийська", "45")
@@ -255,6 +362,7 @@ func (s *NotifierSuite) TestOutageData_WrittenAfterFirstRun() {
 	rows, err := s.outageRepo.Load()
 	require.NoError(s.T(), err)
 	assert.NotEmpty(s.T(), rows, "outage data file should be written after the first run")
+	assert.Equal(s.T(), "Львівська", rows[0].OTG)
 }
 
 func (s *NotifierSuite) TestOutageData_IdenticalSecondRun_SendsNothing() {
@@ -262,7 +370,7 @@ func (s *NotifierSuite) TestOutageData_IdenticalSecondRun_SendsNothing() {
 	s.saveUser(100, 12445, "Стрийська", "45")
 
 	s.runPipeline()
-	require.Len(s.T(), s.sender.sent, 1)
+	require.Len(s.T(), s.sender.sent, 2)
 
 	s.sender.sent = nil
 	s.runPipeline()